@@ -0,0 +1,151 @@
+package main
+
+import (
+	"backend-go/internal/middleware"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// createAPIKey membuat API key lewat endpoint dan mengembalikan key lengkap
+func (a *testApp) createAPIKey(t *testing.T, token string, req models.CreateAPIKeyRequest) string {
+	t.Helper()
+	r := a.json(t, http.MethodPost, "/api/v1/api-keys", req, token)
+	if r.status != http.StatusCreated || r.string("key") == "" {
+		t.Fatalf("create API key = %d %v", r.status, r.body)
+	}
+	return r.string("key")
+}
+
+func TestAPIKeyOwnerState(t *testing.T) {
+	a := newTestApp(t, nil)
+	owner := a.createUser(t, "owner", "Secret123", models.RoleAdmin)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	adminToken := a.login(t, "admin", "Secret123")
+	key := a.createAPIKey(t, a.login(t, "owner", "Secret123"), models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"products:*"}})
+	ownerPath := fmt.Sprintf("/api/v1/users/%d", owner.ID)
+
+	if r := a.json(t, http.MethodDelete, "/api/v1/products/999", nil, key); r.status != http.StatusNotFound {
+		t.Fatalf("delete with admin's key = %d %v, want 404", r.status, r.body)
+	}
+
+	// Staff tidak memiliki products:delete, sehingga scope itu berhenti berlaku
+	if r := a.json(t, http.MethodPut, ownerPath, models.UpdateRequest{Role: models.RoleStaff}, adminToken); r.status != http.StatusOK {
+		t.Fatalf("demote = %d %v", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodDelete, "/api/v1/products/999", nil, key); r.status != http.StatusForbidden {
+		t.Errorf("delete with demoted owner's key = %d %v, want 403", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/products", nil, key); r.status != http.StatusOK {
+		t.Errorf("read with demoted owner's key = %d %v, want 200", r.status, r.body)
+	}
+
+	status := false
+	if r := a.json(t, http.MethodPut, ownerPath, models.UpdateRequest{Status: &status}, adminToken); r.status != http.StatusOK {
+		t.Fatalf("deactivate = %d %v", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/products", nil, key); r.status != http.StatusUnauthorized {
+		t.Errorf("read with deactivated owner's key = %d %v, want 401", r.status, r.body)
+	}
+	keys, _, err := a.repos.APIKeys.List(context.Background(), repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("keys = %+v, want the owner's key revoked", keys)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	token := a.login(t, "admin", "Secret123")
+
+	r := a.json(t, http.MethodPost, "/api/v1/api-keys", models.CreateAPIKeyRequest{Name: "site", Scopes: []string{"products:*"}}, token)
+	scopes, _ := r.body["scopes"].([]interface{})
+	if r.status != http.StatusCreated || len(scopes) < 2 || !strings.HasPrefix(r.string("key"), r.string("prefix")) {
+		t.Fatalf("create = %d %v, want the wildcard expanded and the key starting with its prefix", r.status, r.body)
+	}
+	products := r.string("key")
+	readOnly := a.createAPIKey(t, token, models.CreateAPIKeyRequest{Name: "crm", Scopes: []string{"products:*", "messages:read"}, ReadOnly: true})
+
+	tests := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "scoped read", key: products, method: http.MethodGet, path: "/api/v1/products", want: http.StatusOK},
+		{name: "scoped delete", key: products, method: http.MethodDelete, path: "/api/v1/products/999", want: http.StatusNotFound},
+		{name: "outside scopes", key: products, method: http.MethodGet, path: "/api/v1/messages", want: http.StatusForbidden},
+		{name: "read-only read", key: readOnly, method: http.MethodGet, path: "/api/v1/messages", want: http.StatusOK},
+		{name: "read-only delete", key: readOnly, method: http.MethodDelete, path: "/api/v1/products/999", want: http.StatusForbidden},
+		// Route tanpa permission bergantung pada identitas user, sehingga API key ditolak (RejectAPIKey)
+		{name: "profile", key: products, method: http.MethodGet, path: "/api/v1/me", want: http.StatusForbidden},
+		{name: "sessions", key: products, method: http.MethodGet, path: "/api/v1/me/sessions", want: http.StatusForbidden},
+		{name: "logout", key: products, method: http.MethodPost, path: "/api/v1/logout", want: http.StatusForbidden},
+		{name: "impersonate", key: products, method: http.MethodPost, path: "/api/v1/users/1/impersonate", want: http.StatusForbidden},
+		{name: "unknown key", key: middleware.APIKeyPrefix + "unknown", method: http.MethodGet, path: "/api/v1/products", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := a.json(t, tt.method, tt.path, nil, tt.key); r.status != tt.want {
+				t.Errorf("%s %s = %d %v, want %d", tt.method, tt.path, r.status, r.body, tt.want)
+			}
+		})
+	}
+
+	t.Run("X-API-Key header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+		req.Header.Set("X-API-Key", products)
+		resp, err := a.app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("status = %d, want 200", resp.StatusCode)
+		}
+	})
+
+	t.Run("scopes limited to the creator's role", func(t *testing.T) {
+		staffToken := a.login(t, "staff", "Secret123")
+		// Staff tidak memiliki products:delete
+		if r := a.json(t, http.MethodPost, "/api/v1/api-keys", models.CreateAPIKeyRequest{Name: "x", Scopes: []string{"products:delete"}}, staffToken); r.status != http.StatusForbidden {
+			t.Errorf("create with a scope the role lacks = %d %v, want 403", r.status, r.body)
+		}
+		if r := a.json(t, http.MethodPost, "/api/v1/api-keys", models.CreateAPIKeyRequest{Name: "x", Scopes: []string{"products:fly"}}, token); r.status != http.StatusBadRequest {
+			t.Errorf("create with an unknown scope = %d %v, want 400", r.status, r.body)
+		}
+	})
+
+	t.Run("last use and revocation", func(t *testing.T) {
+		list := a.json(t, http.MethodGet, "/api/v1/api-keys", nil, token)
+		data, _ := list.body["data"].([]interface{})
+		var id float64
+		for _, item := range data {
+			if k := item.(map[string]interface{}); k["name"] == "site" {
+				id = k["id"].(float64)
+				if k["last_used_at"] == nil {
+					t.Errorf("key = %v, want last_used_at set", k)
+				}
+				if _, ok := k["key"]; ok {
+					t.Errorf("key = %v, want the full key only shown at creation", k)
+				}
+			}
+		}
+		if r := a.json(t, http.MethodDelete, fmt.Sprintf("/api/v1/api-keys/%v", id), nil, token); r.status != http.StatusNoContent {
+			t.Fatalf("revoke = %d %v", r.status, r.body)
+		}
+		if r := a.json(t, http.MethodGet, "/api/v1/products", nil, products); r.status != http.StatusUnauthorized {
+			t.Errorf("revoked key = %d %v, want 401", r.status, r.body)
+		}
+	})
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"backend-go/internal/totp"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testApp aplikasi lengkap dengan repository in-memory, untuk test handler tanpa database
+type testApp struct {
+	app   *fiber.App
+	repos *repository.Repositories
+	cfg   *config.Config
+	// tasks pekerjaan latar belakang aplikasi, mis. pengiriman notifikasi
+	tasks *background.Group
+}
+
+func newTestApp(t *testing.T, configure func(cfg *config.Config)) *testApp {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.JWT = config.JWTConfig{Secret: strings.Repeat("s", 32), TTL: 15 * time.Minute, RefreshTTL: time.Hour}
+	cfg.Server.RequestTimeout = 5 * time.Second
+	cfg.Server.UploadTimeout = 5 * time.Second
+	cfg.Upload.Root = t.TempDir()
+	cfg.CORS.AllowOrigins = []string{"*"}
+	cfg.Auth.BcryptCost = bcrypt.MinCost
+	cfg.Auth.LoginMaxFailures = 5
+	cfg.Auth.LoginLockout = 15 * time.Minute
+	cfg.Auth.LoginRateLimit = 1000
+	cfg.Auth.LoginRateWindow = time.Minute
+	if configure != nil {
+		configure(cfg)
+	}
+
+	repos := repository.NewMemory()
+	tasks := background.New()
+	app, _ := newApp(cfg, nil, repos, tasks)
+	return &testApp{app: app, repos: repos, cfg: cfg, tasks: tasks}
+}
+
+// response status, header dan body JSON yang sudah di-decode; list terisi bila body berupa array
+type response struct {
+	status int
+	header http.Header
+	body   map[string]interface{}
+	list   []interface{}
+}
+
+func (r response) string(key string) string {
+	s, _ := r.body[key].(string)
+	return s
+}
+
+func (r response) id() int {
+	id, _ := r.body["id"].(float64)
+	return int(id)
+}
+
+// request mengirim request ke aplikasi; token kosong berarti tanpa header Authorization
+func (a *testApp) request(t *testing.T, method, path, contentType string, body io.Reader, token string) response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, body)
+	if contentType != "" {
+		req.Header.Set(fiber.HeaderContentType, contentType)
+	}
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := a.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := response{status: resp.StatusCode, header: resp.Header}
+	if len(raw) > 0 && raw[0] == '{' {
+		if err := json.Unmarshal(raw, &r.body); err != nil {
+			t.Fatalf("%s %s: decode %s: %v", method, path, raw, err)
+		}
+	}
+	if len(raw) > 0 && raw[0] == '[' {
+		if err := json.Unmarshal(raw, &r.list); err != nil {
+			t.Fatalf("%s %s: decode %s: %v", method, path, raw, err)
+		}
+	}
+	return r
+}
+
+func (a *testApp) json(t *testing.T, method, path string, body interface{}, token string) response {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	return a.request(t, method, path, fiber.MIMEApplicationJSON, reader, token)
+}
+
+// multipart mengirim form dengan file opsional pada field image
+func (a *testApp) multipart(t *testing.T, method, path string, fields map[string]string, image string, token string) response {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		w.WriteField(name, value)
+	}
+	if image != "" {
+		part, err := w.CreateFormFile("image", image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return a.request(t, method, path, w.FormDataContentType(), &buf, token)
+}
+
+// phones nomor telepon unik untuk user test, dengan prefix +62819 yang tidak dipakai nomor tetap di test
+var phones atomic.Int64
+
+// createUser menyimpan user aktif langsung lewat repository
+func (a *testApp) createUser(t *testing.T, username, password string, role models.UserRole) *models.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{
+		Name:     username,
+		Phone:    fmt.Sprintf("+62819%08d", phones.Add(1)),
+		Username: username,
+		Password: string(hash),
+		Role:     role,
+		Status:   true,
+	}
+	if err := a.repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// enrollTOTP mengaktifkan 2FA user langsung lewat repository dan mengembalikan secret-nya.
+// Langkah konfirmasi diletakkan di masa lalu agar kode saat ini masih dapat dipakai.
+func (a *testApp) enrollTOTP(t *testing.T, userID int) string {
+	t.Helper()
+	secret, err := totp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.repos.TOTP.Begin(context.Background(), userID, secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.repos.TOTP.Confirm(context.Background(), userID, totp.Step(time.Now())-10, nil); err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+// totpCode kode TOTP saat ini untuk secret
+func totpCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// login mengembalikan access token user
+func (a *testApp) login(t *testing.T, username, password string) string {
+	t.Helper()
+	r := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: username, Password: password}, "")
+	if r.status != http.StatusOK || r.string("token") == "" {
+		t.Fatalf("login %s: %d %v", username, r.status, r.body)
+	}
+	return r.string("token")
+}
+
+func TestLogin(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "alice", "Secret123", models.RoleStaff)
+	inactive := a.createUser(t, "bob", "Secret123", models.RoleUser)
+	status := false
+	if err := a.repos.Users.Update(context.Background(), inactive.ID, repository.UserUpdate{Status: &status, EditedBy: inactive.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     int
+		wantErr  string
+	}{
+		{name: "success", username: "alice", password: "Secret123", want: http.StatusOK},
+		{name: "wrong password", username: "alice", password: "Secret124", want: http.StatusUnauthorized, wantErr: "Invalid username or password"},
+		{name: "unknown user", username: "carol", password: "Secret123", want: http.StatusUnauthorized, wantErr: "Invalid username or password"},
+		{name: "inactive user", username: "bob", password: "Secret123", want: http.StatusForbidden, wantErr: "Account is inactive"},
+		{name: "missing password", username: "alice", want: http.StatusBadRequest, wantErr: "Validation failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: tt.username, Password: tt.password}, "")
+			if r.status != tt.want {
+				t.Fatalf("status = %d, want %d (%v)", r.status, tt.want, r.body)
+			}
+			if tt.wantErr != "" {
+				if got := r.string("error"); got != tt.wantErr {
+					t.Errorf("error = %q, want %q", got, tt.wantErr)
+				}
+				return
+			}
+			if r.string("token") == "" || r.string("refresh_token") == "" {
+				t.Fatalf("body = %v, want access and refresh token", r.body)
+			}
+			user, _ := r.body["user"].(map[string]interface{})
+			if user["username"] != "alice" || user["role"] != "staff" {
+				t.Errorf("user = %v, want alice/staff", user)
+			}
+		})
+	}
+
+	t.Run("token authenticates", func(t *testing.T) {
+		r := a.json(t, http.MethodGet, "/api/v1/me", nil, a.login(t, "alice", "Secret123"))
+		if r.status != http.StatusOK || r.string("username") != "alice" {
+			t.Fatalf("GET /me = %d %v", r.status, r.body)
+		}
+	})
+}
+
+func TestProductCRUD(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	r := a.multipart(t, http.MethodPost, "/api/v1/products", map[string]string{
+		"title":        "Company profile",
+		"type_product": "digital",
+		"price":        "1500000.50",
+		"status":       "true",
+	}, "cover.png", token)
+	if r.status != http.StatusCreated {
+		t.Fatalf("create = %d %v", r.status, r.body)
+	}
+	id := r.id()
+	path := fmt.Sprintf("/api/v1/products/%d", id)
+	if !strings.HasPrefix(r.string("image"), "uploads/products/") {
+		t.Errorf("image = %q, want a path under uploads/products", r.string("image"))
+	}
+
+	r = a.json(t, http.MethodGet, path, nil, token)
+	if r.status != http.StatusOK || r.string("title") != "Company profile" || r.body["price"] != 1500000.5 {
+		t.Fatalf("get = %d %v", r.status, r.body)
+	}
+
+	r = a.multipart(t, http.MethodPut, path, map[string]string{"title": "Landing page", "price": "900000"}, "", token)
+	if r.status != http.StatusOK || r.string("title") != "Landing page" {
+		t.Fatalf("update = %d %v", r.status, r.body)
+	}
+
+	r = a.json(t, http.MethodGet, "/api/v1/products?search=landing", nil, token)
+	if data, _ := r.body["data"].([]interface{}); r.status != http.StatusOK || len(data) != 1 {
+		t.Fatalf("list = %d %v, want one product", r.status, r.body)
+	}
+
+	if r = a.json(t, http.MethodDelete, path, nil, token); r.status != http.StatusOK {
+		t.Fatalf("delete = %d %v", r.status, r.body)
+	}
+	if r = a.json(t, http.MethodGet, path, nil, token); r.status != http.StatusNotFound {
+		t.Fatalf("get after delete = %d %v, want 404", r.status, r.body)
+	}
+	if r = a.json(t, http.MethodDelete, path, nil, token); r.status != http.StatusNotFound {
+		t.Fatalf("second delete = %d %v, want 404", r.status, r.body)
+	}
+}
+
+func TestProductValidation(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+	valid := map[string]string{"title": "Logo", "type_product": "service", "price": "10"}
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		image  string
+		field  string
+	}{
+		{name: "missing image", fields: valid, field: "image"},
+		{name: "image type", fields: valid, image: "cover.gif", field: "image"},
+		{name: "product type", fields: map[string]string{"title": "Logo", "type_product": "rental", "price": "10"}, image: "a.png", field: "type_product"},
+		{name: "price", fields: map[string]string{"title": "Logo", "type_product": "service", "price": "10.123"}, image: "a.png", field: "price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := a.multipart(t, http.MethodPost, "/api/v1/products", tt.fields, tt.image, token)
+			if r.status != http.StatusBadRequest {
+				t.Fatalf("status = %d %v, want 400", r.status, r.body)
+			}
+			details, _ := r.body["details"].([]interface{})
+			if len(details) != 1 || details[0].(map[string]interface{})["field"] != tt.field {
+				t.Errorf("details = %v, want one error on %s", details, tt.field)
+			}
+		})
+	}
+}
+
+func TestMessageCRUD(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	product := &models.Product{Image: "uploads/products/a.png", Title: "Website", TypeProduct: models.ProductTypeDigital, Price: 10, Status: true}
+	if err := a.repos.Products.Create(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := map[string]string{
+		"name":        "Budi",
+		"description": "Need a company profile",
+		"phone":       "+6281234567890",
+		"product_id":  fmt.Sprint(product.ID),
+	}
+	r := a.multipart(t, http.MethodPost, "/api/v1/messages", fields, "", token)
+	if r.status != http.StatusCreated {
+		t.Fatalf("create = %d %v", r.status, r.body)
+	}
+	path := fmt.Sprintf("/api/v1/messages/%d", r.id())
+
+	r = a.json(t, http.MethodGet, path, nil, token)
+	if r.status != http.StatusOK || r.string("product_name") != "Website" {
+		t.Fatalf("get = %d %v, want product_name Website", r.status, r.body)
+	}
+
+	r = a.multipart(t, http.MethodPut, path, map[string]string{"company": "PT Maju"}, "", token)
+	if r.status != http.StatusOK || r.string("company") != "PT Maju" || r.string("name") != "Budi" {
+		t.Fatalf("update = %d %v", r.status, r.body)
+	}
+
+	fields["product_id"] = fmt.Sprint(product.ID + 100)
+	if r = a.multipart(t, http.MethodPost, "/api/v1/messages", fields, "", token); r.status != http.StatusBadRequest {
+		t.Errorf("create with unknown product = %d %v, want 400", r.status, r.body)
+	}
+
+	if r = a.json(t, http.MethodDelete, path, nil, token); r.status != http.StatusNoContent {
+		t.Fatalf("delete = %d %v, want 204", r.status, r.body)
+	}
+	if r = a.json(t, http.MethodGet, path, nil, token); r.status != http.StatusNotFound {
+		t.Fatalf("get after delete = %d %v, want 404", r.status, r.body)
+	}
+	if r = a.json(t, http.MethodDelete, path, nil, token); r.status != http.StatusNotFound {
+		t.Fatalf("second delete = %d %v, want 404", r.status, r.body)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	tests := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{method: http.MethodGet, path: "/api/v1/products/999"},
+		{method: http.MethodDelete, path: "/api/v1/products/999"},
+		{method: http.MethodGet, path: "/api/v1/carousel/999"},
+		{method: http.MethodDelete, path: "/api/v1/carousel/999"},
+		{method: http.MethodGet, path: "/api/v1/portfolio/images/999"},
+		{method: http.MethodGet, path: "/api/v1/portfolio/reviews/999"},
+		{method: http.MethodGet, path: "/api/v1/messages/999"},
+		{method: http.MethodPut, path: "/api/v1/messages/999", body: map[string]string{"name": "Budi"}},
+		{method: http.MethodDelete, path: "/api/v1/messages/999"},
+		{method: http.MethodGet, path: "/api/v1/users/999"},
+		{method: http.MethodDelete, path: "/api/v1/users/999"},
+		{method: http.MethodGet, path: "/api/v1/unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			r := a.json(t, tt.method, tt.path, tt.body, token)
+			if r.status != http.StatusNotFound {
+				t.Fatalf("status = %d %v, want 404", r.status, r.body)
+			}
+		})
+	}
+
+	t.Run("invalid id", func(t *testing.T) {
+		if r := a.json(t, http.MethodGet, "/api/v1/products/abc", nil, token); r.status != http.StatusBadRequest {
+			t.Fatalf("status = %d %v, want 400", r.status, r.body)
+		}
+	})
+}
+
+func TestPermissions(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "user", "Secret123", models.RoleUser)
+	token := a.login(t, "user", "Secret123")
+
+	if r := a.json(t, http.MethodGet, "/api/v1/products", nil, ""); r.status != http.StatusUnauthorized {
+		t.Errorf("without token = %d, want 401", r.status)
+	}
+	if r := a.json(t, http.MethodDelete, "/api/v1/products/1", nil, token); r.status != http.StatusForbidden {
+		t.Errorf("user deleting product = %d %v, want 403", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/users", nil, token); r.status != http.StatusForbidden {
+		t.Errorf("user listing users = %d %v, want 403", r.status, r.body)
+	}
+}
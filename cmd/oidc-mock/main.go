@@ -0,0 +1,54 @@
+// Command oidc-mock menjalankan identity provider OpenID Connect tiruan untuk mencoba login SSO
+// secara lokal. Setiap user langsung disetujui tanpa password.
+//
+//	go run ./cmd/oidc-mock -addr :9000 -users "alice=cms-admins,bob=editors,carol"
+//
+// lalu jalankan server dengan:
+//
+//	OIDC_ISSUER=http://localhost:9000
+//	OIDC_CLIENT_ID=backend-compro
+//	OIDC_REDIRECT_URL=http://localhost:5173/auth/callback
+//	OIDC_ROLE_MAPPING=cms-admins=admin,editors=staff
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"backend-go/internal/oidc/oidcmock"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "", "issuer URL; empty uses http://<request host>")
+	clientID := flag.String("client-id", "backend-compro", "client ID accepted by the provider")
+	clientSecret := flag.String("client-secret", "", "client secret; empty accepts a public client")
+	users := flag.String("users", "alice=cms-admins,bob=editors,carol", "users as name=group+group, separated by commas")
+	mfa := flag.Bool("mfa", false, "report multi-factor authentication (amr mfa) in ID tokens")
+	verified := flag.String("verified", "email", "claims reported as verified (<claim>_verified=true), separated by commas")
+	flag.Parse()
+
+	cfg := oidcmock.Config{
+		Issuer:       *issuer,
+		ClientID:     *clientID,
+		ClientSecret: *clientSecret,
+		Users:        oidcmock.ParseUsers(*users),
+	}
+	for i := range cfg.Users {
+		if *mfa {
+			cfg.Users[i].AMR = []string{"pwd", "mfa"}
+		}
+		if *verified != "" {
+			cfg.Users[i].Verified = strings.Split(*verified, ",")
+		}
+	}
+
+	provider, err := oidcmock.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Mock OIDC provider listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, provider))
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(\w+)\s+(\S+)\s+(true|false)\s+"([^"]*)"\s*(.*)$`)
+	responsePattern = regexp.MustCompile(`^(\d{3})\s*(?:\{(\w+)\}\s+(\S+))?\s*(?:"([^"]*)")?$`)
+	routerPattern   = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+	attrPattern     = regexp.MustCompile(`(\w+)\(([^)]*)\)`)
+	pathParam       = regexp.MustCompile(`\{(\w+)\}`)
+)
+
+// mimeTypes alias swag untuk @Accept dan @Produce
+var mimeTypes = map[string]string{
+	"json":                  "application/json",
+	"mpfd":                  "multipart/form-data",
+	"x-www-form-urlencoded": "application/x-www-form-urlencoded",
+}
+
+// splitAnnotation memisahkan "@Key  nilai" menjadi key dan nilai
+func splitAnnotation(line string) (string, string) {
+	key, value, _ := strings.Cut(line, " ")
+	return key, strings.TrimSpace(value)
+}
+
+// applyGeneral membaca anotasi umum (@title, @version, @securityDefinitions.apikey, ...) di atas func main
+func (g *generator) applyGeneral(doc *document) {
+	var scheme *securityScheme
+	for _, line := range g.general {
+		key, value := splitAnnotation(line)
+		switch strings.ToLower(key) {
+		case "@title":
+			doc.Info.Title = value
+		case "@version":
+			doc.Info.Version = value
+		case "@description":
+			if scheme != nil {
+				scheme.Description = value
+			} else {
+				doc.Info.Description = joinText(doc.Info.Description, value)
+			}
+		case "@basepath":
+			doc.Servers = []server{{URL: value}}
+		case "@securitydefinitions.apikey":
+			scheme = &securityScheme{Type: "apiKey"}
+			if doc.Components.SecuritySchemes == nil {
+				doc.Components.SecuritySchemes = map[string]*securityScheme{}
+			}
+			doc.Components.SecuritySchemes[value] = scheme
+		case "@in":
+			if scheme != nil {
+				scheme.In = value
+			}
+		case "@name":
+			if scheme != nil {
+				scheme.Name = value
+			}
+		}
+	}
+}
+
+// operation mengubah anotasi satu handler menjadi operation OpenAPI.
+// Path kosong dikembalikan bila handler tidak punya @Router.
+func (g *generator) operation(h handlerDoc) (string, string, *operation, error) {
+	op := &operation{
+		OperationID: h.name,
+		Responses:   map[string]*response{},
+	}
+	var (
+		path, method    string
+		accept, produce []string
+		body            *parameter
+		form            []*parameter
+		bodyExpr        string
+		responseSchemas = map[string]*schema{}
+	)
+
+	for _, line := range h.lines {
+		key, value := splitAnnotation(line)
+		switch strings.ToLower(key) {
+		case "@summary":
+			op.Summary = value
+		case "@description":
+			op.Description = joinText(op.Description, value)
+		case "@id":
+			op.OperationID = value
+		case "@tags":
+			for _, t := range strings.Split(value, ",") {
+				op.Tags = append(op.Tags, strings.TrimSpace(t))
+			}
+		case "@accept":
+			accept = append(accept, parseMimeTypes(value)...)
+		case "@produce":
+			produce = append(produce, parseMimeTypes(value)...)
+		case "@basepath":
+			// Mengganti @BasePath umum, mis. untuk health check yang tidak berada di bawah prefix versi
+			op.Servers = []server{{URL: value}}
+		case "@security":
+			op.Security = append(op.Security, map[string][]string{value: {}})
+		case "@router":
+			m := routerPattern.FindStringSubmatch(value)
+			if m == nil {
+				return "", "", nil, fmt.Errorf("invalid @Router %q", value)
+			}
+			path, method = m[1], strings.ToLower(m[2])
+		case "@param":
+			p, typ, err := g.parameter(h.pkg, value)
+			if err != nil {
+				return "", "", nil, err
+			}
+			switch p.In {
+			case "body":
+				body, bodyExpr = p, typ
+			case "formData":
+				form = append(form, p)
+			default:
+				op.Parameters = append(op.Parameters, p)
+			}
+		case "@success", "@failure":
+			m := responsePattern.FindStringSubmatch(value)
+			if m == nil {
+				return "", "", nil, fmt.Errorf("invalid %s %q", key, value)
+			}
+			code, kind, typ, description := m[1], m[2], m[3], m[4]
+			status, _ := strconv.Atoi(code)
+			if description == "" {
+				description = http.StatusText(status)
+			}
+			op.Responses[code] = &response{Description: description}
+			if typ != "" {
+				s, err := g.typeSchema(h.pkg, typ)
+				if err != nil {
+					return "", "", nil, err
+				}
+				if kind == "array" {
+					s = &schema{Type: "array", Items: s}
+				}
+				responseSchemas[code] = s
+			}
+		}
+	}
+
+	if path == "" {
+		return "", "", nil, nil
+	}
+
+	if len(accept) == 0 {
+		accept = []string{"application/json"}
+	}
+	if len(produce) == 0 {
+		produce = []string{"application/json"}
+	}
+	for code, s := range responseSchemas {
+		op.Responses[code].Content = content(produce, s)
+	}
+
+	switch {
+	case body != nil:
+		s, err := g.typeSchema(h.pkg, bodyExpr)
+		if err != nil {
+			return "", "", nil, err
+		}
+		op.RequestBody = &requestBody{Description: body.Description, Required: body.Required, Content: content(accept, s)}
+	case len(form) > 0:
+		op.RequestBody = &requestBody{Required: true, Content: content(accept, formSchema(form))}
+	}
+
+	// Setiap {param} pada path wajib dideklarasikan sebagai @Param path
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		if !hasParameter(op.Parameters, m[1], "path") {
+			return "", "", nil, fmt.Errorf("path parameter %q of %s is not declared", m[1], path)
+		}
+	}
+
+	return path, method, op, nil
+}
+
+// parameter mem-parse "@Param name in type required "description" attr(...)"
+func (g *generator) parameter(pkg, value string) (*parameter, string, error) {
+	m := paramPattern.FindStringSubmatch(value)
+	if m == nil {
+		return nil, "", fmt.Errorf("invalid @Param %q", value)
+	}
+	name, in, typ, required, description, attrs := m[1], m[2], m[3], m[4] == "true", m[5], m[6]
+
+	p := &parameter{Name: name, In: in, Description: description, Required: required || in == "path"}
+	if in == "body" {
+		return p, typ, nil
+	}
+
+	s, err := primitiveSchema(typ)
+	if err != nil {
+		return nil, "", fmt.Errorf("@Param %s: %w", name, err)
+	}
+	for _, attr := range attrPattern.FindAllStringSubmatch(attrs, -1) {
+		switch attr[1] {
+		case "default":
+			s.Default = literal(attr[2])
+		case "enums":
+			for _, v := range strings.Split(attr[2], ",") {
+				s.Enum = append(s.Enum, literal(strings.TrimSpace(v)))
+			}
+		}
+	}
+	p.Schema = s
+	return p, typ, nil
+}
+
+// typeSchema mem-parse ekspresi tipe pada anotasi (models.User, map[string]string, ...)
+func (g *generator) typeSchema(pkg, typ string) (*schema, error) {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %w", typ, err)
+	}
+	return g.schemaFor(pkg, expr)
+}
+
+// primitiveSchema tipe parameter swag untuk path, query, header, dan formData
+func primitiveSchema(typ string) (*schema, error) {
+	switch typ {
+	case "string":
+		return &schema{Type: "string"}, nil
+	case "int", "integer":
+		return &schema{Type: "integer"}, nil
+	case "number", "float":
+		return &schema{Type: "number"}, nil
+	case "bool", "boolean":
+		return &schema{Type: "boolean"}, nil
+	case "file":
+		return &schema{Type: "string", Format: "binary"}, nil
+	}
+	return nil, fmt.Errorf("unsupported parameter type %q", typ)
+}
+
+// formSchema menggabungkan parameter formData menjadi satu schema object
+func formSchema(params []*parameter) *schema {
+	s := &schema{Type: "object", Properties: map[string]*schema{}}
+	for _, p := range params {
+		prop := *p.Schema
+		prop.Description = p.Description
+		s.Properties[p.Name] = &prop
+		if p.Required {
+			s.Required = append(s.Required, p.Name)
+		}
+	}
+	return s
+}
+
+func content(mimes []string, s *schema) map[string]*mediaType {
+	c := make(map[string]*mediaType, len(mimes))
+	for _, mime := range mimes {
+		c[mime] = &mediaType{Schema: s}
+	}
+	return c
+}
+
+func parseMimeTypes(value string) []string {
+	var mimes []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if alias, ok := mimeTypes[v]; ok {
+			v = alias
+		}
+		mimes = append(mimes, v)
+	}
+	return mimes
+}
+
+func hasParameter(params []*parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// literal membaca nilai default/enum sebagai angka atau boolean bila memungkinkan
+func literal(s string) any {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		return v
+	}
+	return s
+}
+
+func joinText(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
+
+// collectTags mengurutkan seluruh tag yang dipakai operation
+func collectTags(doc *document) []tag {
+	seen := map[string]bool{}
+	var names []string
+	for _, ops := range doc.Paths {
+		for _, op := range ops {
+			for _, t := range op.Tags {
+				if !seen[t] {
+					seen[t] = true
+					names = append(names, t)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	tags := make([]tag, 0, len(names))
+	for _, n := range names {
+		tags = append(tags, tag{Name: n})
+	}
+	return tags
+}
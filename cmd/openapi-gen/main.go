@@ -0,0 +1,210 @@
+// Command openapi-gen membaca anotasi swag-style (@Router, @Param, @Success, ...) pada handler
+// dan struct model, lalu menulis dokumen OpenAPI 3 ke internal/docs/openapi.json.
+//
+// Dijalankan lewat go generate:
+//
+//	go generate ./internal/docs
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	root := flag.String("root", ".", "module root containing main.go and internal/")
+	out := flag.String("out", "internal/docs/openapi.json", "output file")
+	flag.Parse()
+
+	g := newGenerator()
+	if err := g.load(*root); err != nil {
+		log.Fatal(err)
+	}
+	doc, err := g.document()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// typeDecl deklarasi tipe beserta nama package tempat ia didefinisikan
+type typeDecl struct {
+	pkg  string
+	spec *ast.TypeSpec
+}
+
+// handlerDoc komentar @-anotasi milik satu fungsi
+type handlerDoc struct {
+	pkg   string
+	name  string
+	lines []string
+	pos   token.Position
+}
+
+type generator struct {
+	fset     *token.FileSet
+	types    map[string]typeDecl // "models.User"
+	enums    map[string][]any    // "models.UserRole" -> nilai konstanta
+	general  []string            // anotasi umum di atas func main
+	handlers []handlerDoc
+	schemas  map[string]*schema
+}
+
+func newGenerator() *generator {
+	return &generator{
+		fset:    token.NewFileSet(),
+		types:   map[string]typeDecl{},
+		enums:   map[string][]any{},
+		schemas: map[string]*schema{},
+	}
+}
+
+// load mem-parse package main di root dan seluruh package di bawah internal/
+func (g *generator) load(root string) error {
+	dirs := []string{root}
+	err := filepath.WalkDir(filepath.Join(root, "internal"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(g.fset, path, nil, parser.ParseComments)
+			if err != nil {
+				return err
+			}
+			g.collect(file)
+		}
+	}
+	return nil
+}
+
+func (g *generator) collect(file *ast.File) {
+	pkg := file.Name.Name
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			g.collectGenDecl(pkg, d)
+		case *ast.FuncDecl:
+			lines := annotations(d.Doc)
+			if len(lines) == 0 {
+				continue
+			}
+			if pkg == "main" && d.Name.Name == "main" && d.Recv == nil {
+				g.general = lines
+				continue
+			}
+			g.handlers = append(g.handlers, handlerDoc{
+				pkg:   pkg,
+				name:  d.Name.Name,
+				lines: lines,
+				pos:   g.fset.Position(d.Pos()),
+			})
+		}
+	}
+}
+
+func (g *generator) collectGenDecl(pkg string, d *ast.GenDecl) {
+	switch d.Tok {
+	case token.TYPE:
+		for _, spec := range d.Specs {
+			ts := spec.(*ast.TypeSpec)
+			g.types[pkg+"."+ts.Name.Name] = typeDecl{pkg: pkg, spec: ts}
+		}
+	case token.CONST:
+		// Konstanta bertipe (mis. RoleAdmin UserRole = "admin") menjadi enum pada schema
+		for _, spec := range d.Specs {
+			vs := spec.(*ast.ValueSpec)
+			ident, ok := vs.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			for _, value := range vs.Values {
+				if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					key := pkg + "." + ident.Name
+					g.enums[key] = append(g.enums[key], strings.Trim(lit.Value, "\"`"))
+				}
+			}
+		}
+	}
+}
+
+// annotations mengambil baris komentar yang diawali "@"
+func annotations(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var lines []string
+	for _, c := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if strings.HasPrefix(line, "@") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// document menyusun dokumen OpenAPI dari anotasi umum dan seluruh handler
+func (g *generator) document() (*document, error) {
+	doc := &document{
+		OpenAPI: "3.0.3",
+		Paths:   map[string]map[string]*operation{},
+	}
+	g.applyGeneral(doc)
+
+	for _, h := range g.handlers {
+		path, method, op, err := g.operation(h)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", h.pos, h.name, err)
+		}
+		if path == "" {
+			continue
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*operation{}
+		}
+		if _, exists := doc.Paths[path][method]; exists {
+			return nil, fmt.Errorf("%s: %s: duplicate route %s %s", h.pos, h.name, strings.ToUpper(method), path)
+		}
+		doc.Paths[path][method] = op
+	}
+
+	doc.Tags = collectTags(doc)
+	if len(g.schemas) > 0 {
+		doc.Components.Schemas = g.schemas
+	}
+	return doc, nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// builtinSchemas tipe bawaan Go
+var builtinSchemas = map[string]schema{
+	"string":  {Type: "string"},
+	"bool":    {Type: "boolean"},
+	"int":     {Type: "integer"},
+	"int8":    {Type: "integer"},
+	"int16":   {Type: "integer"},
+	"int32":   {Type: "integer", Format: "int32"},
+	"int64":   {Type: "integer", Format: "int64"},
+	"uint":    {Type: "integer"},
+	"uint8":   {Type: "integer"},
+	"uint16":  {Type: "integer"},
+	"uint32":  {Type: "integer"},
+	"uint64":  {Type: "integer"},
+	"float32": {Type: "number", Format: "float"},
+	"float64": {Type: "number", Format: "double"},
+	"any":     {},
+}
+
+// externalSchemas tipe dari package luar module yang muncul di model
+var externalSchemas = map[string]schema{
+	"time.Time":            {Type: "string", Format: "date-time"},
+	"time.Duration":        {Type: "integer", Format: "int64"},
+	"decimal.Decimal":      {Type: "string", Format: "decimal"},
+	"multipart.FileHeader": {Type: "string", Format: "binary"},
+	"json.RawMessage":      {},
+	"fiber.Map":            {Type: "object"},
+}
+
+// schemaFor membangun schema dari ekspresi tipe Go. Tipe milik module direferensikan
+// lewat components/schemas dengan nama "package.Tipe" seperti pada anotasi swag.
+func (g *generator) schemaFor(pkg string, expr ast.Expr) (*schema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if s, ok := builtinSchemas[t.Name]; ok {
+			return &s, nil
+		}
+		return g.ref(pkg + "." + t.Name)
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type expression %T", t.X)
+		}
+		name := x.Name + "." + t.Sel.Name
+		if s, ok := externalSchemas[name]; ok {
+			return &s, nil
+		}
+		return g.ref(name)
+	case *ast.StarExpr:
+		return g.schemaFor(pkg, t.X)
+	case *ast.ArrayType:
+		items, err := g.schemaFor(pkg, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := g.schemaFor(pkg, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		s := &schema{Type: "object"}
+		if values.Type != "" || values.Ref != "" {
+			s.AdditionalProperties = values
+		}
+		return s, nil
+	case *ast.InterfaceType:
+		return &schema{}, nil
+	case *ast.StructType:
+		return g.structSchema(pkg, t)
+	}
+	return nil, fmt.Errorf("unsupported type expression %T", expr)
+}
+
+// ref mendaftarkan tipe ke components/schemas (sekali saja) dan mengembalikan $ref
+func (g *generator) ref(name string) (*schema, error) {
+	ref := &schema{Ref: "#/components/schemas/" + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref, nil
+	}
+
+	decl, ok := g.types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %s", name)
+	}
+
+	// Placeholder mencegah rekursi tak berujung pada tipe yang saling mereferensikan
+	g.schemas[name] = &schema{}
+	s, err := g.schemaFor(decl.pkg, decl.spec.Type)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if values, ok := g.enums[name]; ok {
+		s.Enum = values
+	}
+	g.schemas[name] = s
+	return ref, nil
+}
+
+// structSchema membangun schema object dari field struct; field embedded digabung ke object induk
+func (g *generator) structSchema(pkg string, st *ast.StructType) (*schema, error) {
+	s := &schema{Type: "object", Properties: map[string]*schema{}}
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(raw)
+		}
+
+		if len(field.Names) == 0 {
+			if err := g.embed(pkg, field.Type, s); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			name, ok := jsonName(ident.Name, tag)
+			if !ok {
+				continue
+			}
+			prop, err := g.schemaFor(pkg, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			if required := applyValidation(prop, tag.Get("validate")); required {
+				s.Required = append(s.Required, name)
+			}
+			s.Properties[name] = prop
+		}
+	}
+	return s, nil
+}
+
+// embed menyalin properti dari struct embedded
+func (g *generator) embed(pkg string, expr ast.Expr, into *schema) error {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	name := ""
+	switch t := expr.(type) {
+	case *ast.Ident:
+		name = pkg + "." + t.Name
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			name = x.Name + "." + t.Sel.Name
+		}
+	}
+	decl, ok := g.types[name]
+	if !ok {
+		return fmt.Errorf("unknown embedded type %s", name)
+	}
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		return fmt.Errorf("embedded type %s is not a struct", name)
+	}
+
+	embedded, err := g.structSchema(decl.pkg, st)
+	if err != nil {
+		return err
+	}
+	for k, v := range embedded.Properties {
+		into.Properties[k] = v
+	}
+	into.Required = append(into.Required, embedded.Required...)
+	return nil
+}
+
+// jsonName mengikuti aturan encoding/json: tag json, lalu nama field; "-" berarti diabaikan
+func jsonName(field string, tag reflect.StructTag) (string, bool) {
+	value, ok := tag.Lookup("json")
+	if !ok {
+		return field, true
+	}
+	name, _, _ := strings.Cut(value, ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field, true
+	}
+	return name, true
+}
+
+// applyValidation menerjemahkan tag validate ke constraint schema dan
+// mengembalikan true bila field wajib diisi
+func applyValidation(s *schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required, optional := false, false
+	for _, part := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch rule {
+		case "required":
+			required = true
+		case "omitempty":
+			optional = true
+		}
+		if s.Ref != "" {
+			continue
+		}
+
+		switch rule {
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			setBound(s, rule, n)
+		case "oneof":
+			s.Enum = nil
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "e164":
+			s.Pattern = `^\+[1-9][0-9]{7,14}$`
+		case "alphanum":
+			s.Pattern = `^[a-zA-Z0-9]+$`
+		case "number":
+			s.Pattern = `^[0-9]+$`
+		case "notblank":
+			s.Pattern = `\S`
+		case "decimal":
+			s.Pattern = `^[0-9]+(\.[0-9]{1,` + param + `})?$`
+		case "datetime":
+			if param == "2006-01-02" {
+				s.Format = "date"
+			}
+		case "url":
+			s.Format = "uri"
+		}
+	}
+	return required && !optional
+}
+
+func setBound(s *schema, rule string, n float64) {
+	if s.Type == "integer" || s.Type == "number" {
+		switch rule {
+		case "min":
+			s.Minimum = &n
+		case "max":
+			s.Maximum = &n
+		case "len":
+			s.Minimum, s.Maximum = &n, &n
+		}
+		return
+	}
+
+	length := int(n)
+	switch rule {
+	case "min":
+		s.MinLength = &length
+	case "max":
+		s.MaxLength = &length
+	case "len":
+		s.MinLength, s.MaxLength = &length, &length
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withLegacyRoutes(deprecatedAt, sunset time.Time) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.API.LegacyRoutes = true
+		cfg.API.LegacyDeprecatedAt = deprecatedAt
+		cfg.API.LegacySunset = sunset
+	}
+}
+
+func TestLegacyRoutesDeprecated(t *testing.T) {
+	deprecatedAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	sunset := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name            string
+		deprecatedAt    time.Time
+		sunset          time.Time
+		wantDeprecation string
+		wantSunset      string
+	}{
+		{name: "with dates", deprecatedAt: deprecatedAt, sunset: sunset,
+			wantDeprecation: fmt.Sprintf("@%d", deprecatedAt.Unix()), wantSunset: sunset.UTC().Format(http.TimeFormat)},
+		{name: "without dates", wantDeprecation: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, withLegacyRoutes(tt.deprecatedAt, tt.sunset))
+			a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+			token := a.login(t, "admin", "Secret123")
+
+			r := a.json(t, http.MethodGet, "/products", nil, token)
+			if r.status != http.StatusOK {
+				t.Fatalf("GET /products = %d %v, want 200", r.status, r.body)
+			}
+			if got := r.header.Get("Deprecation"); got != tt.wantDeprecation {
+				t.Errorf("Deprecation = %q, want %q", got, tt.wantDeprecation)
+			}
+			if got := r.header.Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+			if got, want := r.header.Get("Link"), `</api/v1/products>; rel="successor-version"`; got != want {
+				t.Errorf("Link = %q, want %q", got, want)
+			}
+
+			// Path berversi tidak diberi header usang
+			r = a.json(t, http.MethodGet, "/api/v1/products", nil, token)
+			if r.status != http.StatusOK {
+				t.Fatalf("GET /api/v1/products = %d %v, want 200", r.status, r.body)
+			}
+			for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+				if got := r.header.Get(header); got != "" {
+					t.Errorf("%s on /api/v1/products = %q, want none", header, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLegacyRoutesAfterSunset(t *testing.T) {
+	sunset := time.Now().Add(-time.Hour).Truncate(time.Second)
+	a := newTestApp(t, withLegacyRoutes(sunset.Add(-30*24*time.Hour), sunset))
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	for _, path := range []string{"/products", "/carousel"} {
+		r := a.json(t, http.MethodGet, path, nil, token)
+		if r.status != http.StatusGone || r.string("code") != "gone" {
+			t.Fatalf("GET %s = %d %v, want 410 gone", path, r.status, r.body)
+		}
+		if !strings.Contains(r.string("error"), "/api/v1"+path) {
+			t.Errorf("error = %q, want it to point at /api/v1%s", r.string("error"), path)
+		}
+		if got := r.header.Get("Sunset"); got != sunset.UTC().Format(http.TimeFormat) {
+			t.Errorf("Sunset = %q, want %q", got, sunset.UTC().Format(http.TimeFormat))
+		}
+		if got := r.header.Get("Link"); got == "" {
+			t.Error("Link header missing on 410")
+		}
+
+		if r := a.json(t, http.MethodGet, "/api/v1"+path, nil, token); r.status != http.StatusOK {
+			t.Errorf("GET /api/v1%s = %d %v, want 200", path, r.status, r.body)
+		}
+	}
+}
+
+func TestLegacyRoutesDisabled(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+	if r := a.json(t, http.MethodGet, "/products", nil, token); r.status != http.StatusNotFound {
+		t.Errorf("GET /products = %d %v, want 404 without legacy routes", r.status, r.body)
+	}
+}
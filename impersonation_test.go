@@ -0,0 +1,228 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/middleware"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// withImpersonation mengaktifkan impersonasi, yang tidak memiliki TTL bawaan di config test
+func withImpersonation(cfg *config.Config) { cfg.Auth.ImpersonationTTL = 30 * time.Minute }
+
+// impersonate memulai impersonasi dengan token admin dan mengembalikan token impersonasi
+func (a *testApp) impersonate(t *testing.T, adminToken string, userID int) string {
+	t.Helper()
+	path := fmt.Sprintf("/api/v1/users/%d/impersonate", userID)
+	r := a.json(t, http.MethodPost, path, models.ImpersonateRequest{Reason: "support ticket"}, adminToken)
+	if r.status != http.StatusOK || r.string("token") == "" {
+		t.Fatalf("impersonate = %d %v", r.status, r.body)
+	}
+	return r.string("token")
+}
+
+func TestImpersonatedWritesRecordActor(t *testing.T) {
+	a := newTestApp(t, withImpersonation)
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	token := a.impersonate(t, a.login(t, "admin", "Secret123"), staff.ID)
+
+	fields := map[string]string{"name": "Budi", "description": "Need a company profile", "phone": "+6281234567890"}
+	r := a.multipart(t, http.MethodPost, "/api/v1/messages", fields, "", token)
+	if r.status != http.StatusCreated || r.body["created_by"] != float64(staff.ID) || r.body["impersonated_by"] != float64(admin.ID) {
+		t.Fatalf("create = %d %v, want created_by %d and impersonated_by %d", r.status, r.body, staff.ID, admin.ID)
+	}
+	path := fmt.Sprintf("/api/v1/messages/%d", r.id())
+
+	r = a.multipart(t, http.MethodPut, path, map[string]string{"company": "PT Maju"}, "", token)
+	if r.status != http.StatusOK || r.body["edited_by"] != float64(staff.ID) || r.body["impersonated_by"] != float64(admin.ID) {
+		t.Fatalf("update = %d %v, want edited_by %d and impersonated_by %d", r.status, r.body, staff.ID, admin.ID)
+	}
+
+	// Perubahan berikutnya oleh user sendiri menghapus atribusi admin
+	r = a.multipart(t, http.MethodPut, path, map[string]string{"company": "PT Jaya"}, "", a.login(t, "staff", "Secret123"))
+	if _, ok := r.body["impersonated_by"]; r.status != http.StatusOK || ok {
+		t.Fatalf("update by staff = %d %v, want no impersonated_by", r.status, r.body)
+	}
+
+	name := "Staff Baru"
+	if r := a.json(t, http.MethodPatch, "/api/v1/me", models.UpdateProfileRequest{Name: &name}, token); r.status != http.StatusOK {
+		t.Fatalf("update profile = %d %v", r.status, r.body)
+	}
+	user, err := a.repos.Users.GetByID(context.Background(), staff.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.EditedBy == nil || *user.EditedBy != staff.ID || user.ImpersonatedBy == nil || *user.ImpersonatedBy != admin.ID {
+		t.Errorf("user edited_by = %v, impersonated_by = %v, want %d and %d", user.EditedBy, user.ImpersonatedBy, staff.ID, admin.ID)
+	}
+}
+
+func TestImpersonationRestrictions(t *testing.T) {
+	// Staff boleh memerankan user lain, tetapi tidak role yang lebih berkuasa
+	a := newTestApp(t, func(cfg *config.Config) {
+		withImpersonation(cfg)
+		withStaffGrants(t, "users:impersonate")(cfg)
+	})
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	user := a.createUser(t, "user", "Secret123", models.RoleUser)
+	inactive := a.createUser(t, "inactive", "Secret123", models.RoleUser)
+	status := false
+	if err := a.repos.Users.Update(context.Background(), inactive.ID, repository.UserUpdate{Status: &status}); err != nil {
+		t.Fatal(err)
+	}
+	adminToken := a.login(t, "admin", "Secret123")
+	staffToken := a.login(t, "staff", "Secret123")
+
+	impersonate := func(token string, id int) response {
+		t.Helper()
+		path := fmt.Sprintf("/api/v1/users/%d/impersonate", id)
+		return a.json(t, http.MethodPost, path, models.ImpersonateRequest{Reason: "support ticket"}, token)
+	}
+	refused := []struct {
+		name   string
+		token  string
+		id     int
+		status int
+	}{
+		{name: "without permission", token: a.login(t, "user", "Secret123"), id: staff.ID, status: http.StatusForbidden},
+		{name: "more privileged role", token: staffToken, id: admin.ID, status: http.StatusForbidden},
+		{name: "self", token: adminToken, id: admin.ID, status: http.StatusBadRequest},
+		{name: "inactive user", token: adminToken, id: inactive.ID, status: http.StatusBadRequest},
+	}
+	for _, tt := range refused {
+		if r := impersonate(tt.token, tt.id); r.status != tt.status {
+			t.Errorf("impersonate %s = %d %v, want %d", tt.name, r.status, r.body, tt.status)
+		}
+	}
+	a.impersonate(t, staffToken, user.ID)
+
+	token := a.impersonate(t, adminToken, staff.ID)
+	claims := tokenClaims(t, token)
+	if claims.UserID != staff.ID || claims.Act == nil || claims.Act.UserID != admin.ID {
+		t.Fatalf("claims = %+v, want user %d acting through admin %d", claims, staff.ID, admin.ID)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > a.cfg.Auth.ImpersonationTTL {
+		t.Errorf("token lives %s, want at most %s", ttl, a.cfg.Auth.ImpersonationTTL)
+	}
+
+	r := a.json(t, http.MethodGet, "/api/v1/me", nil, token)
+	if r.status != http.StatusOK || r.id() != staff.ID || r.header.Get(middleware.HeaderImpersonatedBy) != strconv.Itoa(admin.ID) {
+		t.Fatalf("me = %d %v %s %q, want staff with the admin in the header", r.status, r.body,
+			middleware.HeaderImpersonatedBy, r.header.Get(middleware.HeaderImpersonatedBy))
+	}
+
+	// Kredensial dan session user tidak dapat diubah, dan impersonasi tidak dapat dirantai
+	blocked := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodPost, "/api/v1/me/password", models.ChangePasswordRequest{CurrentPassword: "Secret123", NewPassword: "Another789x"}},
+		{http.MethodPost, "/api/v1/me/2fa/setup", nil},
+		{http.MethodDelete, "/api/v1/me/sessions", nil},
+		{http.MethodPost, fmt.Sprintf("/api/v1/users/%d/impersonate", inactive.ID), models.ImpersonateRequest{Reason: "support ticket"}},
+	}
+	for _, b := range blocked {
+		if r := a.json(t, b.method, b.path, b.body, token); r.status != http.StatusForbidden {
+			t.Errorf("%s %s while impersonating = %d %v, want 403", b.method, b.path, r.status, r.body)
+		}
+	}
+	a.login(t, "staff", "Secret123")
+
+	// Admin yang dinonaktifkan kehilangan semua impersonasi yang masih berjalan
+	if err := a.repos.Users.Update(context.Background(), admin.ID, repository.UserUpdate{Status: &status}); err != nil {
+		t.Fatal(err)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusUnauthorized {
+		t.Errorf("me after the admin was deactivated = %d %v, want 401", r.status, r.body)
+	}
+}
+
+func TestImpersonationAuditLog(t *testing.T) {
+	a := newTestApp(t, withImpersonation)
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	adminToken := a.login(t, "admin", "Secret123")
+	token := a.impersonate(t, adminToken, staff.ID)
+
+	a.json(t, http.MethodGet, "/api/v1/me", nil, token)
+	a.json(t, http.MethodPost, "/api/v1/me/password", models.ChangePasswordRequest{CurrentPassword: "Secret123", NewPassword: "Another789x"}, token)
+	// Request user sendiri tidak masuk audit log
+	a.json(t, http.MethodGet, "/api/v1/me", nil, a.login(t, "staff", "Secret123"))
+
+	r := a.json(t, http.MethodGet, fmt.Sprintf("/api/v1/audit-log?user_id=%d", staff.ID), nil, adminToken)
+	if r.status != http.StatusOK {
+		t.Fatalf("audit log = %d %v", r.status, r.body)
+	}
+	data, _ := r.body["data"].([]interface{})
+	want := []struct {
+		action, method, path string
+		status               int
+	}{
+		{models.AuditImpersonatedRequest, http.MethodPost, "/api/v1/me/password", http.StatusForbidden},
+		{models.AuditImpersonatedRequest, http.MethodGet, "/api/v1/me", http.StatusOK},
+		{models.AuditImpersonationStarted, http.MethodPost, fmt.Sprintf("/api/v1/users/%d/impersonate", staff.ID), http.StatusOK},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("audit log = %v, want %d entries", data, len(want))
+	}
+	sessionID := float64(tokenClaims(t, token).SessionID)
+	for i, w := range want {
+		entry := data[i].(map[string]interface{})
+		if entry["action"] != w.action || entry["method"] != w.method || entry["path"] != w.path || entry["status"] != float64(w.status) {
+			t.Errorf("entry %d = %v, want %s %s %s %d", i, entry, w.action, w.method, w.path, w.status)
+		}
+		if entry["actor_id"] != float64(admin.ID) || entry["user_id"] != float64(staff.ID) || entry["session_id"] != sessionID {
+			t.Errorf("entry %d = %v, want actor %d, user %d and session %v", i, entry, admin.ID, staff.ID, sessionID)
+		}
+	}
+	if reason := data[2].(map[string]interface{})["detail"]; reason != "support ticket" {
+		t.Errorf("impersonation reason = %v, want support ticket", reason)
+	}
+
+	// Audit log hanya untuk admin, dan tidak dapat dibaca lewat token impersonasi staff
+	if r := a.json(t, http.MethodGet, "/api/v1/audit-log", nil, token); r.status != http.StatusForbidden {
+		t.Errorf("audit log while impersonating staff = %d %v, want 403", r.status, r.body)
+	}
+	r = a.json(t, http.MethodGet, fmt.Sprintf("/api/v1/audit-log?actor_id=%d", staff.ID), nil, adminToken)
+	if data, _ := r.body["data"].([]interface{}); r.status != http.StatusOK || len(data) != 0 {
+		t.Errorf("audit log for actor %d = %d %v, want empty", staff.ID, r.status, r.body)
+	}
+}
+
+func TestImpersonationCannotManageAPIKeys(t *testing.T) {
+	a := newTestApp(t, withImpersonation)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	owner := a.createUser(t, "owner", "Secret123", models.RoleAdmin)
+	key := a.createAPIKey(t, a.login(t, "owner", "Secret123"), models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"products:*"}})
+	keys, _, err := a.repos.APIKeys.List(context.Background(), repository.Page{Limit: 10})
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("keys = %+v, %v", keys, err)
+	}
+	token := a.impersonate(t, a.login(t, "admin", "Secret123"), owner.ID)
+
+	// Key milik user tidak dapat dibuat maupun dicabut atas nama user
+	blocked := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodPost, "/api/v1/api-keys", models.CreateAPIKeyRequest{Name: "backdoor", Scopes: []string{"products:*"}}},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/api-keys/%d", keys[0].ID), nil},
+	}
+	for _, b := range blocked {
+		r := a.json(t, b.method, b.path, b.body, token)
+		if r.status != http.StatusForbidden || r.string("error") != "This action is not allowed while impersonating a user" {
+			t.Errorf("%s %s while impersonating = %d %v, want 403", b.method, b.path, r.status, r.body)
+		}
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/products", nil, key); r.status != http.StatusOK {
+		t.Errorf("read with the owner's key = %d %v, want it still valid", r.status, r.body)
+	}
+}
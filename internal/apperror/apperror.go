@@ -0,0 +1,229 @@
+package apperror
+
+import (
+	"backend-go/internal/repoerr"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// StatusClientClosedRequest status non-standar (gaya nginx) untuk request yang dibatalkan client
+const StatusClientClosedRequest = 499
+
+// Code kode error yang stabil untuk dibaca client
+type Code string
+
+const (
+	CodeBadRequest      Code = "bad_request"
+	CodeValidation      Code = "validation_failed"
+	CodeUnauthorized    Code = "unauthorized"
+	CodeForbidden       Code = "forbidden"
+	CodeNotFound        Code = "not_found"
+	CodeConflict        Code = "conflict"
+	CodeGone            Code = "gone"
+	CodeTooManyRequests Code = "too_many_requests"
+	CodeUnprocessable   Code = "unprocessable_entity"
+	CodePayloadTooLarge Code = "payload_too_large"
+	CodeTimeout         Code = "timeout"
+	CodeCanceled        Code = "request_canceled"
+	CodeInternal        Code = "internal_error"
+)
+
+// FieldError detail error untuk satu field input
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// AppError error aplikasi dengan status HTTP dan pesan yang aman dikirim ke client.
+// Err menyimpan penyebab internal untuk log dan tidak pernah dikirim ke client.
+type AppError struct {
+	Code    Code
+	Status  int
+	Message string
+	Details []FieldError
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails menambahkan detail per field
+func (e *AppError) WithDetails(details ...FieldError) *AppError {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
+// New membuat AppError dengan status dan kode tertentu
+func New(status int, code Code, message string) *AppError {
+	return &AppError{Code: code, Status: status, Message: message}
+}
+
+func BadRequest(message string) *AppError {
+	return New(fiber.StatusBadRequest, CodeBadRequest, message)
+}
+
+func Validation(message string, details ...FieldError) *AppError {
+	return New(fiber.StatusBadRequest, CodeValidation, message).WithDetails(details...)
+}
+
+func Unauthorized(message string) *AppError {
+	return New(fiber.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *AppError {
+	return New(fiber.StatusForbidden, CodeForbidden, message)
+}
+
+// PermissionDenied error 403 seragam untuk permission yang tidak dimiliki; permission yang
+// dibutuhkan dikirim di details agar client dapat menampilkannya
+func PermissionDenied(permission string) *AppError {
+	return Forbidden("You do not have permission to perform this action").
+		WithDetails(FieldError{Field: "permission", Message: permission + " is required"})
+}
+
+func TooManyRequests(message string) *AppError {
+	return New(fiber.StatusTooManyRequests, CodeTooManyRequests, message)
+}
+
+func NotFound(message string) *AppError {
+	return New(fiber.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *AppError {
+	return New(fiber.StatusConflict, CodeConflict, message)
+}
+
+func Gone(message string) *AppError {
+	return New(fiber.StatusGone, CodeGone, message)
+}
+
+func Unprocessable(message string) *AppError {
+	return New(fiber.StatusUnprocessableEntity, CodeUnprocessable, message)
+}
+
+// Internal membuat error 500; err hanya dicatat di log
+func Internal(message string, err error) *AppError {
+	e := New(fiber.StatusInternalServerError, CodeInternal, message)
+	e.Err = err
+	return e
+}
+
+// From menerjemahkan error sembarang (repository, PostgreSQL, context, Fiber) menjadi AppError
+func From(err error) *AppError {
+	return Wrap(err, "Internal server error")
+}
+
+// Wrap seperti From, tetapi memakai message sebagai pesan 500 bila err tidak dikenali
+func Wrap(err error, message string) *AppError {
+	if err == nil {
+		return Internal(message, nil)
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &AppError{Code: CodeTimeout, Status: fiber.StatusServiceUnavailable, Message: "Request timed out", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &AppError{Code: CodeCanceled, Status: StatusClientClosedRequest, Message: "Request canceled", Err: err}
+	case errors.Is(err, repoerr.ErrNotFound):
+		return &AppError{Code: CodeNotFound, Status: fiber.StatusNotFound, Message: "Resource not found", Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if e := fromPgError(pgErr); e != nil {
+			e.Err = err
+			return e
+		}
+	}
+
+	if errors.Is(err, repoerr.ErrDuplicate) {
+		return &AppError{Code: CodeConflict, Status: fiber.StatusConflict, Message: "Resource already exists", Err: err}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fromFiberError(fiberErr)
+	}
+
+	return Internal(message, err)
+}
+
+// fromPgError memetakan constraint violation PostgreSQL ke status 409/422
+func fromPgError(pgErr *pgconn.PgError) *AppError {
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		return Conflict("Resource already exists").WithDetails(constraintDetail(pgErr, "already in use")...)
+	case "23503": // foreign_key_violation
+		return Unprocessable("Referenced resource does not exist").WithDetails(constraintDetail(pgErr, "references a missing resource")...)
+	case "23514", "23502": // check_violation, not_null_violation
+		return Unprocessable("Value violates a data constraint").WithDetails(constraintDetail(pgErr, "is invalid")...)
+	case "22001", "22003", "22P02", "22007", "22008": // data terlalu panjang, di luar rentang, atau format salah
+		return Unprocessable("Invalid value for field")
+	}
+	return nil
+}
+
+// constraintDetail menyusun detail field dari kolom atau nama constraint, tanpa membocorkan pesan mentah database
+func constraintDetail(pgErr *pgconn.PgError, message string) []FieldError {
+	field := pgErr.ColumnName
+	if field == "" {
+		field = pgErr.ConstraintName
+	}
+	if field == "" {
+		return nil
+	}
+	return []FieldError{{Field: field, Message: message}}
+}
+
+// fromFiberError mempertahankan status dari fiber.Error (mis. 404 route, 413 body terlalu besar)
+func fromFiberError(fiberErr *fiber.Error) *AppError {
+	code := CodeInternal
+	switch fiberErr.Code {
+	case fiber.StatusBadRequest:
+		code = CodeBadRequest
+	case fiber.StatusUnauthorized:
+		code = CodeUnauthorized
+	case fiber.StatusForbidden:
+		code = CodeForbidden
+	case fiber.StatusNotFound:
+		code = CodeNotFound
+	case fiber.StatusConflict:
+		code = CodeConflict
+	case fiber.StatusGone:
+		code = CodeGone
+	case fiber.StatusTooManyRequests:
+		code = CodeTooManyRequests
+	case fiber.StatusRequestEntityTooLarge:
+		code = CodePayloadTooLarge
+	case fiber.StatusUnprocessableEntity:
+		code = CodeUnprocessable
+	case fiber.StatusServiceUnavailable, fiber.StatusRequestTimeout:
+		code = CodeTimeout
+	default:
+		if fiberErr.Code < fiber.StatusInternalServerError {
+			code = CodeBadRequest
+		}
+	}
+
+	if fiberErr.Code >= fiber.StatusInternalServerError {
+		return &AppError{Code: code, Status: fiberErr.Code, Message: "Internal server error", Err: fiberErr}
+	}
+	return &AppError{Code: code, Status: fiberErr.Code, Message: fiberErr.Message}
+}
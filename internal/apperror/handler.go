@@ -0,0 +1,36 @@
+package apperror
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestIDKey key locals tempat middleware requestid menyimpan ID request
+const RequestIDKey = "requestid"
+
+// Response envelope JSON untuk semua response error
+type Response struct {
+	Error     string       `json:"error"`
+	Code      Code         `json:"code"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Handler dipasang sebagai fiber.Config.ErrorHandler. Error 5xx dicatat bersama request ID
+// dan penyebab internalnya, sedangkan client hanya menerima pesan yang aman.
+func Handler(c *fiber.Ctx, err error) error {
+	appErr := From(err)
+	requestID, _ := c.Locals(RequestIDKey).(string)
+
+	if appErr.Status >= fiber.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", requestID, c.Method(), c.OriginalURL(), appErr)
+	}
+
+	return c.Status(appErr.Status).JSON(Response{
+		Error:     appErr.Message,
+		Code:      appErr.Code,
+		Details:   appErr.Details,
+		RequestID: requestID,
+	})
+}
@@ -0,0 +1,206 @@
+// Package authz memetakan role user ke permission yang dibutuhkan tiap endpoint.
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"backend-go/internal/models"
+)
+
+// Permission hak akses dengan format "resource:action"
+type Permission string
+
+const (
+	UsersRead   Permission = "users:read"
+	UsersCreate Permission = "users:create"
+	UsersUpdate Permission = "users:update"
+	UsersDelete Permission = "users:delete"
+	// UsersImpersonate menerbitkan token yang bertindak sebagai user lain
+	UsersImpersonate Permission = "users:impersonate"
+
+	CarouselRead   Permission = "carousel:read"
+	CarouselCreate Permission = "carousel:create"
+	CarouselUpdate Permission = "carousel:update"
+	CarouselDelete Permission = "carousel:delete"
+
+	ProductsRead   Permission = "products:read"
+	ProductsCreate Permission = "products:create"
+	ProductsUpdate Permission = "products:update"
+	ProductsDelete Permission = "products:delete"
+
+	PortfolioRead   Permission = "portfolio:read"
+	PortfolioCreate Permission = "portfolio:create"
+	PortfolioUpdate Permission = "portfolio:update"
+	PortfolioDelete Permission = "portfolio:delete"
+
+	MessagesRead   Permission = "messages:read"
+	MessagesCreate Permission = "messages:create"
+	MessagesUpdate Permission = "messages:update"
+	MessagesDelete Permission = "messages:delete"
+
+	InvitesRead   Permission = "invites:read"
+	InvitesCreate Permission = "invites:create"
+	InvitesDelete Permission = "invites:delete"
+
+	APIKeysRead   Permission = "apikeys:read"
+	APIKeysCreate Permission = "apikeys:create"
+	APIKeysDelete Permission = "apikeys:delete"
+
+	AuditRead Permission = "audit:read"
+)
+
+// All seluruh permission yang dikenal
+var All = []Permission{
+	UsersRead, UsersCreate, UsersUpdate, UsersDelete, UsersImpersonate,
+	CarouselRead, CarouselCreate, CarouselUpdate, CarouselDelete,
+	ProductsRead, ProductsCreate, ProductsUpdate, ProductsDelete,
+	PortfolioRead, PortfolioCreate, PortfolioUpdate, PortfolioDelete,
+	MessagesRead, MessagesCreate, MessagesUpdate, MessagesDelete,
+	InvitesRead, InvitesCreate, InvitesDelete,
+	APIKeysRead, APIKeysCreate, APIKeysDelete,
+	AuditRead,
+}
+
+// Grants pemetaan role ke daftar permission. Selain nama permission, "*" berarti semua
+// permission dan "resource:*" berarti semua action pada resource tersebut.
+type Grants map[models.UserRole][]string
+
+// DefaultGrants matriks bawaan: admin mengelola semuanya, staff mengelola konten tanpa
+// menghapus, user hanya membaca konten dan mengirim pesan
+var DefaultGrants = Grants{
+	models.RoleAdmin: {"*"},
+	models.RoleStaff: {
+		"carousel:read", "carousel:create", "carousel:update",
+		"products:read", "products:create", "products:update",
+		"portfolio:read", "portfolio:create", "portfolio:update",
+		"messages:read", "messages:create", "messages:update",
+	},
+	models.RoleUser: {
+		"carousel:read", "products:read", "portfolio:read",
+		"messages:create",
+	},
+}
+
+// Policy matriks role dan permission yang sudah divalidasi
+type Policy struct {
+	roles map[models.UserRole]map[Permission]bool
+}
+
+// Default membuat Policy dari DefaultGrants
+func Default() *Policy {
+	p, err := New(DefaultGrants)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// New membuat Policy dari grants. Role atau permission yang tidak dikenal dianggap error
+// agar salah ketik di konfigurasi tidak diam-diam mencabut akses.
+func New(grants Grants) (*Policy, error) {
+	p := &Policy{roles: make(map[models.UserRole]map[Permission]bool, len(grants))}
+	for role, names := range grants {
+		switch role {
+		case models.RoleAdmin, models.RoleStaff, models.RoleUser:
+		default:
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+
+		set := make(map[Permission]bool)
+		for _, name := range names {
+			perms := expand(name)
+			if len(perms) == 0 {
+				return nil, fmt.Errorf("role %s: unknown permission %q", role, name)
+			}
+			for _, perm := range perms {
+				set[perm] = true
+			}
+		}
+		p.roles[role] = set
+	}
+	return p, nil
+}
+
+// Load membaca file JSON berisi grants ({"staff": ["products:*", "messages:read"]}).
+// Role yang ada di file menggantikan grants bawaan role tersebut; role lain tetap memakai bawaan.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overrides Grants
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	grants := make(Grants, len(DefaultGrants))
+	for role, names := range DefaultGrants {
+		grants[role] = names
+	}
+	for role, names := range overrides {
+		grants[role] = names
+	}
+
+	p, err := New(grants)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Allows true bila role memiliki permission
+func (p *Policy) Allows(role models.UserRole, perm Permission) bool {
+	return p.roles[role][perm]
+}
+
+// Permissions daftar permission milik role, terurut
+func (p *Policy) Permissions(role models.UserRole) []Permission {
+	perms := make([]Permission, 0, len(p.roles[role]))
+	for perm := range p.roles[role] {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
+
+// Expand menerjemahkan daftar nama permission atau wildcard menjadi permission terurut tanpa duplikat
+func Expand(names []string) ([]Permission, error) {
+	set := make(map[Permission]bool)
+	for _, name := range names {
+		perms := expand(name)
+		if len(perms) == 0 {
+			return nil, fmt.Errorf("unknown permission %q", name)
+		}
+		for _, perm := range perms {
+			set[perm] = true
+		}
+	}
+
+	out := make([]Permission, 0, len(set))
+	for perm := range set {
+		out = append(out, perm)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+// IsRead true untuk permission dengan action read
+func (p Permission) IsRead() bool {
+	return strings.HasSuffix(string(p), ":read")
+}
+
+// expand menerjemahkan nama atau wildcard menjadi permission yang dikenal
+func expand(name string) []Permission {
+	var perms []Permission
+	for _, perm := range All {
+		if name == "*" || Permission(name) == perm ||
+			(strings.HasSuffix(name, ":*") && strings.HasPrefix(string(perm), strings.TrimSuffix(name, "*"))) {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
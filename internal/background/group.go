@@ -0,0 +1,85 @@
+package background
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Group melacak goroutine latar belakang (misalnya penghapusan file lama)
+// agar bisa ditunggu sampai selesai saat server shutdown
+type Group struct {
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// New membuat Group kosong
+func New() *Group {
+	return &Group{}
+}
+
+// Go menjalankan fn di goroutine terpisah. Setelah Wait dipanggil, fn dijalankan
+// langsung di goroutine pemanggil supaya pekerjaan tidak hilang saat shutdown.
+func (g *Group) Go(fn func()) {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		g.run(fn)
+		return
+	}
+	g.wg.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer g.wg.Done()
+		g.run(fn)
+	}()
+}
+
+// Wait menolak task baru lalu menunggu semua task selesai atau ctx habis
+func (g *Group) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Every menjalankan fn sekali di awal lalu setiap interval sampai ctx selesai.
+// Dipanggil lewat Group.Go agar shutdown menunggu putaran yang sedang berjalan.
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run menjalankan fn dan mencegah panic menjatuhkan proses
+func (g *Group) run(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Background task panicked: %v", r)
+		}
+	}()
+	fn()
+}
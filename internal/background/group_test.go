@@ -0,0 +1,89 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitDrainsRunningTasks(t *testing.T) {
+	g := New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	g.Go(func() {
+		close(started)
+		<-release
+		finished.Store(true)
+	})
+	<-started
+
+	waited := make(chan error)
+	go func() { waited <- g.Wait(context.Background()) }()
+
+	select {
+	case err := <-waited:
+		t.Fatalf("Wait() = %v before the running task finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Fatalf("Wait() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait() did not return after the task finished")
+	}
+	if !finished.Load() {
+		t.Error("Wait() returned before the task finished")
+	}
+}
+
+func TestGoAfterWait(t *testing.T) {
+	g := New()
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setelah shutdown tidak ada goroutine baru: fn sudah selesai saat Go kembali
+	var ran bool
+	g.Go(func() { ran = true })
+	if !ran {
+		t.Fatal("Go() after Wait left the task running in the background")
+	}
+
+	g.Go(func() { panic("boom") })
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatalf("second Wait() = %v", err)
+	}
+}
+
+func TestWaitDeadline(t *testing.T) {
+	g := New()
+	release := make(chan struct{})
+	defer close(release)
+	g.Go(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := g.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestGoRecoversPanic(t *testing.T) {
+	g := New()
+	var after atomic.Bool
+	g.Go(func() { panic("boom") })
+	g.Go(func() { after.Store(true) })
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !after.Load() {
+		t.Error("task after a panicking task did not run")
+	}
+}
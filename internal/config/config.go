@@ -0,0 +1,740 @@
+package config
+
+import (
+	"backend-go/internal/authz"
+	"backend-go/internal/jwtkeys"
+	"backend-go/internal/models"
+	"backend-go/internal/password"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minJWTSecretLength panjang minimal secret HS256 agar token tidak mudah ditebak
+const minJWTSecretLength = 32
+
+// minPasswordLength batas bawah AUTH_PASSWORD_MIN_LENGTH
+const minPasswordLength = 8
+
+// maxImpersonationTTL batas atas AUTH_IMPERSONATION_TTL agar token impersonasi tetap berumur pendek
+const maxImpersonationTTL = time.Hour
+
+// maxBcryptCost batas atas AUTH_BCRYPT_COST; setiap kenaikan satu menggandakan waktu hash saat login
+const maxBcryptCost = 16
+
+// Config konfigurasi aplikasi yang dimuat sekali saat startup
+type Config struct {
+	Server ServerConfig
+	DB     DBConfig
+	JWT    JWTConfig
+	CORS   CORSConfig
+	Upload UploadConfig
+	API    APIConfig
+	Authz  AuthzConfig
+	Auth   AuthConfig
+	OIDC   OIDCConfig
+	Notify NotifyConfig
+}
+
+// ServerConfig konfigurasi HTTP server
+type ServerConfig struct {
+	Port            string
+	BodyLimit       int
+	ShutdownTimeout time.Duration
+	// ShutdownGracePeriod jeda antara readiness gagal dan berhenti menerima koneksi
+	ShutdownGracePeriod time.Duration
+	// RequestTimeout deadline default context request, UploadTimeout untuk route upload file
+	RequestTimeout time.Duration
+	UploadTimeout  time.Duration
+	// RouteTimeouts mengganti RequestTimeout per grup route, yaitu segmen pertama path setelah
+	// prefix versi (mis. "products" untuk /api/v1/products/:id, "auth" untuk /api/v1/auth/*).
+	// Route upload tetap memakai UploadTimeout untuk handler-nya.
+	RouteTimeouts map[string]time.Duration
+	// ProxyHeader header berisi IP client asli yang di-set load balancer (mis. X-Real-IP), dipakai
+	// rate limit, lockout dan daftar session. Header hanya dipercaya dari alamat TrustedProxies
+	// (IP atau CIDR); kosong berarti IP koneksi langsung. Proxy harus menimpa header ini, bukan
+	// menambahkannya, karena nilai pertama yang valid yang dipakai.
+	ProxyHeader    string
+	TrustedProxies []string
+}
+
+// DBConfig konfigurasi koneksi dan pool PostgreSQL
+type DBConfig struct {
+	Host            string
+	Port            string
+	User            string
+	Password        string
+	Name            string
+	SSLMode         string
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// JWTConfig konfigurasi penandatanganan token
+type JWTConfig struct {
+	// Secret HS256 bersama. Bila SigningKeyFile di-set, Secret hanya dipakai untuk memverifikasi
+	// token HS256 lama selama transisi dan sebaiknya dikosongkan setelah token tersebut kedaluwarsa.
+	Secret string
+	// SigningKeyFile private key PEM (RSA atau Ed25519) untuk menandatangani token dengan RS256/EdDSA
+	SigningKeyFile string
+	// VerificationKeyFiles kunci PEM tambahan yang masih diterima saat verifikasi, untuk rotasi:
+	// kunci lama tetap di sini sampai token terakhirnya kedaluwarsa
+	VerificationKeyFiles []string
+	// Keys kunci hasil memuat Secret dan file di atas; nil berarti HS256 dengan Secret
+	Keys *jwtkeys.Set
+	// TTL masa berlaku access token, dibuat pendek karena dapat diperpanjang lewat refresh token
+	TTL time.Duration
+	// RefreshTTL masa berlaku refresh token; setiap rotasi memperpanjang session sebesar nilai ini
+	RefreshTTL time.Duration
+	// RevocationCacheSize dan RevocationCacheTTL membatasi cache status pencabutan token dan
+	// session di memori; TTL juga batas waktu pencabutan dari instance lain mulai berlaku
+	RevocationCacheSize int
+	RevocationCacheTTL  time.Duration
+	// PurgeInterval jarak antar penghapusan pencabutan token yang sudah kedaluwarsa
+	PurgeInterval time.Duration
+}
+
+// CORSConfig konfigurasi origin yang diizinkan
+type CORSConfig struct {
+	AllowOrigins []string
+}
+
+// UploadConfig konfigurasi penyimpanan file upload
+type UploadConfig struct {
+	Root string
+}
+
+// APIConfig konfigurasi versi API dan masa transisi path lama tanpa prefix versi
+type APIConfig struct {
+	// LegacyRoutes tetap melayani path lama (/products) di samping /api/v1/products
+	LegacyRoutes bool
+	// LegacyDeprecatedAt dan LegacySunset dikirim lewat header Deprecation dan Sunset;
+	// setelah LegacySunset lewat, path lama menjawab 410 Gone
+	LegacyDeprecatedAt time.Time
+	LegacySunset       time.Time
+}
+
+// AuthConfig aturan pendaftaran akun
+type AuthConfig struct {
+	// PublicRegistration mengizinkan /register tanpa kode undangan; akun yang dibuat selalu ber-role user
+	PublicRegistration bool
+	// InviteTTL masa berlaku default kode undangan
+	InviteTTL time.Duration
+	// ResetTTL masa berlaku token reset password
+	ResetTTL time.Duration
+	// LoginMaxFailures kegagalan login beruntun (dalam LoginLockout) sebelum username dikunci selama LoginLockout
+	LoginMaxFailures int
+	LoginLockout     time.Duration
+	// LoginDelay jeda minimal setelah kegagalan kedua, berlipat dua setiap kegagalan berikutnya
+	LoginDelay time.Duration
+	// LoginRateLimit jumlah request auth per IP dalam LoginRateWindow; 0 mematikan throttling
+	LoginRateLimit  int
+	LoginRateWindow time.Duration
+	// TOTPIssuer nama yang tampil di aplikasi authenticator
+	TOTPIssuer string
+	// TOTPRequiredRoles role yang wajib memakai 2FA; tanpa pendaftaran hanya endpoint pendaftaran 2FA yang bisa diakses
+	TOTPRequiredRoles []models.UserRole
+	// MFAChallengeTTL masa berlaku token tantangan antara password benar dan kode TOTP
+	MFAChallengeTTL time.Duration
+	// Password kebijakan password baru; PasswordBlocklistFile menambah daftar password umum bawaan
+	Password              password.Policy
+	PasswordBlocklistFile string
+	// BcryptCost cost hash password baru; hash lama dengan cost lebih rendah di-hash ulang saat login
+	BcryptCost int
+	// ImpersonationTTL masa berlaku token impersonasi admin; tidak dapat diperpanjang
+	ImpersonationTTL time.Duration
+}
+
+// OIDCConfig single sign-on lewat identity provider OpenID Connect. Issuer kosong mematikan SSO.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL halaman frontend yang menerima code dan state dari provider lalu meneruskannya
+	// ke /auth/oidc/callback; harus sama dengan yang didaftarkan di provider
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim dan UsernameClaim nama claim ID token yang berisi grup dan username
+	GroupsClaim   string
+	UsernameClaim string
+	// RoleMapping grup provider ke role; user dengan beberapa grup yang cocok mendapat role tertinggi.
+	// Bila di-set, role user disamakan dengan provider di setiap login SSO.
+	RoleMapping map[string]models.UserRole
+	// DefaultRole role user tanpa grup yang cocok; kosong berarti user tersebut tidak boleh login
+	DefaultRole models.UserRole
+	// LinkExisting menautkan login pertama ke user lokal dengan username yang sama persis, hanya
+	// bila provider mengirim <UsernameClaim>_verified=true dan user tersebut bukan admin. Selain
+	// itu username yang sudah dipakai ditolak agar akun lokal tidak bisa diambil alih lewat provider.
+	LinkExisting bool
+	// StateTTL batas waktu antara /auth/oidc/authorize dan callback
+	StateTTL time.Duration
+}
+
+// NotifyConfig kanal pengiriman pesan ke user. Driver "log" menulis ke log aplikasi,
+// "file" menambahkan baris JSON ke File; keduanya untuk development.
+type NotifyConfig struct {
+	Driver string
+	File   string
+}
+
+// AuthzConfig matriks role dan permission. PolicyFile kosong berarti memakai matriks bawaan.
+type AuthzConfig struct {
+	PolicyFile string
+	Policy     *authz.Policy
+}
+
+// DSN menyusun connection string PostgreSQL
+func (c DBConfig) DSN() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   c.Host + ":" + c.Port,
+		Path:   "/" + c.Name,
+	}
+	q := url.Values{}
+	q.Set("sslmode", c.SSLMode)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// source mencari nilai konfigurasi berdasarkan key
+type source func(key string) (string, bool)
+
+// Load memuat konfigurasi dengan prioritas: environment variable, file CONFIG_FILE, lalu .env.
+// Konfigurasi yang tidak valid dikembalikan sebagai error agar server menolak start.
+func Load() (*Config, error) {
+	lookup, err := sources()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := parse(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadDB hanya memuat dan memvalidasi konfigurasi database, untuk subcommand seperti migrate
+// yang tidak butuh secret JWT maupun pengaturan auth
+func LoadDB() (DBConfig, error) {
+	lookup, err := sources()
+	if err != nil {
+		return DBConfig{}, err
+	}
+
+	p := parser{lookup: lookup}
+	db := p.db()
+	if len(p.errs) > 0 {
+		return DBConfig{}, fmt.Errorf("invalid configuration: %w", errors.Join(p.errs...))
+	}
+	if errs := db.validate(); len(errs) > 0 {
+		return DBConfig{}, fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return db, nil
+}
+
+// sources menyusun lookup dari environment variable, file CONFIG_FILE, lalu .env
+func sources() (source, error) {
+	var files []map[string]string
+
+	if path, ok := os.LookupEnv("CONFIG_FILE"); ok && path != "" {
+		values, err := godotenv.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+		}
+		files = append(files, values)
+	}
+
+	if values, err := godotenv.Read(".env"); err == nil {
+		files = append(files, values)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading .env: %w", err)
+	}
+
+	return func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		for _, values := range files {
+			if value, ok := values[key]; ok {
+				return value, true
+			}
+		}
+		return "", false
+	}, nil
+}
+
+// parse membaca seluruh key ke dalam Config, mengumpulkan error format sekaligus
+func parse(lookup source) (*Config, error) {
+	p := parser{lookup: lookup}
+
+	cfg := &Config{
+		Server: ServerConfig{
+			Port:                p.string("PORT", "3000"),
+			BodyLimit:           p.int("BODY_LIMIT", 10*1024*1024),
+			ShutdownTimeout:     p.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownGracePeriod: p.duration("SHUTDOWN_GRACE_PERIOD", 0),
+			RequestTimeout:      p.duration("REQUEST_TIMEOUT", 10*time.Second),
+			UploadTimeout:       p.duration("UPLOAD_TIMEOUT", 60*time.Second),
+			RouteTimeouts:       p.durations("ROUTE_TIMEOUTS"),
+			ProxyHeader:         p.string("PROXY_HEADER", ""),
+			TrustedProxies:      p.list("TRUSTED_PROXIES", nil),
+		},
+		DB: p.db(),
+		JWT: JWTConfig{
+			Secret:     p.string("JWT_SECRET", ""),
+			TTL:        p.duration("JWT_TTL", 15*time.Minute),
+			RefreshTTL: p.duration("JWT_REFRESH_TTL", 30*24*time.Hour),
+
+			RevocationCacheSize: p.int("JWT_REVOCATION_CACHE_SIZE", 10000),
+			RevocationCacheTTL:  p.duration("JWT_REVOCATION_CACHE_TTL", 30*time.Second),
+			PurgeInterval:       p.duration("JWT_PURGE_INTERVAL", time.Hour),
+		},
+		CORS: CORSConfig{
+			AllowOrigins: p.list("CORS_ALLOW_ORIGINS", []string{"*"}),
+		},
+		Upload: UploadConfig{
+			Root: p.string("UPLOAD_ROOT", "uploads"),
+		},
+		Auth: AuthConfig{
+			PublicRegistration: p.bool("AUTH_PUBLIC_REGISTRATION", true),
+			InviteTTL:          p.duration("AUTH_INVITE_TTL", 72*time.Hour),
+			ResetTTL:           p.duration("AUTH_RESET_TTL", 30*time.Minute),
+			LoginMaxFailures:   p.int("AUTH_LOGIN_MAX_FAILURES", 5),
+			LoginLockout:       p.duration("AUTH_LOGIN_LOCKOUT", 15*time.Minute),
+			LoginDelay:         p.duration("AUTH_LOGIN_DELAY", time.Second),
+			LoginRateLimit:     p.int("AUTH_LOGIN_RATE_LIMIT", 20),
+			LoginRateWindow:    p.duration("AUTH_LOGIN_RATE_WINDOW", time.Minute),
+			TOTPIssuer:         p.string("AUTH_TOTP_ISSUER", "Backend Compro"),
+			TOTPRequiredRoles:  p.roles("AUTH_TOTP_REQUIRED_ROLES"),
+			MFAChallengeTTL:    p.duration("AUTH_MFA_CHALLENGE_TTL", 5*time.Minute),
+			Password: password.Policy{
+				MinLength:        p.int("AUTH_PASSWORD_MIN_LENGTH", 8),
+				RequireUpper:     p.bool("AUTH_PASSWORD_REQUIRE_UPPER", true),
+				RequireLower:     p.bool("AUTH_PASSWORD_REQUIRE_LOWER", true),
+				RequireDigit:     p.bool("AUTH_PASSWORD_REQUIRE_DIGIT", true),
+				RequireSymbol:    p.bool("AUTH_PASSWORD_REQUIRE_SYMBOL", false),
+				DisallowPersonal: p.bool("AUTH_PASSWORD_DISALLOW_PERSONAL", true),
+				CheckCommon:      p.bool("AUTH_PASSWORD_CHECK_COMMON", true),
+			},
+			BcryptCost:       p.int("AUTH_BCRYPT_COST", bcrypt.DefaultCost),
+			ImpersonationTTL: p.duration("AUTH_IMPERSONATION_TTL", 15*time.Minute),
+		},
+		OIDC: OIDCConfig{
+			Issuer:        p.string("OIDC_ISSUER", ""),
+			ClientID:      p.string("OIDC_CLIENT_ID", ""),
+			ClientSecret:  p.string("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:   p.string("OIDC_REDIRECT_URL", ""),
+			Scopes:        p.list("OIDC_SCOPES", []string{"openid", "profile", "email"}),
+			GroupsClaim:   p.string("OIDC_GROUPS_CLAIM", "groups"),
+			UsernameClaim: p.string("OIDC_USERNAME_CLAIM", "preferred_username"),
+			RoleMapping:   p.roleMapping("OIDC_ROLE_MAPPING"),
+			DefaultRole:   p.role("OIDC_DEFAULT_ROLE", models.RoleUser),
+			LinkExisting:  p.bool("OIDC_LINK_EXISTING", false),
+			StateTTL:      p.duration("OIDC_STATE_TTL", 10*time.Minute),
+		},
+		Notify: NotifyConfig{
+			Driver: p.string("NOTIFY_DRIVER", "log"),
+			File:   p.string("NOTIFY_FILE", ""),
+		},
+		API: APIConfig{
+			LegacyRoutes:       p.bool("API_LEGACY_ROUTES", true),
+			LegacyDeprecatedAt: p.time("API_LEGACY_DEPRECATED_AT"),
+			LegacySunset:       p.time("API_LEGACY_SUNSET"),
+		},
+	}
+	cfg.JWT.SigningKeyFile = p.string("JWT_SIGNING_KEY_FILE", "")
+	cfg.JWT.VerificationKeyFiles = p.list("JWT_VERIFICATION_KEY_FILES", nil)
+	cfg.JWT.Keys = p.keys(cfg.JWT)
+	cfg.Auth.PasswordBlocklistFile = p.string("AUTH_PASSWORD_BLOCKLIST_FILE", "")
+	cfg.Auth.Password.Blocklist = p.blocklist("AUTH_PASSWORD_BLOCKLIST_FILE", cfg.Auth.PasswordBlocklistFile)
+	cfg.Authz.PolicyFile = p.string("AUTHZ_POLICY_FILE", "")
+	cfg.Authz.Policy = p.policy("AUTHZ_POLICY_FILE", cfg.Authz.PolicyFile)
+
+	if len(p.errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", errors.Join(p.errs...))
+	}
+	return cfg, nil
+}
+
+// Validate memeriksa nilai wajib dan batasan antar field
+func (c *Config) Validate() error {
+	var errs []error
+	required := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	if _, err := strconv.Atoi(c.Server.Port); err != nil {
+		errs = append(errs, fmt.Errorf("PORT must be numeric, got %q", c.Server.Port))
+	}
+	if c.Server.BodyLimit <= 0 {
+		errs = append(errs, errors.New("BODY_LIMIT must be positive"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.Server.ShutdownGracePeriod < 0 {
+		errs = append(errs, errors.New("SHUTDOWN_GRACE_PERIOD must not be negative"))
+	}
+	if c.Server.RequestTimeout <= 0 || c.Server.UploadTimeout <= 0 {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT and UPLOAD_TIMEOUT must be positive"))
+	}
+	for group, d := range c.Server.RouteTimeouts {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("ROUTE_TIMEOUTS: timeout for %q must be positive", group))
+		}
+	}
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		errs = append(errs, errors.New("TRUSTED_PROXIES is required when PROXY_HEADER is set"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must contain IP addresses or CIDR ranges, got %q", proxy))
+			}
+		}
+	}
+
+	errs = append(errs, c.DB.validate()...)
+
+	if c.JWT.SigningKeyFile == "" {
+		required("JWT_SECRET", c.JWT.Secret)
+	}
+	if c.JWT.Secret != "" && len(c.JWT.Secret) < minJWTSecretLength {
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters", minJWTSecretLength))
+	}
+	if c.JWT.TTL <= 0 {
+		errs = append(errs, errors.New("JWT_TTL must be positive"))
+	}
+	if c.JWT.RefreshTTL <= c.JWT.TTL {
+		errs = append(errs, errors.New("JWT_REFRESH_TTL must be longer than JWT_TTL"))
+	}
+	if c.JWT.RevocationCacheSize < 0 || c.JWT.RevocationCacheTTL < 0 {
+		errs = append(errs, errors.New("JWT_REVOCATION_CACHE_SIZE and JWT_REVOCATION_CACHE_TTL must not be negative"))
+	}
+	if c.JWT.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("JWT_PURGE_INTERVAL must be positive"))
+	}
+
+	if c.Auth.InviteTTL <= 0 || c.Auth.ResetTTL <= 0 {
+		errs = append(errs, errors.New("AUTH_INVITE_TTL and AUTH_RESET_TTL must be positive"))
+	}
+	if c.Auth.LoginMaxFailures < 1 || c.Auth.LoginLockout <= 0 {
+		errs = append(errs, errors.New("AUTH_LOGIN_MAX_FAILURES must be at least 1 and AUTH_LOGIN_LOCKOUT positive"))
+	}
+	if c.Auth.LoginDelay < 0 || c.Auth.LoginRateLimit < 0 || c.Auth.LoginRateWindow <= 0 {
+		errs = append(errs, errors.New("AUTH_LOGIN_DELAY and AUTH_LOGIN_RATE_LIMIT must not be negative and AUTH_LOGIN_RATE_WINDOW must be positive"))
+	}
+	required("AUTH_TOTP_ISSUER", c.Auth.TOTPIssuer)
+	if c.Auth.MFAChallengeTTL <= 0 {
+		errs = append(errs, errors.New("AUTH_MFA_CHALLENGE_TTL must be positive"))
+	}
+	if c.Auth.Password.MinLength < minPasswordLength || c.Auth.Password.MinLength > password.MaxBytes {
+		errs = append(errs, fmt.Errorf("AUTH_PASSWORD_MIN_LENGTH must be between %d and %d", minPasswordLength, password.MaxBytes))
+	}
+	if c.Auth.BcryptCost < bcrypt.DefaultCost || c.Auth.BcryptCost > maxBcryptCost {
+		errs = append(errs, fmt.Errorf("AUTH_BCRYPT_COST must be between %d and %d", bcrypt.DefaultCost, maxBcryptCost))
+	}
+	if c.Auth.ImpersonationTTL <= 0 || c.Auth.ImpersonationTTL > maxImpersonationTTL {
+		errs = append(errs, fmt.Errorf("AUTH_IMPERSONATION_TTL must be positive and at most %s", maxImpersonationTTL))
+	}
+	if c.OIDC.Issuer != "" {
+		if u, err := url.Parse(c.OIDC.Issuer); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OIDC_ISSUER must be an absolute URL, got %q", c.OIDC.Issuer))
+		}
+		required("OIDC_CLIENT_ID", c.OIDC.ClientID)
+		required("OIDC_REDIRECT_URL", c.OIDC.RedirectURL)
+		required("OIDC_USERNAME_CLAIM", c.OIDC.UsernameClaim)
+		if !slices.Contains(c.OIDC.Scopes, "openid") {
+			errs = append(errs, errors.New("OIDC_SCOPES must include openid"))
+		}
+		if c.OIDC.StateTTL <= 0 {
+			errs = append(errs, errors.New("OIDC_STATE_TTL must be positive"))
+		}
+	}
+	switch c.Notify.Driver {
+	case "log":
+	case "file":
+		required("NOTIFY_FILE", c.Notify.File)
+	default:
+		errs = append(errs, fmt.Errorf("NOTIFY_DRIVER must be log or file, got %q", c.Notify.Driver))
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("CORS_ALLOW_ORIGINS must not be empty"))
+	}
+	required("UPLOAD_ROOT", c.Upload.Root)
+
+	if !c.API.LegacyDeprecatedAt.IsZero() && !c.API.LegacySunset.IsZero() && !c.API.LegacySunset.After(c.API.LegacyDeprecatedAt) {
+		errs = append(errs, errors.New("API_LEGACY_SUNSET must be after API_LEGACY_DEPRECATED_AT"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate memeriksa konfigurasi database; dipakai Validate dan LoadDB
+func (c DBConfig) validate() []error {
+	var errs []error
+	required := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	required("DB_HOST", c.Host)
+	required("DB_USER", c.User)
+	required("DB_NAME", c.Name)
+	if _, err := strconv.Atoi(c.Port); err != nil {
+		errs = append(errs, fmt.Errorf("DB_PORT must be numeric, got %q", c.Port))
+	}
+	if c.MaxConns < 1 {
+		errs = append(errs, errors.New("DB_MAX_CONNS must be at least 1"))
+	}
+	if c.MinConns < 0 || c.MinConns > c.MaxConns {
+		errs = append(errs, errors.New("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS"))
+	}
+	return errs
+}
+
+// parser membantu konversi tipe sambil mencatat error per key
+type parser struct {
+	lookup source
+	errs   []error
+}
+
+// db membaca konfigurasi database
+func (p *parser) db() DBConfig {
+	return DBConfig{
+		Host:            p.string("DB_HOST", ""),
+		Port:            p.string("DB_PORT", "5432"),
+		User:            p.string("DB_USER", ""),
+		Password:        p.string("DB_PASSWORD", ""),
+		Name:            p.string("DB_NAME", ""),
+		SSLMode:         p.string("DB_SSLMODE", "disable"),
+		MaxConns:        int32(p.int("DB_MAX_CONNS", 10)),
+		MinConns:        int32(p.int("DB_MIN_CONNS", 0)),
+		MaxConnLifetime: p.duration("DB_MAX_CONN_LIFETIME", time.Hour),
+		MaxConnIdleTime: p.duration("DB_MAX_CONN_IDLE_TIME", 30*time.Minute),
+	}
+}
+
+func (p *parser) string(key, fallback string) string {
+	if value, ok := p.lookup(key); ok {
+		return strings.TrimSpace(value)
+	}
+	return fallback
+}
+
+func (p *parser) int(key string, fallback int) int {
+	value, ok := p.lookup(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+		return fallback
+	}
+	return n
+}
+
+func (p *parser) bool(key string, fallback bool) bool {
+	value, ok := p.lookup(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+		return fallback
+	}
+	return b
+}
+
+// time menerima tanggal (2006-01-02, dianggap UTC) atau RFC3339; kosong berarti tidak di-set
+func (p *parser) time(key string) time.Time {
+	value, ok := p.lookup(key)
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	p.errs = append(p.errs, fmt.Errorf("%s must be a date like 2025-12-31 or RFC3339 timestamp, got %q", key, value))
+	return time.Time{}
+}
+
+func (p *parser) duration(key string, fallback time.Duration) time.Duration {
+	value, ok := p.lookup(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be a duration like 30s or 15m, got %q", key, value))
+		return fallback
+	}
+	return d
+}
+
+// durations pasangan nama=durasi dipisah koma, mis. "products=30s,auth=5s"
+func (p *parser) durations(key string) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for _, item := range p.list(key, nil) {
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || name == "" || err != nil {
+			p.errs = append(p.errs, fmt.Errorf("%s: entry must look like group=30s, got %q", key, item))
+			continue
+		}
+		durations[name] = d
+	}
+	return durations
+}
+
+// policy memuat matriks permission dari file JSON, atau matriks bawaan bila path kosong
+func (p *parser) policy(key, path string) *authz.Policy {
+	if path == "" {
+		return authz.Default()
+	}
+	policy, err := authz.Load(path)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %w", key, err))
+		return authz.Default()
+	}
+	return policy
+}
+
+// blocklist memuat daftar password tambahan; path kosong berarti hanya daftar bawaan
+func (p *parser) blocklist(key, path string) map[string]struct{} {
+	if path == "" {
+		return nil
+	}
+	list, err := password.LoadList(path)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %w", key, err))
+	}
+	return list
+}
+
+// roles daftar role dipisah koma; role yang tidak dikenal dicatat sebagai error
+func (p *parser) roles(key string) []models.UserRole {
+	var roles []models.UserRole
+	for _, item := range p.list(key, nil) {
+		role := models.UserRole(item)
+		switch role {
+		case models.RoleAdmin, models.RoleStaff, models.RoleUser:
+			roles = append(roles, role)
+		default:
+			p.errs = append(p.errs, fmt.Errorf("%s: unknown role %q", key, item))
+		}
+	}
+	return roles
+}
+
+// role satu role; string kosong berarti tidak ada role (mis. tanpa role default)
+func (p *parser) role(key string, fallback models.UserRole) models.UserRole {
+	value, ok := p.lookup(key)
+	if !ok {
+		return fallback
+	}
+	role := models.UserRole(strings.TrimSpace(value))
+	switch role {
+	case "", models.RoleAdmin, models.RoleStaff, models.RoleUser:
+		return role
+	}
+	p.errs = append(p.errs, fmt.Errorf("%s: unknown role %q", key, value))
+	return fallback
+}
+
+// roleMapping pasangan grup=role dipisah koma, mis. "cms-admins=admin,editors=staff"
+func (p *parser) roleMapping(key string) map[string]models.UserRole {
+	mapping := make(map[string]models.UserRole)
+	for _, item := range p.list(key, nil) {
+		group, role, ok := strings.Cut(item, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		switch models.UserRole(role) {
+		case models.RoleAdmin, models.RoleStaff, models.RoleUser:
+			if ok && group != "" {
+				mapping[group] = models.UserRole(role)
+				continue
+			}
+		}
+		p.errs = append(p.errs, fmt.Errorf("%s: entry must look like group=role with a known role, got %q", key, item))
+	}
+	return mapping
+}
+
+// keys menyusun kunci JWT. Tanpa JWT_SIGNING_KEY_FILE token ditandatangani HS256 dengan JWT_SECRET;
+// dengan file tersebut token ditandatangani RS256/EdDSA dan JWT_SECRET hanya untuk verifikasi.
+func (p *parser) keys(c JWTConfig) *jwtkeys.Set {
+	if c.SigningKeyFile == "" {
+		set, err := jwtkeys.NewSet(jwtkeys.FromSecret(c.Secret))
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("JWT_SECRET: %w", err))
+		}
+		return set
+	}
+
+	signing, err := jwtkeys.LoadFile(c.SigningKeyFile)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("JWT_SIGNING_KEY_FILE: %w", err))
+		return nil
+	}
+	var verification []*jwtkeys.Key
+	for _, path := range c.VerificationKeyFiles {
+		key, err := jwtkeys.LoadFile(path)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("JWT_VERIFICATION_KEY_FILES: %w", err))
+			continue
+		}
+		verification = append(verification, key)
+	}
+	if c.Secret != "" {
+		verification = append(verification, jwtkeys.FromSecret(c.Secret))
+	}
+
+	set, err := jwtkeys.NewSet(signing, verification...)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("JWT_SIGNING_KEY_FILE: %w", err))
+	}
+	return set
+}
+
+func (p *parser) list(key string, fallback []string) []string {
+	value, ok := p.lookup(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setEnv memasang konfigurasi minimal yang valid lalu menimpanya dengan overrides. Direktori
+// kerja dipindah ke direktori kosong agar .env milik developer tidak ikut terbaca.
+func setEnv(t *testing.T, overrides map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	env := map[string]string{
+		"DB_HOST":    "localhost",
+		"DB_USER":    "app",
+		"DB_NAME":    "compro",
+		"JWT_SECRET": strings.Repeat("s", minJWTSecretLength),
+	}
+	for key, value := range overrides {
+		env[key] = value
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "valid"},
+		{name: "missing JWT secret", env: map[string]string{"JWT_SECRET": ""}, want: "JWT_SECRET is required"},
+		{name: "short JWT secret", env: map[string]string{"JWT_SECRET": "too-short"}, want: "JWT_SECRET must be at least 32 characters"},
+		{name: "invalid duration", env: map[string]string{"JWT_TTL": "15 minutes"}, want: `JWT_TTL must be a duration like 30s or 15m, got "15 minutes"`},
+		{name: "invalid route timeout", env: map[string]string{"ROUTE_TIMEOUTS": "products=soon"}, want: "ROUTE_TIMEOUTS"},
+		{name: "non-positive duration", env: map[string]string{"REQUEST_TIMEOUT": "0s"}, want: "REQUEST_TIMEOUT and UPLOAD_TIMEOUT must be positive"},
+		{name: "refresh shorter than access", env: map[string]string{"JWT_TTL": "2h", "JWT_REFRESH_TTL": "1h"}, want: "JWT_REFRESH_TTL must be longer than JWT_TTL"},
+		{name: "pool min above max", env: map[string]string{"DB_MAX_CONNS": "2", "DB_MIN_CONNS": "5"}, want: "DB_MIN_CONNS must be between 0 and DB_MAX_CONNS"},
+		{name: "invalid pool size", env: map[string]string{"DB_MAX_CONNS": "ten"}, want: `DB_MAX_CONNS must be an integer, got "ten"`},
+		{name: "proxy header without proxies", env: map[string]string{"PROXY_HEADER": "X-Forwarded-For"}, want: "TRUSTED_PROXIES is required when PROXY_HEADER is set"},
+		{name: "invalid CIDR", env: map[string]string{"PROXY_HEADER": "X-Forwarded-For", "TRUSTED_PROXIES": "10.0.0.0/8,10.1.0.0/33"}, want: `got "10.1.0.0/33"`},
+		{name: "hostname as proxy", env: map[string]string{"PROXY_HEADER": "X-Forwarded-For", "TRUSTED_PROXIES": "proxy.internal"}, want: `got "proxy.internal"`},
+		{name: "trusted proxies", env: map[string]string{"PROXY_HEADER": "X-Forwarded-For", "TRUSTED_PROXIES": "10.0.0.0/8, 192.168.1.10, ::1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+			cfg, err := Load()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				if cfg.JWT.Keys == nil || cfg.Authz.Policy == nil {
+					t.Errorf("Load() = %+v, want signing keys and a policy", cfg)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	setEnv(t, map[string]string{"JWT_SECRET": "too-short", "DB_HOST": "", "BODY_LIMIT": "0"})
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load() error = nil")
+	}
+	for _, want := range []string{"JWT_SECRET", "DB_HOST is required", "BODY_LIMIT must be positive"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	setEnv(t, map[string]string{"JWT_TTL": "10m"})
+	path := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(path, []byte("JWT_TTL=20m\nPORT=8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Environment variable mengalahkan CONFIG_FILE, key lain diambil dari file
+	if cfg.JWT.TTL != 10*time.Minute || cfg.Server.Port != "8080" {
+		t.Errorf("JWT_TTL = %s, PORT = %s, want 10m from the environment and 8080 from the file", cfg.JWT.TTL, cfg.Server.Port)
+	}
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+	if _, err := Load(); err == nil {
+		t.Error("Load() with a missing CONFIG_FILE = nil error")
+	}
+}
+
+func TestLoadDB(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		// migrate tidak membutuhkan secret JWT maupun konfigurasi auth
+		{name: "without JWT secret", env: map[string]string{"JWT_SECRET": "", "JWT_TTL": "soon"}},
+		{name: "missing host", env: map[string]string{"DB_HOST": ""}, want: "DB_HOST is required"},
+		{name: "invalid port", env: map[string]string{"DB_PORT": "postgres"}, want: `DB_PORT must be numeric, got "postgres"`},
+		{name: "pool min above max", env: map[string]string{"DB_MAX_CONNS": "2", "DB_MIN_CONNS": "5"}, want: "DB_MIN_CONNS must be between 0 and DB_MAX_CONNS"},
+		{name: "invalid duration", env: map[string]string{"DB_MAX_CONN_LIFETIME": "forever"}, want: "DB_MAX_CONN_LIFETIME must be a duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+			db, err := LoadDB()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("LoadDB() error = %v", err)
+				}
+				if want := "postgres://app:@localhost:5432/compro?sslmode=disable"; db.DSN() != want {
+					t.Errorf("DSN() = %s, want %s", db.DSN(), want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadDB() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
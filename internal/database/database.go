@@ -1,6 +1,7 @@
 package database
 
 import (
+	"backend-go/internal/config"
 	"context"
 	"fmt"
 
@@ -10,13 +11,19 @@ import (
 var DB *pgxpool.Pool
 
 // InitDB menginisialisasi koneksi database PostgreSQL
-func InitDB(connString string) error {
-	config, err := pgxpool.ParseConfig(connString)
+func InitDB(cfg config.DBConfig) error {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN())
 	if err != nil {
 		return fmt.Errorf("error parsing connection string: %w", err)
 	}
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	// Ukuran pool dan umur koneksi diambil dari konfigurasi
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return fmt.Errorf("error creating connection pool: %w", err)
 	}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationFS membuat fstest.MapFS dari nama file; isi file diambil dari namanya
+func migrationFS(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys[name] = &fstest.MapFile{Data: []byte("-- " + name)}
+	}
+	return fsys
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFS(
+		"0002_create_posts.down.sql", "0002_create_posts.up.sql",
+		"0001_create_users.up.sql", "0001_create_users.down.sql",
+		"README.md",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("migrations = %+v, want two", migrations)
+	}
+	first := migrations[0]
+	if first.Version != 1 || first.Name != "create_users" || first.Up != "-- 0001_create_users.up.sql" || first.Down != "-- 0001_create_users.down.sql" {
+		t.Errorf("migrations[0] = %+v", first)
+	}
+	if migrations[1].Version != 2 {
+		t.Errorf("migrations[1] = %+v, want version 2", migrations[1])
+	}
+}
+
+func TestLoadMigrationsRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "duplicate version", files: []string{
+			"0001_create_users.up.sql", "0001_create_users.down.sql", "1_create_users.up.sql",
+		}, want: "migration 1 has two up scripts"},
+		{name: "conflicting names", files: []string{
+			"0001_create_users.up.sql", "0001_create_users.down.sql", "0001_create_accounts.up.sql",
+		}, want: "conflicting names"},
+		{name: "gap", files: []string{
+			"0001_create_users.up.sql", "0001_create_users.down.sql",
+			"0003_create_posts.up.sql", "0003_create_posts.down.sql",
+		}, want: "migration 2 is missing before 3_create_posts"},
+		{name: "not starting at one", files: []string{
+			"0002_create_posts.up.sql", "0002_create_posts.down.sql",
+		}, want: "migration 1 is missing"},
+		{name: "up without down", files: []string{
+			"0001_create_users.up.sql", "0001_create_users.down.sql", "0002_create_posts.up.sql",
+		}, want: "migration 2_create_posts has no down script"},
+		{name: "down without up", files: []string{
+			"0001_create_users.down.sql",
+		}, want: "migration 1_create_users has no up script"},
+		{name: "missing name", files: []string{"0001.up.sql"}, want: "invalid migration file name"},
+		{name: "invalid version", files: []string{"v1_create_users.up.sql"}, want: "invalid migration version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(migrationFS(tt.files...))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("loadMigrations() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+}
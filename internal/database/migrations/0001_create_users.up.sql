@@ -0,0 +1,34 @@
+-- Fungsi bersama untuk mengisi edited_at setiap kali baris diubah
+CREATE OR REPLACE FUNCTION set_edited_at() RETURNS trigger AS $$
+BEGIN
+    IF NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at THEN
+        NEW.edited_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE users (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    phone       VARCHAR(20)  NOT NULL,
+    username    VARCHAR(50)  NOT NULL,
+    password    VARCHAR(255) NOT NULL,
+    role        VARCHAR(20)  NOT NULL DEFAULT 'user'
+                CHECK (role IN ('admin', 'staff', 'user')),
+    status      BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by  INTEGER      REFERENCES users(id),
+    edited_at   TIMESTAMPTZ,
+    edited_by   INTEGER      REFERENCES users(id),
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  INTEGER      REFERENCES users(id)
+);
+
+-- Username dan nomor telepon hanya unik di antara user yang belum dihapus
+CREATE UNIQUE INDEX users_username_active_key ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_phone_active_key ON users (phone) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER users_set_edited_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
@@ -0,0 +1,19 @@
+CREATE TABLE carousel (
+    id          SERIAL PRIMARY KEY,
+    image       VARCHAR(255) NOT NULL,
+    title       VARCHAR(100) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    status      BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by  INTEGER      REFERENCES users(id),
+    edited_at   TIMESTAMPTZ,
+    edited_by   INTEGER      REFERENCES users(id),
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  INTEGER      REFERENCES users(id)
+);
+
+CREATE INDEX carousel_active_created_at_idx ON carousel (created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER carousel_set_edited_at
+    BEFORE UPDATE ON carousel
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS products;
+DROP TYPE IF EXISTS product_type;
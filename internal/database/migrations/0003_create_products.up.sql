@@ -0,0 +1,23 @@
+CREATE TYPE product_type AS ENUM ('physical', 'digital', 'service');
+
+CREATE TABLE products (
+    id           SERIAL PRIMARY KEY,
+    image        VARCHAR(255)  NOT NULL,
+    title        VARCHAR(100)  NOT NULL,
+    description  TEXT          NOT NULL DEFAULT '',
+    type_product product_type  NOT NULL,
+    price        NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+    status       BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    created_by   INTEGER       NOT NULL REFERENCES users(id),
+    edited_at    TIMESTAMPTZ,
+    edited_by    INTEGER       REFERENCES users(id),
+    deleted_at   TIMESTAMPTZ,
+    deleted_by   INTEGER       REFERENCES users(id)
+);
+
+CREATE INDEX products_active_created_at_idx ON products (created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER products_set_edited_at
+    BEFORE UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
@@ -0,0 +1,16 @@
+CREATE TABLE portfolio_images (
+    id          SERIAL PRIMARY KEY,
+    image       VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by  INTEGER      NOT NULL REFERENCES users(id),
+    edited_at   TIMESTAMPTZ,
+    edited_by   INTEGER      REFERENCES users(id),
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  INTEGER      REFERENCES users(id)
+);
+
+CREATE INDEX portfolio_images_active_created_at_idx ON portfolio_images (created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER portfolio_images_set_edited_at
+    BEFORE UPDATE ON portfolio_images
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
@@ -0,0 +1,20 @@
+CREATE TABLE portfolio_review (
+    id          SERIAL PRIMARY KEY,
+    id_product  INTEGER      REFERENCES products(id),
+    title       VARCHAR(100) NOT NULL,
+    description TEXT         NOT NULL,
+    image       VARCHAR(255) NOT NULL DEFAULT '',
+    date        DATE         NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by  INTEGER      NOT NULL REFERENCES users(id),
+    edited_at   TIMESTAMPTZ,
+    edited_by   INTEGER      REFERENCES users(id),
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  INTEGER      REFERENCES users(id)
+);
+
+CREATE INDEX portfolio_review_active_date_idx ON portfolio_review (date DESC) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER portfolio_review_set_edited_at
+    BEFORE UPDATE ON portfolio_review
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
@@ -0,0 +1,23 @@
+CREATE TABLE messages_user (
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    company       VARCHAR(100) NOT NULL DEFAULT '',
+    id_product    INTEGER      REFERENCES products(id),
+    address       TEXT         NOT NULL DEFAULT '',
+    description   TEXT         NOT NULL,
+    date_schedule TIMESTAMPTZ,
+    phone         VARCHAR(20)  NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_by    INTEGER      NOT NULL REFERENCES users(id),
+    edited_at     TIMESTAMPTZ,
+    edited_by     INTEGER      REFERENCES users(id),
+    deleted_at    TIMESTAMPTZ,
+    deleted_by    INTEGER      REFERENCES users(id)
+);
+
+CREATE INDEX messages_user_active_created_at_idx ON messages_user (created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX messages_user_id_product_idx ON messages_user (id_product);
+
+CREATE TRIGGER messages_user_set_edited_at
+    BEFORE UPDATE ON messages_user
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
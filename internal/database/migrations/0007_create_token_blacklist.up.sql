@@ -0,0 +1,8 @@
+CREATE TABLE token_blacklist (
+    id          SERIAL PRIMARY KEY,
+    token       TEXT        NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX token_blacklist_expires_at_idx ON token_blacklist (expires_at);
@@ -0,0 +1,27 @@
+-- Satu session per login; refresh token dirotasi di dalam session yang sama (satu "family")
+CREATE TABLE sessions (
+    id              SERIAL PRIMARY KEY,
+    user_id         INTEGER     NOT NULL REFERENCES users(id),
+    user_agent      TEXT        NOT NULL DEFAULT '',
+    ip_address      TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    revoked_at      TIMESTAMPTZ,
+    revoked_reason  VARCHAR(50)
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id) WHERE revoked_at IS NULL;
+
+-- Hanya hash SHA-256 refresh token yang disimpan. Token yang sudah dirotasi tetap disimpan
+-- (rotated_at terisi) agar pemakaian ulang dapat dideteksi.
+CREATE TABLE refresh_tokens (
+    id          SERIAL PRIMARY KEY,
+    session_id  INTEGER     NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    rotated_at  TIMESTAMPTZ
+);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens (session_id);
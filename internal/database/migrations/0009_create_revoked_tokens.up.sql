@@ -0,0 +1,11 @@
+-- Pencabutan access token berdasarkan claim jti menggantikan blacklist token mentah.
+-- Baris yang expires_at-nya lewat tidak diperlukan lagi dan dihapus oleh job purge.
+DROP TABLE IF EXISTS token_blacklist;
+
+CREATE TABLE revoked_tokens (
+    jti         TEXT        PRIMARY KEY,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
@@ -0,0 +1,13 @@
+-- Undangan registrasi sekali pakai; hanya hash SHA-256 kode undangan yang disimpan
+CREATE TABLE invites (
+    id          SERIAL PRIMARY KEY,
+    code_hash   CHAR(64)    NOT NULL UNIQUE,
+    role        VARCHAR(20) NOT NULL
+                CHECK (role IN ('admin', 'staff', 'user')),
+    created_by  INTEGER     NOT NULL REFERENCES users(id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    used_by     INTEGER     REFERENCES users(id),
+    revoked_at  TIMESTAMPTZ
+);
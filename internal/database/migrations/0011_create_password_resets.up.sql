@@ -0,0 +1,11 @@
+-- Token reset password sekali pakai; hanya hash SHA-256 token yang disimpan
+CREATE TABLE password_resets (
+    id          SERIAL PRIMARY KEY,
+    user_id     INTEGER     NOT NULL REFERENCES users(id),
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ
+);
+
+CREATE INDEX password_resets_user_id_idx ON password_resets (user_id) WHERE used_at IS NULL;
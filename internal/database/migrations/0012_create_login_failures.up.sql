@@ -0,0 +1,24 @@
+-- Percobaan login gagal dilacak per username (termasuk username yang tidak ada) agar
+-- lockout tidak membocorkan akun mana yang terdaftar
+CREATE TABLE login_failures (
+    username         TEXT        PRIMARY KEY,
+    failures         INTEGER     NOT NULL DEFAULT 0,
+    last_failure_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until     TIMESTAMPTZ
+);
+
+-- Riwayat penguncian dan pembukaan kunci untuk ditinjau admin
+CREATE TABLE lockout_events (
+    id            SERIAL PRIMARY KEY,
+    username      TEXT        NOT NULL,
+    user_id       INTEGER     REFERENCES users(id),
+    event         VARCHAR(20) NOT NULL
+                  CHECK (event IN ('locked', 'unlocked')),
+    failures      INTEGER     NOT NULL DEFAULT 0,
+    ip_address    TEXT        NOT NULL DEFAULT '',
+    actor_id      INTEGER     REFERENCES users(id),
+    locked_until  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX lockout_events_username_idx ON lockout_events (username, created_at DESC);
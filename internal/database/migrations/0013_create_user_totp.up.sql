@@ -0,0 +1,25 @@
+-- Metode autentikasi yang dipakai saat session dibuat (pwd, otp), diteruskan ke claim amr
+-- setiap access token hasil refresh
+ALTER TABLE sessions ADD COLUMN amr TEXT[] NOT NULL DEFAULT '{pwd}';
+
+-- Secret TOTP per user. Secret disimpan apa adanya karena dibutuhkan untuk menghitung kode;
+-- confirmed_at NULL berarti pendaftaran belum diverifikasi dan 2FA belum aktif
+CREATE TABLE user_totp (
+    user_id         INTEGER     PRIMARY KEY REFERENCES users(id),
+    secret          TEXT        NOT NULL,
+    confirmed_at    TIMESTAMPTZ,
+    -- Langkah waktu terakhir yang dipakai, mencegah kode yang sama dipakai dua kali
+    last_used_step  BIGINT      NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Kode pemulihan sekali pakai; hanya hash SHA-256 yang disimpan
+CREATE TABLE recovery_codes (
+    id          SERIAL PRIMARY KEY,
+    user_id     INTEGER     NOT NULL REFERENCES users(id),
+    code_hash   CHAR(64)    NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    used_at     TIMESTAMPTZ
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id) WHERE used_at IS NULL;
@@ -0,0 +1,15 @@
+-- API key untuk klien mesin (build situs statis, integrasi CRM). Hanya hash SHA-256 key yang
+-- disimpan; prefix disimpan apa adanya agar key dapat dikenali di daftar dan log
+CREATE TABLE api_keys (
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    prefix        VARCHAR(16)  NOT NULL UNIQUE,
+    key_hash      CHAR(64)     NOT NULL UNIQUE,
+    scopes        TEXT[]       NOT NULL,
+    read_only     BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_by    INTEGER      NOT NULL REFERENCES users(id),
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ,
+    last_used_at  TIMESTAMPTZ,
+    revoked_at    TIMESTAMPTZ
+);
@@ -0,0 +1,5 @@
+-- Gagal bila masih ada lebih dari satu user aktif tanpa nomor telepon
+DROP INDEX users_phone_active_key;
+CREATE UNIQUE INDEX users_phone_active_key ON users (phone) WHERE deleted_at IS NULL;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS oidc_states;
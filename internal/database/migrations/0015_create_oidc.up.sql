@@ -0,0 +1,27 @@
+-- Login SSO yang sedang berjalan antara /auth/oidc/authorize dan callback. Hanya hash SHA-256
+-- state yang disimpan; code verifier PKCE tidak pernah dikirim ke browser
+CREATE TABLE oidc_states (
+    state_hash     CHAR(64)    PRIMARY KEY,
+    nonce          TEXT        NOT NULL,
+    code_verifier  TEXT        NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ NOT NULL
+);
+
+-- Akun identity provider (issuer + sub) yang tertaut ke user lokal
+CREATE TABLE user_identities (
+    id             SERIAL PRIMARY KEY,
+    user_id        INTEGER      NOT NULL REFERENCES users(id),
+    issuer         VARCHAR(255) NOT NULL,
+    subject        VARCHAR(255) NOT NULL,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_login_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
+
+-- User SSO tanpa claim phone_number disimpan dengan nomor telepon kosong, sehingga nilai
+-- kosong tidak ikut unik
+DROP INDEX users_phone_active_key;
+CREATE UNIQUE INDEX users_phone_active_key ON users (phone) WHERE deleted_at IS NULL AND phone <> '';
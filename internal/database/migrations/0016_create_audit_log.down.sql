@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE sessions DROP COLUMN IF EXISTS impersonator_id;
@@ -0,0 +1,22 @@
+-- Admin yang sedang memerankan user lewat session ini; NULL untuk login biasa
+ALTER TABLE sessions ADD COLUMN impersonator_id INTEGER REFERENCES users(id);
+
+-- Jejak audit aksi yang dilakukan atas nama user lain. actor_id admin yang sebenarnya,
+-- user_id user yang diperankan
+CREATE TABLE audit_log (
+    id          SERIAL PRIMARY KEY,
+    action      VARCHAR(50)  NOT NULL,
+    actor_id    INTEGER      NOT NULL REFERENCES users(id),
+    user_id     INTEGER      NOT NULL REFERENCES users(id),
+    session_id  INTEGER      NOT NULL REFERENCES sessions(id),
+    method      VARCHAR(10)  NOT NULL,
+    path        TEXT         NOT NULL,
+    status      INTEGER      NOT NULL,
+    request_id  VARCHAR(64)  NOT NULL DEFAULT '',
+    ip_address  TEXT         NOT NULL DEFAULT '',
+    detail      TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_actor_id_idx ON audit_log (actor_id, created_at DESC);
+CREATE INDEX audit_log_user_id_idx ON audit_log (user_id, created_at DESC);
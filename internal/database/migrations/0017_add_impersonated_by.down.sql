@@ -0,0 +1,6 @@
+ALTER TABLE messages_user DROP COLUMN IF EXISTS impersonated_by;
+ALTER TABLE portfolio_review DROP COLUMN IF EXISTS impersonated_by;
+ALTER TABLE portfolio_images DROP COLUMN IF EXISTS impersonated_by;
+ALTER TABLE products DROP COLUMN IF EXISTS impersonated_by;
+ALTER TABLE carousel DROP COLUMN IF EXISTS impersonated_by;
+ALTER TABLE users DROP COLUMN IF EXISTS impersonated_by;
@@ -0,0 +1,8 @@
+-- Admin yang memerankan user saat baris terakhir kali dibuat, diubah, atau dihapus;
+-- NULL bila penulisan dilakukan user sendiri. Riwayat lengkapnya ada di audit_log
+ALTER TABLE users ADD COLUMN impersonated_by INTEGER REFERENCES users(id);
+ALTER TABLE carousel ADD COLUMN impersonated_by INTEGER REFERENCES users(id);
+ALTER TABLE products ADD COLUMN impersonated_by INTEGER REFERENCES users(id);
+ALTER TABLE portfolio_images ADD COLUMN impersonated_by INTEGER REFERENCES users(id);
+ALTER TABLE portfolio_review ADD COLUMN impersonated_by INTEGER REFERENCES users(id);
+ALTER TABLE messages_user ADD COLUMN impersonated_by INTEGER REFERENCES users(id);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS users_set_edited_at ON users;
+CREATE TRIGGER users_set_edited_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_edited_at();
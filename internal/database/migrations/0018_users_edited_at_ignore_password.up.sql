@@ -0,0 +1,8 @@
+-- Rehash password saat login bukan perubahan data user: trigger edited_at dilewati bila
+-- hanya kolom password yang berubah. Reset password mengisi edited_at sendiri
+DROP TRIGGER IF EXISTS users_set_edited_at ON users;
+CREATE TRIGGER users_set_edited_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    WHEN (to_jsonb(OLD) - 'password' IS DISTINCT FROM to_jsonb(NEW) - 'password')
+    EXECUTE FUNCTION set_edited_at();
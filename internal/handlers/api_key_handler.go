@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/middleware"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler struct {
+	apiKeys repository.APIKeyRepository
+	policy  *authz.Policy
+}
+
+func NewAPIKeyHandler(apiKeys repository.APIKeyRepository, policy *authz.Policy) *APIKeyHandler {
+	return &APIKeyHandler{apiKeys: apiKeys, policy: policy}
+}
+
+// CreateAPIKey godoc
+// @Summary      Create API key
+// @Description  Issue an API key for a machine client, limited to the given permission scopes (wildcards such as products:* are expanded now). Scopes must be held by the caller, and a scope stops working when the caller's role loses it; the key stops working when the caller is deactivated or deleted. The key is only returned once; send it as X-API-Key or as a bearer token.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateAPIKeyRequest  true  "API key data"
+// @Security     ApiKeyAuth
+// @Success      201  {object}  models.APIKeyCreatedResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	perms, err := authz.Expand(req.Scopes)
+	if err != nil {
+		summary := validation.Summary(validation.LanguageFromRequest(c))
+		return apperror.Validation(summary, apperror.FieldError{Field: "scopes", Message: err.Error()})
+	}
+	scopes := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		// Key tidak boleh lebih berkuasa dari pembuatnya
+		if !middleware.Can(c, h.policy, perm) {
+			return apperror.PermissionDenied(string(perm))
+		}
+		scopes = append(scopes, string(perm))
+	}
+
+	key, prefix, err := newAPIKey()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate API key")
+	}
+
+	apiKey := models.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ReadOnly:  req.ReadOnly,
+		CreatedBy: c.Locals("userID").(int),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := h.apiKeys.Create(c.UserContext(), &apiKey, middleware.HashAPIKey(key)); err != nil {
+		return apperror.Wrap(err, "Failed to create API key")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIKeyCreatedResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+// GetAPIKeys godoc
+// @Summary      Get all API keys
+// @Description  List API keys with their scopes and last use, newest first. Keys themselves are never returned.
+// @Tags         api-keys
+// @Produce      json
+// @Param        page   query     int  false  "Page number"     default(1)
+// @Param        limit  query     int  false  "Items per page"  default(10)
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	keys, total, err := h.apiKeys.List(c.UserContext(), repository.Page{Limit: limit, Offset: offset})
+	if err != nil {
+		return apperror.Wrap(err, "Failed to fetch API keys")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": keys,
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke API key
+// @Description  Revoke an API key; requests using it are rejected immediately
+// @Tags         api-keys
+// @Param        id   path      int  true  "API key ID"
+// @Security     ApiKeyAuth
+// @Success      204  "No Content"
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid API key ID format")
+	}
+
+	if err := h.apiKeys.Revoke(c.UserContext(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("API key not found or already revoked")
+		}
+		return apperror.Wrap(err, "Failed to revoke API key")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// newAPIKey membuat key berformat bck_<prefix>_<secret>; prefix (bck_ + 8 hex) disimpan
+// apa adanya untuk identifikasi, secret 256-bit hanya disimpan sebagai bagian dari hash
+func newAPIKey() (key, prefix string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix = middleware.APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
@@ -1,145 +1,530 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/config"
 	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"os"
+	"backend-go/internal/password"
+	"backend-go/internal/repository"
+	"backend-go/internal/totp"
+	"backend-go/internal/validation"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// maxLoginDelay batas atas jeda progresif antar percobaan login gagal
+const maxLoginDelay = time.Minute
+
 type AuthHandler struct {
-    db *pgxpool.Pool
+    users    repository.UserRepository
+    tokens   repository.TokenRepository
+    sessions repository.SessionRepository
+    attempts repository.LoginAttemptRepository
+    totp     repository.TOTPRepository
+    jwt      config.JWTConfig
+    auth     config.AuthConfig
+    // dummyHash dibandingkan saat username tidak ada atau tidak memiliki password, agar waktu
+    // response sama dengan username yang ada
+    dummyHash []byte
 }
 
-func NewAuthHandler(db *pgxpool.Pool) *AuthHandler {
-    return &AuthHandler{db: db}
+func NewAuthHandler(users repository.UserRepository, tokens repository.TokenRepository, sessions repository.SessionRepository, attempts repository.LoginAttemptRepository, totpRepo repository.TOTPRepository, jwtConfig config.JWTConfig, authConfig config.AuthConfig) *AuthHandler {
+    // Cost di luar rentang bcrypt (mis. config kosong) diganti bcrypt.DefaultCost
+    dummyHash, err := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), authConfig.BcryptCost)
+    if err != nil {
+        dummyHash, _ = bcrypt.GenerateFromPassword([]byte(uuid.NewString()), bcrypt.DefaultCost)
+    }
+    return &AuthHandler{users: users, tokens: tokens, sessions: sessions, attempts: attempts, totp: totpRepo, jwt: jwtConfig, auth: authConfig, dummyHash: dummyHash}
 }
 
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user and get JWT token
+// @Description  Authenticate user and get a short-lived JWT access token plus a refresh token. Users with two-factor authentication enabled receive 202 with a short-lived challenge token instead, to be completed at /auth/2fa/verify. Repeated failures for a username add a growing delay between attempts and eventually lock the account temporarily; both are reported as 429 with a Retry-After header. Deactivated accounts are refused with 403.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        credentials  body      models.LoginRequest  true  "Login Credentials"
-// @Success      200  {object}  map[string]string
-// @Failure      401  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Success      200  {object}  models.TokenResponse
+// @Success      202  {object}  models.MFAChallengeResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
     var req models.LoginRequest
     
     if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+        return apperror.BadRequest("Invalid request body")
+    }
+    if err := validation.Validate(c, &req); err != nil {
+        return err
+    }
+
+    // Username yang terkunci atau masih dalam jeda ditolak sebelum password diperiksa
+    failures, err := h.beginLogin(c, req.Username)
+    if err != nil {
+        return err
     }
 
     // Cari user berdasarkan username
-    var user models.User
-    query := `SELECT id, username, name, password, role FROM users WHERE username = $1 AND deleted_at IS NULL` // Diperbaiki
-    err := h.db.QueryRow(context.Background(), query, req.Username).Scan(
-        &user.ID,
-        &user.Username,
-        &user.Name,
-        &user.Password,
-        &user.Role,
-    )
-
-    if err != nil {
-        if err == pgx.ErrNoRows {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "error": "Invalid username or password",
-            })
+    user, err := h.users.GetByUsername(c.UserContext(), req.Username)
+
+    if err != nil {
+        if errors.Is(err, repository.ErrNotFound) {
+            // Username yang tidak ada tetap dihitung dan tetap membayar biaya bcrypt agar tidak
+            // bisa dibedakan dari username yang ada, baik dari lockout maupun waktu response
+            bcrypt.CompareHashAndPassword(h.dummyHash, []byte(req.Password))
+            if err := h.recordLoginFailure(c, req.Username, nil, failures); err != nil {
+                return err
+            }
+            return apperror.Unauthorized("Invalid username or password")
         }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Database error: " + err.Error(), // Untuk debugging
-        })
+        return apperror.Wrap(err, "Failed to login")
     }
 
-    // Bandingkan password
-    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid username or password",
-        })
+    // Bandingkan password; user SSO tanpa password lokal dibandingkan dengan dummyHash agar
+    // waktunya sama dan hasilnya selalu gagal
+    hash := []byte(user.Password)
+    if len(hash) == 0 {
+        hash = h.dummyHash
+    }
+    if err := bcrypt.CompareHashAndPassword(hash, []byte(req.Password)); err != nil {
+        if err := h.recordLoginFailure(c, req.Username, &user.ID, failures); err != nil {
+            return err
+        }
+        return apperror.Unauthorized("Invalid username or password")
     }
 
-    // Buat JWT token
-    claims := models.Claims{
+    // Status baru diperiksa setelah password benar agar tidak membocorkan akun yang nonaktif
+    if !user.Status {
+        return apperror.Forbidden("Account is inactive")
+    }
+
+    h.upgradePasswordHash(c, user, req.Password)
+
+    // Hitungan gagal baru dihapus setelah kode TOTP benar, agar password yang bocor tidak
+    // bisa dipakai untuk mengulang tebakan kode tanpa batas
+    enrollment, err := h.totp.Get(c.UserContext(), user.ID)
+    if err != nil && !errors.Is(err, repository.ErrNotFound) {
+        return apperror.Wrap(err, "Failed to login")
+    }
+    if enrollment != nil && enrollment.ConfirmedAt != nil {
+        return h.challenge(c, user, []string{models.AMRPassword})
+    }
+
+    if err := h.attempts.Clear(c.UserContext(), req.Username); err != nil {
+        return apperror.Wrap(err, "Failed to login")
+    }
+
+    response, err := h.startSession(c, user, []string{models.AMRPassword})
+    if err != nil {
+        return err
+    }
+    return c.JSON(response)
+}
+
+// upgradePasswordHash meng-hash ulang password dengan cost yang dikonfigurasi selagi password-nya
+// diketahui. Kegagalan hanya dicatat karena login tetap sah dengan hash lama.
+func (h *AuthHandler) upgradePasswordHash(c *fiber.Ctx, user *models.User, plain string) {
+    if !password.NeedsRehash(user.Password, h.auth.BcryptCost) {
+        return
+    }
+    hash, err := password.Hash(plain, h.auth.BcryptCost)
+    if err == nil {
+        err = h.users.UpgradePasswordHash(c.UserContext(), user.ID, user.Password, hash)
+    }
+    if err != nil {
+        log.Printf("Failed to upgrade password hash of user %d: %v", user.ID, err)
+    }
+}
+
+// challenge menerbitkan token tantangan 2FA yang ditukar dengan token lewat VerifyMFA; amr
+// faktor pertama yang sudah lolos
+func (h *AuthHandler) challenge(c *fiber.Ctx, user *models.User, amr []string) error {
+    claims := models.MFAChallengeClaims{
         UserID: user.ID,
-        Role:   user.Role,
+        AMR:    amr,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+            // jti dicabut setelah dipakai sehingga tantangan hanya bisa diselesaikan sekali
+            ID:        uuid.NewString(),
+            Audience:  jwt.ClaimStrings{models.MFAChallengeAudience},
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(h.auth.MFAChallengeTTL)),
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    signedToken, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
-    
+    token, err := h.jwt.Keys.Sign(claims)
+    if err != nil {
+        return apperror.Wrap(err, "Failed to generate token")
+    }
+
+    return c.Status(fiber.StatusAccepted).JSON(models.MFAChallengeResponse{
+        MFARequired:    true,
+        ChallengeToken: token,
+        Expires:        claims.ExpiresAt.Time.Format(time.RFC3339),
+    })
+}
+
+// VerifyMFA godoc
+// @Summary      Complete two-factor login
+// @Description  Exchange the challenge token from /login or /auth/oidc/callback plus a current TOTP code (or one unused recovery code) for an access token and refresh token. Wrong codes count as failed logins for the account.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.MFAVerifyRequest  true  "Challenge token and code"
+// @Success      200  {object}  models.TokenResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /auth/2fa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *fiber.Ctx) error {
+    var req models.MFAVerifyRequest
+    if err := c.BodyParser(&req); err != nil {
+        return apperror.BadRequest("Invalid request body")
+    }
+    if err := validation.Validate(c, &req); err != nil {
+        return err
+    }
+    if req.Code == "" && req.RecoveryCode == "" {
+        return apperror.BadRequest("Either code or recovery_code is required")
+    }
+
+    claims, err := middleware.ParseChallenge(req.ChallengeToken, h.jwt.Keys)
+    if err != nil {
+        return apperror.Unauthorized("Invalid or expired challenge token")
+    }
+    used, err := h.tokens.IsRevoked(c.UserContext(), claims.ID)
+    if err != nil {
+        return apperror.Wrap(err, "Failed to verify challenge")
+    }
+    if used {
+        return apperror.Unauthorized("Invalid or expired challenge token")
+    }
+
+    user, err := h.users.GetByID(c.UserContext(), claims.UserID)
+    if err != nil {
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.Unauthorized("Invalid or expired challenge token")
+        }
+        return apperror.Wrap(err, "Failed to verify challenge")
+    }
+    if !user.Status {
+        return apperror.Forbidden("Account is inactive")
+    }
+
+    failures, err := h.beginLogin(c, user.Username)
+    if err != nil {
+        return err
+    }
+
+    ok, err := h.verifySecondFactor(c, user.ID, req)
+    if err != nil {
+        return apperror.Wrap(err, "Failed to verify code")
+    }
+    if !ok {
+        if err := h.recordLoginFailure(c, user.Username, &user.ID, failures); err != nil {
+            return err
+        }
+        return apperror.Unauthorized("Invalid two-factor code")
+    }
+
+    // Pemeriksaan di awal hanya mempercepat penolakan; request paralel dengan challenge yang sama
+    // (mis. kode TOTP dan kode pemulihan) sama-sama lolos, dan hanya yang pertama mencabutnya
+    first, err := h.tokens.Use(c.UserContext(), claims.ID, claims.ExpiresAt.Time)
+    if err != nil {
+        return apperror.Wrap(err, "Failed to verify challenge")
+    }
+    if !first {
+        return apperror.Unauthorized("Invalid or expired challenge token")
+    }
+    if err := h.attempts.Clear(c.UserContext(), user.Username); err != nil {
+        return apperror.Wrap(err, "Failed to login")
+    }
+
+    // Tantangan lama tanpa amr berasal dari login password
+    amr := claims.AMR
+    if len(amr) == 0 {
+        amr = []string{models.AMRPassword}
+    }
+    response, err := h.startSession(c, user, append(amr, models.AMROTP))
+    if err != nil {
+        return err
+    }
+    return c.JSON(response)
+}
+
+// verifySecondFactor memeriksa kode TOTP (sekali pakai per langkah waktu) atau kode pemulihan
+func (h *AuthHandler) verifySecondFactor(c *fiber.Ctx, userID int, req models.MFAVerifyRequest) (bool, error) {
+    if req.Code == "" {
+        return h.totp.UseRecoveryCode(c.UserContext(), userID, hashToken(normalizeRecoveryCode(req.RecoveryCode)))
+    }
+
+    enrollment, err := h.totp.Get(c.UserContext(), userID)
+    if err != nil {
+        if errors.Is(err, repository.ErrNotFound) {
+            return false, nil
+        }
+        return false, err
+    }
+    step, ok := totp.Verify(enrollment.Secret, req.Code, time.Now())
+    if !ok {
+        return false, nil
+    }
+    return h.totp.UseStep(c.UserContext(), userID, step)
+}
+
+// startSession membuat session baru dengan refresh token sendiri dan menyusun response login
+func (h *AuthHandler) startSession(c *fiber.Ctx, user *models.User, amr []string) (*models.TokenResponse, error) {
+    refreshToken, refreshHash, err := newOpaqueToken()
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to generate token",
-        })
+        return nil, apperror.Wrap(err, "Failed to generate token")
+    }
+    // Nilai header Fiber hanya berlaku selama request, disalin karena disimpan di session
+    session := models.Session{
+        UserID:    user.ID,
+        UserAgent: utils.CopyString(c.Get(fiber.HeaderUserAgent)),
+        IPAddress: utils.CopyString(c.IP()),
+        ExpiresAt: time.Now().Add(h.jwt.RefreshTTL),
+        AMR:       amr,
+    }
+    if err := h.sessions.Create(c.UserContext(), &session, refreshHash); err != nil {
+        return nil, apperror.Wrap(err, "Failed to create session")
     }
 
-    userResponse := models.UserLoginResponse{
+    response, err := h.tokenResponse(user, &session, refreshToken)
+    if err != nil {
+        return nil, err
+    }
+    response.User = &models.UserLoginResponse{
         ID:       user.ID,
         Username: user.Username,
-        Name:    user.Name,
+        Name:     user.Name,
         Role:     string(user.Role),
     }
+    return response, nil
+}
+
+// beginLogin mencatat percobaan login (password atau kode 2FA) sebelum kredensial diperiksa dan
+// menolaknya selama username terkunci atau jeda progresif belum lewat. Pemeriksaan dan
+// penghitungan atomik agar percobaan paralel tidak bisa melewati jeda maupun lockout.
+// Mengembalikan jumlah percobaan yang belum dihapus, termasuk percobaan ini.
+func (h *AuthHandler) beginLogin(c *fiber.Ctx, username string) (int, error) {
+    throttle := repository.LoginThrottle{
+        Since:    time.Now().Add(-h.auth.LoginLockout),
+        Delay:    h.auth.LoginDelay,
+        MaxDelay: maxLoginDelay,
+    }
+    failures, allowed, err := h.attempts.Attempt(c.UserContext(), username, throttle)
+    if err != nil {
+        return 0, apperror.Wrap(err, "Failed to login")
+    }
+    if allowed {
+        return failures.Failures, nil
+    }
 
-    return c.JSON(fiber.Map{
-        "token":   signedToken,
-        "expires": claims.ExpiresAt.Time.Format(time.RFC3339),
-        "user":    userResponse,
-    })
+    now := time.Now()
+    if failures.LockedUntil != nil && now.Before(*failures.LockedUntil) {
+        return 0, retryAfter(c, failures.LockedUntil.Sub(now), "Account temporarily locked due to too many failed login attempts")
+    }
+    wait := failures.LastFailureAt.Add(throttle.Wait(failures.Failures)).Sub(now)
+    return 0, retryAfter(c, max(wait, time.Second), "Too many failed login attempts, please wait before retrying")
+}
+
+// recordLoginFailure mengunci username bila percobaan gagal ini mencapai batas kegagalan.
+// Pemanggil tetap menjawab 401 agar penguncian tidak terbuka.
+func (h *AuthHandler) recordLoginFailure(c *fiber.Ctx, username string, userID *int, failures int) error {
+    if failures < h.auth.LoginMaxFailures {
+        return nil
+    }
+
+    lockedUntil := time.Now().Add(h.auth.LoginLockout)
+    event := models.LockoutEvent{
+        Username:    username,
+        UserID:      userID,
+        Event:       models.LockoutEventLocked,
+        Failures:    failures,
+        IPAddress:   c.IP(),
+        LockedUntil: &lockedUntil,
+    }
+    if err := h.attempts.Lock(c.UserContext(), &event); err != nil {
+        return apperror.Wrap(err, "Failed to login")
+    }
+    log.Printf("Locked login for %q until %s after %d failed attempts from %s", username, lockedUntil.Format(time.RFC3339), failures, c.IP())
+    return nil
+}
+
+// retryAfter response 429 dengan header Retry-After dalam detik (dibulatkan ke atas)
+func retryAfter(c *fiber.Ctx, wait time.Duration, message string) error {
+    seconds := int((wait + time.Second - 1) / time.Second)
+    c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+    return apperror.TooManyRequests(message)
+}
+
+// Refresh godoc
+// @Summary      Refresh access token
+// @Description  Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing a rotated token revokes the whole session.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.RefreshRequest  true  "Refresh token"
+// @Success      200  {object}  models.TokenResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+    var req models.RefreshRequest
+    if err := c.BodyParser(&req); err != nil {
+        return apperror.BadRequest("Invalid request body")
+    }
+    if err := validation.Validate(c, &req); err != nil {
+        return err
+    }
+
+    refreshToken, refreshHash, err := newOpaqueToken()
+    if err != nil {
+        return apperror.Wrap(err, "Failed to generate token")
+    }
+
+    session, err := h.sessions.Rotate(c.UserContext(), hashToken(req.RefreshToken), refreshHash, time.Now().Add(h.jwt.RefreshTTL))
+    if err != nil {
+        switch {
+        case errors.Is(err, repository.ErrTokenReused):
+            return apperror.Unauthorized("Refresh token already used, session revoked")
+        case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrSessionInactive):
+            return apperror.Unauthorized("Invalid or expired refresh token")
+        }
+        return apperror.Wrap(err, "Failed to refresh token")
+    }
+
+    // Role dibaca ulang agar perubahan role berlaku pada access token berikutnya
+    user, err := h.users.GetByID(c.UserContext(), session.UserID)
+    if err != nil && !errors.Is(err, repository.ErrNotFound) {
+        return apperror.Wrap(err, "Failed to refresh token")
+    }
+    // Session milik user yang sudah dihapus atau dinonaktifkan tidak boleh diperpanjang
+    if err != nil || !user.Status {
+        reason := models.SessionRevokedDeleted
+        if err == nil {
+            reason = models.SessionRevokedDeactivated
+        }
+        if err := h.sessions.Revoke(c.UserContext(), session.ID, reason); err != nil {
+            return apperror.Wrap(err, "Failed to revoke session")
+        }
+        return apperror.Unauthorized("Invalid or expired refresh token")
+    }
+
+    response, err := h.tokenResponse(user, session, refreshToken)
+    if err != nil {
+        return err
+    }
+    return c.JSON(response)
 }
 
+// tokenResponse menandatangani access token untuk session dan menyusun response token
+func (h *AuthHandler) tokenResponse(user *models.User, session *models.Session, refreshToken string) (*models.TokenResponse, error) {
+    claims := models.Claims{
+        UserID:    user.ID,
+        Role:      user.Role,
+        SessionID: session.ID,
+        AMR:       session.AMR,
+        RegisteredClaims: jwt.RegisteredClaims{
+            // jti dipakai untuk mencabut access token ini secara individual
+            ID:        uuid.NewString(),
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(h.jwt.TTL)),
+        },
+    }
+
+    // Token impersonasi tidak boleh hidup lebih lama dari session-nya (ImpersonationTTL)
+    if session.ImpersonatorID != nil {
+        claims.Act = &models.Actor{UserID: *session.ImpersonatorID}
+        if session.ExpiresAt.Before(claims.ExpiresAt.Time) {
+            claims.ExpiresAt = jwt.NewNumericDate(session.ExpiresAt)
+        }
+    }
+
+    signedToken, err := h.jwt.Keys.Sign(claims)
+    if err != nil {
+        return nil, apperror.Wrap(err, "Failed to generate token")
+    }
+
+    return &models.TokenResponse{
+        Token:          signedToken,
+        Expires:        claims.ExpiresAt.Time.Format(time.RFC3339),
+        RefreshToken:   refreshToken,
+        RefreshExpires: session.ExpiresAt.Format(time.RFC3339),
+    }, nil
+}
+
+// Logout godoc
+// @Summary      User logout
+// @Description  Revoke the current session so its access and refresh tokens stop working
+// @Tags         auth
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /logout [post]
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
     authHeader := c.Get("Authorization")
     tokenString := middleware.ExtractToken(authHeader)
     
     if tokenString == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "No token provided",
-        })
+        return apperror.Unauthorized("No token provided")
     }
 
     // Parse token untuk mendapatkan expiry time
-    claims := &models.Claims{}
-    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        return []byte(os.Getenv("JWT_SECRET")), nil
-    })
-    
-    if err != nil || !token.Valid {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid token",
-        })
-    }
-
-    // Masukkan token ke blacklist
-    _, err = h.db.Exec(context.Background(),
-        `INSERT INTO token_blacklist (token, expires_at)
-         VALUES ($1, $2)`,
-        tokenString,
-        claims.ExpiresAt.Time,
-    )
-    
+    claims, err := middleware.ParseToken(tokenString, h.jwt.Keys)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to logout",
-        })
+        return apperror.Unauthorized("Invalid token")
+    }
+
+    // Cabut jti access token dan session-nya sehingga refresh token session ini juga tidak berlaku lagi
+    if err := h.tokens.Revoke(c.UserContext(), claims.ID, claims.ExpiresAt.Time); err != nil {
+        return apperror.Wrap(err, "Failed to logout")
+    }
+    if claims.SessionID != 0 {
+        if err := h.sessions.Revoke(c.UserContext(), claims.SessionID, models.SessionRevokedLogout); err != nil {
+            return apperror.Wrap(err, "Failed to logout")
+        }
     }
 
     return c.JSON(fiber.Map{
         "message": "Successfully logged out",
     })
-}
\ No newline at end of file
+}
+
+// newOpaqueToken membuat token acak (refresh token, kode undangan) beserta hash yang disimpan di database
+func newOpaqueToken() (token, hash string, err error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", "", err
+    }
+    token = base64.RawURLEncoding.EncodeToString(b)
+    return token, hashToken(token), nil
+}
+
+// hashToken SHA-256 cukup untuk token acak 256-bit; bcrypt tidak diperlukan karena token tidak bisa ditebak
+func hashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
@@ -1,28 +1,27 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"fmt"
-	"log"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
 	"math"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type CarouselHandler struct {
-	db *pgxpool.Pool
+	carousels repository.CarouselRepository
+	uploads   uploadStore
 }
 
-func NewCarouselHandler(db *pgxpool.Pool) *CarouselHandler {
-    return &CarouselHandler{db: db}
+func NewCarouselHandler(carousels repository.CarouselRepository, uploads config.UploadConfig, tasks *background.Group) *CarouselHandler {
+    return &CarouselHandler{carousels: carousels, uploads: newUploadStore(uploads, tasks)}
 }
 
 // CreateCarousel godoc
@@ -37,8 +36,9 @@ func NewCarouselHandler(db *pgxpool.Pool) *CarouselHandler {
 // @Param        status      formData  bool    false "Carousel status"
 // @Security     ApiKeyAuth
 // @Success      201  {object}  models.Carousel
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /carousel [post]
 func (h * CarouselHandler) CreateCarousel(c * fiber.Ctx) error {
 	// Dapatkan user yang membuat
@@ -47,78 +47,48 @@ func (h * CarouselHandler) CreateCarousel(c * fiber.Ctx) error {
 	// Parse form data
 	file, err := c.FormFile("image")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Image is required",
-		})
+		return validation.Field(c, "image", "required", "")
 	}
 
 	var req models.CarouselCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
 	}
 
 	// Validasi input
-	if req.Title == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Title is required",
-		})
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
-	// Simpan gambar
-	uploadPath := "uploads/carousel/"
-	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create upload directory",
-		})
+	// Validasi tipe file
+	ext, err := imageExt(c, file)
+	if err != nil {
+		return err
 	}
 
-	ext := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("%d-%s%s", time.Now().UnixNano(), req.Title, ext)
-	filePath := filepath.Join(uploadPath, filename)
-
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save image",
-		})
+	// Simpan gambar
+	imagePath, err := h.uploads.save(c, file, "carousel", req.Title, ext)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to save image")
 	}
 
 	// Simpan data carousel ke database
-	query := `
-	INSERT INTO carousel (
-            image, 
-            title, 
-            description, 
-            status, 
-            created_by
-        ) VALUES ($1, $2, $3, $4, $5)
-        RETURNING id, created_at
-	`
-
-	var carousel models.Carousel
-	err = h.db.QueryRow(context.Background(), query,
-        "uploads/carousel/"+filename,
-        req.Title,
-        req.Description,
-        req.Status,
-        userID,
-    ).Scan(&carousel.ID, &carousel.CreatedAt)
+	carousel := models.Carousel{
+		Image:          imagePath,
+		Title:          req.Title,
+		Description:    req.Description,
+		Status:         req.Status,
+		CreatedBy:      &userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	}
+	err = h.carousels.Create(c.UserContext(), &carousel)
 
 	if err != nil {
         // Hapus file yang sudah diupload jika gagal insert
-        os.Remove(filePath)
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to create carousel",
-        })
+        h.uploads.remove(imagePath)
+        return apperror.Wrap(err, "Failed to create carousel")
     }
 
-	carousel.Image = "uploads/carousel/" + filename
-    carousel.Title = req.Title
-    carousel.Description = req.Description
-    carousel.Status = req.Status
-	carousel.CreatedBy = &userID
-
     return c.Status(fiber.StatusCreated).JSON(carousel)
 }
 
@@ -135,34 +105,30 @@ func (h * CarouselHandler) CreateCarousel(c * fiber.Ctx) error {
 // @Param        status      formData  bool    false "Carousel status"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.Carousel
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /carousel/{id} [put]
 func (h *CarouselHandler) UpdateCarousel(c *fiber.Ctx) error {
     carouselID := c.Params("id")
     id, err := strconv.Atoi(carouselID)
     if err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid carousel ID",
-        })
+        return apperror.BadRequest("Invalid carousel ID")
     }
 
     // Dapatkan user yang melakukan update
     userID := c.Locals("userID").(int)
     
     // Cek apakah carousel ada
-    var existingImage string
-    err = h.db.QueryRow(context.Background(),
-        "SELECT image FROM carousel WHERE id = $1 AND deleted_at IS NULL",
-        id,
-    ).Scan(&existingImage)
-    
+    existing, err := h.carousels.GetByID(c.UserContext(), id)
     if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-            "error": "Carousel not found",
-        })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("Carousel not found")
+        }
+        return apperror.From(err)
     }
+    existingImage := existing.Image
 
     // Parse form data secara manual
     title := c.FormValue("title")
@@ -174,9 +140,7 @@ func (h *CarouselHandler) UpdateCarousel(c *fiber.Ctx) error {
     if statusStr != "" {
         statusVal, err := strconv.ParseBool(statusStr)
         if err != nil {
-            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-                "error": "Invalid status value",
-            })
+            return apperror.BadRequest("Invalid status value")
         }
         status = &statusVal
     }
@@ -186,6 +150,9 @@ func (h *CarouselHandler) UpdateCarousel(c *fiber.Ctx) error {
         Description: description,
         Status:      status,
     }
+    if err := validation.Validate(c, &req); err != nil {
+        return err
+    }
 
     // Handle image upload
     file, _ := c.FormFile("image")
@@ -193,83 +160,39 @@ func (h *CarouselHandler) UpdateCarousel(c *fiber.Ctx) error {
     
     if file != nil {
         // Upload new image
-        uploadDir := "uploads/carousel/"
-        if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "Failed to create upload directory",
-            })
-        }
-        
-        ext := filepath.Ext(file.Filename)
-        filename := fmt.Sprintf("%d-%s%s", 
-            time.Now().UnixNano(), 
-            strings.ReplaceAll(req.Title, " ", "_"),
-            ext,
-        )
-        
-        // Jika title kosong
-        if req.Title == "" {
-            filename = fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
-        }
-        
-        filePath := filepath.Join(uploadDir, filename)
-        
-        if err := c.SaveFile(file, filePath); err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "Failed to save image",
-            })
+        ext, err := imageExt(c, file)
+        if err != nil {
+            return err
         }
-        newImagePath = "uploads/carousel/" + filename
-        
-        // Delete old image
-        go func(oldImage string) {
-            if oldImage != "" {
-                os.Remove("." + oldImage)
-            }
-        }(existingImage)
-    }
 
-    // Build dynamic query
-    query := `UPDATE carousel SET
-                image = COALESCE(NULLIF($1, ''), image),
-                title = COALESCE(NULLIF($2, ''), title),
-                description = COALESCE(NULLIF($3, ''), description),
-                status = COALESCE($4, status),
-                edited_by = $5
-              WHERE id = $6
-              RETURNING *`
-
-    args := []interface{}{
-        newImagePath,
-        req.Title,
-        req.Description,
-        req.Status,
-        userID,
-        id,
+        newImagePath, err = h.uploads.save(c, file, "carousel", req.Title, ext)
+        if err != nil {
+            return apperror.Wrap(err, "Failed to save image")
+        }
     }
 
-    var carousel models.Carousel
-    err = h.db.QueryRow(context.Background(), query, args...).Scan(
-        &carousel.ID,
-        &carousel.Image,
-        &carousel.Title,
-        &carousel.Description,
-        &carousel.Status,
-        &carousel.CreatedAt,
-        &carousel.CreatedBy,
-        &carousel.EditedAt,
-        &carousel.EditedBy,
-        &carousel.DeletedAt,
-        &carousel.DeletedBy,
-    )
+    carousel, err := h.carousels.Update(c.UserContext(), id, repository.CarouselUpdate{
+        Image:          newImagePath,
+        Title:          req.Title,
+        Description:    req.Description,
+        Status:         req.Status,
+        EditedBy:       userID,
+        ImpersonatedBy: middleware.Impersonator(c),
+    })
 
     if err != nil {
         if newImagePath != "" {
-            os.Remove("." + newImagePath)
+            h.uploads.remove(newImagePath)
+        }
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("Carousel not found")
         }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to update carousel",
-        })
+        return apperror.Wrap(err, "Failed to update carousel")
+    }
+
+    // Hapus gambar lama setelah update berhasil
+    if newImagePath != "" {
+        h.uploads.removeLater(existingImage)
     }
 
     return c.JSON(carousel)
@@ -284,79 +207,42 @@ func (h *CarouselHandler) UpdateCarousel(c *fiber.Ctx) error {
 // @Param        id   path      int  true  "Carousel ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      403  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /carousel/{id} [delete]
 func (h *CarouselHandler) DeleteCarousel(c *fiber.Ctx) error {
     carouselID := c.Params("id")
     id, err := strconv.Atoi(carouselID)
     if err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid carousel ID format",
-        })
+        return apperror.BadRequest("Invalid carousel ID format")
     }
 
     // Dapatkan admin yang melakukan delete
     adminID := c.Locals("userID").(int)
-    adminRole := c.Locals("userRole").(models.UserRole)
-
-    if adminRole != models.RoleAdmin {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-            "error": "Admin access required",
-        })
-    }
 
     // Dapatkan path gambar dan validasi keberadaan
-    var imagePath string
-    err = h.db.QueryRow(context.Background(),
-        `SELECT image FROM carousel 
-         WHERE id = $1 AND deleted_at IS NULL`,
-        id,
-    ).Scan(&imagePath)
-
+    carousel, err := h.carousels.GetByID(c.UserContext(), id)
     if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-            "error": "Carousel not found or already deleted",
-        })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("Carousel not found or already deleted")
+        }
+        return apperror.From(err)
     }
+    imagePath := carousel.Image
 
     // Soft delete di database
-    query := `
-        UPDATE carousel 
-        SET deleted_at = $1, deleted_by = $2 
-        WHERE id = $3
-    `
-    result, err := h.db.Exec(
-        context.Background(),
-        query,
-        time.Now().UTC(),
-        adminID,
-        id,
-    )
-
+    err = h.carousels.SoftDelete(c.UserContext(), id, adminID, middleware.Impersonator(c))
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to delete carousel",
-        })
-    }
-
-    if result.RowsAffected() == 0 {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-            "error": "Carousel not found",
-        })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("Carousel not found")
+        }
+        return apperror.Wrap(err, "Failed to delete carousel")
     }
 
     // Hapus file gambar
-    if imagePath != "" {
-        go func(path string) {
-            fullPath := path // karena path disimpan sebagai "/uploads/..."
-            if err := os.Remove(fullPath); err != nil {
-                log.Printf("Failed to delete image: %s. Error: %v", path, err)
-            }
-        }(imagePath)
-    }
+    h.uploads.removeLater(imagePath)
 
     return c.JSON(fiber.Map{
         "message": "Carousel deleted successfully",
@@ -372,8 +258,10 @@ func (h *CarouselHandler) DeleteCarousel(c *fiber.Ctx) error {
 // @Param        page    query     int     false  "Page number"     default(1)
 // @Param        limit   query     int     false  "Items per page"  default(10)
 // @Param        status  query     bool    false  "Filter by status"
+// @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      500  {object}  map[string]string
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /carousel [get]
 func (h *CarouselHandler) GetCarousels(c *fiber.Ctx) error {
     // Parse query parameters
@@ -390,73 +278,22 @@ func (h *CarouselHandler) GetCarousels(c *fiber.Ctx) error {
     }
     offset := (page - 1) * limit
 
-    // Build query
-    query := `SELECT 
-                id, image, title, description, status, created_at 
-              FROM carousel 
-              WHERE deleted_at IS NULL`
-    args := []interface{}{}
-    paramCounter := 1
+    filter := repository.CarouselFilter{
+        Page: repository.Page{Limit: limit, Offset: offset},
+    }
 
     // Filter status
     if status != "" {
         statusBool, err := strconv.ParseBool(status)
         if err == nil {
-            query += fmt.Sprintf(" AND status = $%d", paramCounter)
-            args = append(args, statusBool)
-            paramCounter++
+            filter.Status = &statusBool
         }
     }
 
-    // Add pagination
-    query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", paramCounter, paramCounter+1)
-    args = append(args, limit, offset)
-
     // Eksekusi query
-    rows, err := h.db.Query(context.Background(), query, args...)
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to fetch carousels",
-        })
-    }
-    defer rows.Close()
-
-    var carousels []models.CarouselResponse
-    for rows.Next() {
-        var carousel models.CarouselResponse
-        err := rows.Scan(
-            &carousel.ID,
-            &carousel.Image,
-            &carousel.Title,
-            &carousel.Description,
-            &carousel.Status,
-            &carousel.CreatedAt,
-        )
-        if err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "Failed to parse carousel data",
-            })
-        }
-        carousels = append(carousels, carousel)
-    }
-
-    // Get total count
-    countQuery := `SELECT COUNT(*) FROM carousel WHERE deleted_at IS NULL`
-    countArgs := []interface{}{}
-    paramCounter = 1
-
-    if status != "" {
-        statusBool, _ := strconv.ParseBool(status)
-        countQuery += fmt.Sprintf(" AND status = $%d", paramCounter)
-        countArgs = append(countArgs, statusBool)
-    }
-
-    var total int
-    err = h.db.QueryRow(context.Background(), countQuery, countArgs...).Scan(&total)
+    carousels, total, err := h.carousels.List(c.UserContext(), filter)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to get total carousels",
-        })
+        return apperror.Wrap(err, "Failed to fetch carousels")
     }
 
     return c.JSON(fiber.Map{
@@ -473,61 +310,46 @@ func (h *CarouselHandler) GetCarousels(c *fiber.Ctx) error {
 // GetCarouselByID godoc
 // @Summary      Get carousel by ID
 // @Description  Retrieve carousel details by carousel ID
-// @Tags         carousels
+// @Tags         carousel
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Carousel ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.CarouselResponse
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /carousel/{id} [get]
 func (h *CarouselHandler) GetCarouselByID(c *fiber.Ctx) error {
     // Parse ID dari parameter URL
     carouselID := c.Params("id")
     id, err := strconv.Atoi(carouselID)
     if err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid carousel ID format",
-        })
+        return apperror.BadRequest("Invalid carousel ID format")
     }
 
     // Query ke database
-    query := `
-        SELECT 
-            id,
-            image, 
-            title, 
-            description, 
-            status,
-            created_at,
-            created_by
-        FROM carousel 
-        WHERE id = $1 AND deleted_at IS NULL
-    `
-    
-    var carousel models.CarouselResponse
-    err = h.db.QueryRow(context.Background(), query, id).Scan(
-        &carousel.ID,
-        &carousel.Image,
-        &carousel.Title,
-        &carousel.Description,
-        &carousel.Status,
-        &carousel.CreatedAt,
-        &carousel.CreatedBy,
-    )
+    carousel, err := h.carousels.GetByID(c.UserContext(), id)
 
     if err != nil {
-        if err == pgx.ErrNoRows {
-            return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-                "error": "Carousel not found",
-            })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("Carousel not found")
         }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to fetch carousel: " + err.Error(),
-        })
+        return apperror.Wrap(err, "Failed to fetch carousel")
     }
 
-    return c.JSON(carousel)
+    response := models.CarouselResponse{
+        ID:          carousel.ID,
+        Image:       carousel.Image,
+        Title:       carousel.Title,
+        Description: carousel.Description,
+        Status:      carousel.Status,
+        CreatedAt:   carousel.CreatedAt,
+    }
+    if carousel.CreatedBy != nil {
+        response.CreatedBy = *carousel.CreatedBy
+    }
+
+    return c.JSON(response)
 }
\ No newline at end of file
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/version"
+	"context"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// readinessCheckTimeout batas waktu pengecekan dependency pada /readyz
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db           *pgxpool.Pool
+	uploadRoot   string
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(db *pgxpool.Pool, uploads config.UploadConfig) *HealthHandler {
+	return &HealthHandler{db: db, uploadRoot: uploads.Root}
+}
+
+// SetShuttingDown membuat readiness gagal agar load balancer berhenti mengirim traffic
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// HealthCheck status satu dependency
+type HealthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// PoolStats statistik pool koneksi database
+type PoolStats struct {
+	MaxConns      int32 `json:"max_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	AcquiredConns int32 `json:"acquired_conns"`
+	AcquireCount  int64 `json:"acquire_count"`
+}
+
+// ReadinessResponse body response /readyz
+type ReadinessResponse struct {
+	Status  string                 `json:"status"`
+	Checks  map[string]HealthCheck `json:"checks"`
+	Pool    PoolStats              `json:"pool"`
+	Version version.Info           `json:"version"`
+}
+
+// Liveness godoc
+// @Summary      Liveness probe
+// @Description  Report that the process is alive
+// @Tags         health
+// @BasePath     /
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status":  "ok",
+		"version": version.Get(),
+	})
+}
+
+// Readiness godoc
+// @Summary      Readiness probe
+// @Description  Check database and upload storage, report pool stats and build version
+// @Tags         health
+// @BasePath     /
+// @Produce      json
+// @Success      200  {object}  handlers.ReadinessResponse
+// @Failure      503  {object}  handlers.ReadinessResponse
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessCheckTimeout)
+	defer cancel()
+
+	resp := ReadinessResponse{
+		Status:  "ready",
+		Checks:  map[string]HealthCheck{},
+		Version: version.Get(),
+	}
+
+	if h.shuttingDown.Load() {
+		resp.Checks["shutdown"] = HealthCheck{Status: "error", Error: "server is shutting down"}
+	}
+
+	if err := h.db.Ping(ctx); err != nil {
+		log.Printf("Readiness: database ping failed: %v", err)
+		resp.Checks["database"] = HealthCheck{Status: "error", Error: "database unreachable"}
+	} else {
+		resp.Checks["database"] = HealthCheck{Status: "ok"}
+	}
+
+	if err := h.checkUploadsWritable(); err != nil {
+		log.Printf("Readiness: upload directory not writable: %v", err)
+		resp.Checks["uploads"] = HealthCheck{Status: "error", Error: "upload directory not writable"}
+	} else {
+		resp.Checks["uploads"] = HealthCheck{Status: "ok"}
+	}
+
+	stat := h.db.Stat()
+	resp.Pool = PoolStats{
+		MaxConns:      stat.MaxConns(),
+		TotalConns:    stat.TotalConns(),
+		IdleConns:     stat.IdleConns(),
+		AcquiredConns: stat.AcquiredConns(),
+		AcquireCount:  stat.AcquireCount(),
+	}
+
+	for _, check := range resp.Checks {
+		if check.Status != "ok" {
+			resp.Status = "not_ready"
+			return c.Status(fiber.StatusServiceUnavailable).JSON(resp)
+		}
+	}
+
+	return c.JSON(resp)
+}
+
+// checkUploadsWritable membuat lalu menghapus file sementara di root upload
+func (h *HealthHandler) checkUploadsWritable() error {
+	if err := os.MkdirAll(h.uploadRoot, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(h.uploadRoot, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type ImpersonationHandler struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	audit    repository.AuditRepository
+	tokens   *AuthHandler
+	policy   *authz.Policy
+	auth     config.AuthConfig
+}
+
+// NewImpersonationHandler memakai AuthHandler untuk menandatangani token impersonasi
+func NewImpersonationHandler(users repository.UserRepository, sessions repository.SessionRepository, audit repository.AuditRepository, tokens *AuthHandler, policy *authz.Policy, authConfig config.AuthConfig) *ImpersonationHandler {
+	return &ImpersonationHandler{users: users, sessions: sessions, audit: audit, tokens: tokens, policy: policy, auth: authConfig}
+}
+
+// Impersonate godoc
+// @Summary      Impersonate user
+// @Description  Issue a short-lived access token that acts as another user, to see what they see. The token carries the caller's user ID in the act claim and cannot be refreshed. Every request made with it returns an X-Impersonated-By header and is recorded in the audit log under both users. The session shows up in the user's session list and ends on logout, on expiry, or when the user's sessions are revoked. The caller must hold every permission of the user's role, and inactive users cannot be impersonated. Changing passwords, two-factor settings and API keys is refused while impersonating.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                         true  "User ID"
+// @Param        request  body      models.ImpersonateRequest  true  "Reason, recorded in the audit log"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.ImpersonationResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /users/{id}/impersonate [post]
+func (h *ImpersonationHandler) Impersonate(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid user ID format")
+	}
+
+	var req models.ImpersonateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	actorID := c.Locals("userID").(int)
+	if id == actorID {
+		return apperror.BadRequest("You cannot impersonate yourself")
+	}
+
+	user, err := h.users.GetByID(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User not found")
+		}
+		return apperror.Wrap(err, "Failed to fetch user")
+	}
+	// Memerankan akun yang lebih berkuasa sama dengan memakai hak yang tidak dimiliki pemanggil
+	if err := requireRolePermissions(c, h.policy, user.Role); err != nil {
+		return err
+	}
+	if !user.Status {
+		return apperror.BadRequest("Inactive users cannot be impersonated")
+	}
+
+	// Session hanya dapat dipakai lewat access token: refresh token-nya langsung dibuang
+	// sehingga impersonasi tidak bisa diperpanjang melewati ImpersonationTTL
+	_, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate token")
+	}
+	amr, _ := c.Locals("amr").([]string)
+	session := models.Session{
+		UserID:         user.ID,
+		UserAgent:      utils.CopyString(c.Get(fiber.HeaderUserAgent)),
+		IPAddress:      utils.CopyString(c.IP()),
+		ExpiresAt:      time.Now().Add(h.auth.ImpersonationTTL),
+		AMR:            amr,
+		ImpersonatorID: &actorID,
+	}
+	if err := h.sessions.Create(c.UserContext(), &session, refreshHash); err != nil {
+		return apperror.Wrap(err, "Failed to create session")
+	}
+
+	requestID, _ := c.Locals(apperror.RequestIDKey).(string)
+	if err := h.audit.Create(c.UserContext(), &models.AuditEntry{
+		Action:    models.AuditImpersonationStarted,
+		ActorID:   actorID,
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Method:    utils.CopyString(c.Method()),
+		Path:      utils.CopyString(c.Path()),
+		Status:    fiber.StatusOK,
+		RequestID: requestID,
+		IPAddress: session.IPAddress,
+		Detail:    req.Reason,
+	}); err != nil {
+		// Impersonasi tanpa jejak audit tidak boleh dipakai
+		if revokeErr := h.sessions.Revoke(c.UserContext(), session.ID, models.SessionRevokedLogout); revokeErr != nil {
+			return apperror.Wrap(errors.Join(err, revokeErr), "Failed to record impersonation")
+		}
+		return apperror.Wrap(err, "Failed to record impersonation")
+	}
+
+	token, err := h.tokens.tokenResponse(user, &session, "")
+	if err != nil {
+		return err
+	}
+	return c.JSON(models.ImpersonationResponse{
+		Token:          token.Token,
+		Expires:        token.Expires,
+		SessionID:      session.ID,
+		ImpersonatorID: actorID,
+		User: models.UserLoginResponse{
+			ID:       user.ID,
+			Username: user.Username,
+			Name:     user.Name,
+			Role:     string(user.Role),
+		},
+	})
+}
+
+// GetAuditLog godoc
+// @Summary      Get audit log
+// @Description  List actions taken by administrators on behalf of other users, newest first: each impersonation with its reason, and every request made with an impersonation token
+// @Tags         users
+// @Produce      json
+// @Param        actor_id  query     int  false  "Filter by the administrator who acted"
+// @Param        user_id   query     int  false  "Filter by the impersonated user"
+// @Param        page      query     int  false  "Page number"     default(1)
+// @Param        limit     query     int  false  "Items per page"  default(10)
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /audit-log [get]
+func (h *ImpersonationHandler) GetAuditLog(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	actorID, _ := strconv.Atoi(c.Query("actor_id"))
+	userID, _ := strconv.Atoi(c.Query("user_id"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	entries, total, err := h.audit.List(c.UserContext(), repository.AuditFilter{
+		ActorID: actorID,
+		UserID:  userID,
+		Page:    repository.Page{Limit: limit, Offset: offset},
+	})
+	if err != nil {
+		return apperror.Wrap(err, "Failed to fetch audit log")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": entries,
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InviteHandler struct {
+	invites repository.InviteRepository
+	policy  *authz.Policy
+	auth    config.AuthConfig
+}
+
+func NewInviteHandler(invites repository.InviteRepository, policy *authz.Policy, authConfig config.AuthConfig) *InviteHandler {
+	return &InviteHandler{invites: invites, policy: policy, auth: authConfig}
+}
+
+// CreateInvite godoc
+// @Summary      Create invite
+// @Description  Issue a single-use, expiring invite code bound to a role. The code is only returned once and is redeemed through /register. The caller must hold every permission of the invited role.
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateInviteRequest  true  "Invite data"
+// @Security     ApiKeyAuth
+// @Success      201  {object}  models.InviteCreatedResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /invites [post]
+func (h *InviteHandler) CreateInvite(c *fiber.Ctx) error {
+	var req models.CreateInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	// Akun dari undangan tidak boleh lebih berkuasa dari pembuatnya
+	if err := requireRolePermissions(c, h.policy, req.Role); err != nil {
+		return err
+	}
+
+	ttl := h.auth.InviteTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	code, codeHash, err := newOpaqueToken()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate invite code")
+	}
+
+	invite := models.Invite{
+		Role:      req.Role,
+		CreatedBy: c.Locals("userID").(int),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := h.invites.Create(c.UserContext(), &invite, codeHash); err != nil {
+		return apperror.Wrap(err, "Failed to create invite")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.InviteCreatedResponse{
+		Invite: invite,
+		Code:   code,
+	})
+}
+
+// GetInvites godoc
+// @Summary      Get all invites
+// @Description  List issued invites, newest first. Invite codes are never returned.
+// @Tags         invites
+// @Produce      json
+// @Param        page   query     int  false  "Page number"     default(1)
+// @Param        limit  query     int  false  "Items per page"  default(10)
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /invites [get]
+func (h *InviteHandler) GetInvites(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	invites, total, err := h.invites.List(c.UserContext(), repository.Page{Limit: limit, Offset: offset})
+	if err != nil {
+		return apperror.Wrap(err, "Failed to fetch invites")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": invites,
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// RevokeInvite godoc
+// @Summary      Revoke invite
+// @Description  Cancel an unused invite so its code can no longer be redeemed
+// @Tags         invites
+// @Param        id   path      int  true  "Invite ID"
+// @Security     ApiKeyAuth
+// @Success      204  "No Content"
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /invites/{id} [delete]
+func (h *InviteHandler) RevokeInvite(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid invite ID format")
+	}
+
+	if err := h.invites.Revoke(c.UserContext(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Invite not found, already used, or already revoked")
+		}
+		return apperror.Wrap(err, "Failed to revoke invite")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
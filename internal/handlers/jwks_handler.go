@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"backend-go/internal/jwtkeys"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	keys *jwtkeys.Set
+}
+
+func NewJWKSHandler(keys *jwtkeys.Set) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys for verifying access tokens, selected by the kid header. During key rotation both the current and the previous key are listed. Access tokens carry no aud claim; reject tokens that have one. Empty when tokens are signed with a shared HS256 secret.
+// @Tags         auth
+// @BasePath     /
+// @Produce      json
+// @Success      200  {object}  jwtkeys.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	// Verifier boleh menyimpan sebentar; kunci baru sebaiknya dipasang sebagai kunci verifikasi
+	// lebih dulu, lebih lama dari max-age ini, sebelum dipakai menandatangani
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.keys.JWKS())
+}
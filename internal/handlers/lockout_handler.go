@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LockoutHandler struct {
+	users    repository.UserRepository
+	attempts repository.LoginAttemptRepository
+	policy   *authz.Policy
+}
+
+func NewLockoutHandler(users repository.UserRepository, attempts repository.LoginAttemptRepository, policy *authz.Policy) *LockoutHandler {
+	return &LockoutHandler{users: users, attempts: attempts, policy: policy}
+}
+
+// UnlockUser godoc
+// @Summary      Unlock user
+// @Description  Lift a login lockout and reset the failed-attempt counter for a user. Unlocking a user that is not locked only resets the counter. The caller must hold every permission of the user's role.
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /users/{id}/unlock [post]
+func (h *LockoutHandler) UnlockUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid user ID format")
+	}
+
+	user, err := h.users.GetByID(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User not found")
+		}
+		return apperror.Wrap(err, "Failed to fetch user")
+	}
+	// Membuka lockout akun yang lebih berkuasa membantu menebak password-nya
+	if err := requireRolePermissions(c, h.policy, user.Role); err != nil {
+		return err
+	}
+
+	actorID := c.Locals("userID").(int)
+	event := models.LockoutEvent{
+		Username:  user.Username,
+		UserID:    &user.ID,
+		Event:     models.LockoutEventUnlocked,
+		IPAddress: c.IP(),
+		ActorID:   &actorID,
+	}
+	wasLocked, err := h.attempts.Unlock(c.UserContext(), &event)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to unlock user")
+	}
+
+	message := "User was not locked, failed attempts reset"
+	if wasLocked {
+		message = "User unlocked"
+	}
+	return c.JSON(fiber.Map{
+		"message":  message,
+		"unlocked": wasLocked,
+	})
+}
+
+// GetLockoutEvents godoc
+// @Summary      Get lockout events
+// @Description  List account lockouts and unlocks, newest first
+// @Tags         users
+// @Produce      json
+// @Param        username  query     string  false  "Filter by username"
+// @Param        page      query     int     false  "Page number"     default(1)
+// @Param        limit     query     int     false  "Items per page"  default(10)
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /lockout-events [get]
+func (h *LockoutHandler) GetLockoutEvents(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	events, total, err := h.attempts.ListEvents(c.UserContext(), repository.LockoutEventFilter{
+		Username: c.Query("username"),
+		Page:     repository.Page{Limit: limit, Offset: offset},
+	})
+	if err != nil {
+		return apperror.Wrap(err, "Failed to fetch lockout events")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": events,
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
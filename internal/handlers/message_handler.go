@@ -1,26 +1,27 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"fmt"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
 	"math"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // MessageHandler handles message-related operations
 type MessageHandler struct {
-	db *pgxpool.Pool
+	messages repository.MessageRepository
+	products repository.ProductRepository
 }
 
-func NewMessagesHandler(db *pgxpool.Pool) *MessageHandler {
-	return &MessageHandler{db: db}
+func NewMessagesHandler(messages repository.MessageRepository, products repository.ProductRepository) *MessageHandler {
+	return &MessageHandler{messages: messages, products: products}
 }
 
 // CreateMessage godoc
@@ -33,10 +34,12 @@ func NewMessagesHandler(db *pgxpool.Pool) *MessageHandler {
 // @Param        product_id  formData  int     false "Related product ID"
 // @Param        address     formData  string  false "Physical address"
 // @Param        description formData  string  true  "Message content"
+// @Param        phone       formData  string  true  "Phone number in E.164 format"
 // @Security     ApiKeyAuth
 // @Success      201  {object}  models.Message
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /messages [post]
 func (h *MessageHandler) CreateMessage(c *fiber.Ctx) error {
 	// Dapatkan user yang membuat
@@ -45,108 +48,46 @@ func (h *MessageHandler) CreateMessage(c *fiber.Ctx) error {
 	// Parse form data
 	var req models.MessageCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
 	}
 
-	// Validasi manual
-	var validationErrors []string
-
-	// Validasi name
+	// Normalisasi input sebelum validasi
 	req.Name = strings.TrimSpace(req.Name)
-	if req.Name == "" {
-		validationErrors = append(validationErrors, "name is required")
-	} else if len(req.Name) > 100 {
-		validationErrors = append(validationErrors, "name max length is 100 characters")
-	}
-
-	// Validasi phone
 	req.Phone = strings.TrimSpace(req.Phone)
-	if req.Phone == "" {
-		validationErrors = append(validationErrors, "phone is required")
-	} else if !isValidPhone(req.Phone) {
-		validationErrors = append(validationErrors, "invalid phone number format")
-	}
-
-	// Validasi company
 	req.Company = strings.TrimSpace(req.Company)
-	if len(req.Company) > 100 {
-		validationErrors = append(validationErrors, "company max length is 100 characters")
-	}
-
-	// Validasi description
 	req.Description = strings.TrimSpace(req.Description)
-	if req.Description == "" {
-		validationErrors = append(validationErrors, "description is required")
-	}
 
-	if len(validationErrors) > 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Validation failed",
-			"details": validationErrors,
-		})
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
 	// Validasi product_id jika ada
 	if req.ProductID != nil {
-		var exists bool
-		err := h.db.QueryRow(
-			context.Background(),
-			"SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)",
-			*req.ProductID,
-		).Scan(&exists)
+		exists, err := h.products.Exists(c.UserContext(), *req.ProductID)
 
 		if err != nil || !exists {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid product ID",
-			})
+			return apperror.BadRequest("Invalid product ID")
 		}
 	}
 
 	// Insert ke database
-	query := `
-        INSERT INTO messages_user (
-            name,
-            company,
-            id_product,
-            address,
-            description,
-            date_schedule,
-            phone,
-            created_by
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-        RETURNING id, created_at, date_schedule
-    `
-
-	var message models.Message
-	err := h.db.QueryRow(context.Background(), query,
-		req.Name,
-		req.Company,
-		req.ProductID,
-		req.Address,
-		req.Description,
-		req.DateSchedule,
-		req.Phone,
-		userID,
-	).Scan(&message.ID, &message.CreatedAt, &message.DateSchedule)
+	message := models.Message{
+		Name:           req.Name,
+		Company:        req.Company,
+		ProductID:      req.ProductID,
+		Address:        req.Address,
+		Description:    req.Description,
+		DateSchedule:   req.DateSchedule,
+		Phone:          req.Phone,
+		CreatedBy:      userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	}
+	err := h.messages.Create(c.UserContext(), &message)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create message: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to create message")
 	}
 
-	// Isi response
-	message.Name = req.Name
-	message.Company = req.Company
-	message.ProductID = req.ProductID
-	message.Address = req.Address
-	message.Description = req.Description
-	message.DateSchedule = req.DateSchedule
-	message.Phone = req.Phone
-	message.CreatedBy = userID
-
 	return c.Status(fiber.StatusCreated).JSON(message)
 }
 
@@ -162,11 +103,13 @@ func (h *MessageHandler) CreateMessage(c *fiber.Ctx) error {
 // @Param        product_id   formData  int     false "Related product ID"
 // @Param        address      formData  string  false "Physical address"
 // @Param        description  formData  string  false "Message content"
+// @Param        phone        formData  string  false "Phone number in E.164 format"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.Message
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /messages/{id} [put]
 func (h *MessageHandler) UpdateMessage(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int)
@@ -175,133 +118,51 @@ func (h *MessageHandler) UpdateMessage(c *fiber.Ctx) error {
 	messageID := c.Params("id")
 	id, err := strconv.Atoi(messageID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid message ID format",
-		})
+		return apperror.BadRequest("Invalid message ID format")
 	}
 
 	// Parse form data
 	var req models.MessageUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
 	}
 
-	// Validasi manual
-	var validationErrors []string
-
-	if req.Name != nil {
-		*req.Name = strings.TrimSpace(*req.Name)
-		if *req.Name == "" {
-			validationErrors = append(validationErrors, "name cannot be empty")
-		} else if len(*req.Name) > 100 {
-			validationErrors = append(validationErrors, "name max length is 100 characters")
-		}
-	}
-
-	if req.Phone != "" {
-		req.Phone = strings.TrimSpace(req.Phone)
-		if !isValidPhone(req.Phone) {
-			validationErrors = append(validationErrors, "invalid phone number format")
-		}
-	}
-
-	if req.Company != nil {
-		*req.Company = strings.TrimSpace(*req.Company)
-		if len(*req.Company) > 100 {
-			validationErrors = append(validationErrors, "company max length is 100 characters")
-		}
-	}
-
-	if req.Description != nil {
-		*req.Description = strings.TrimSpace(*req.Description)
-		if *req.Description == "" {
-			validationErrors = append(validationErrors, "description cannot be empty")
-		}
-	}
+	// Normalisasi input sebelum validasi
+	trimPtr(req.Name)
+	trimPtr(req.Company)
+	trimPtr(req.Description)
+	req.Phone = strings.TrimSpace(req.Phone)
 
-	if len(validationErrors) > 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Validation failed",
-			"details": validationErrors,
-		})
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
 	// Validasi product_id jika ada
 	if req.ProductID != nil {
-		var exists bool
-		err := h.db.QueryRow(
-			context.Background(),
-			"SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)",
-			*req.ProductID,
-		).Scan(&exists)
+		exists, err := h.products.Exists(c.UserContext(), *req.ProductID)
 
 		if err != nil || !exists {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid product ID",
-			})
+			return apperror.BadRequest("Invalid product ID")
 		}
 	}
 
-	// Build dynamic query
-	query := `
-        UPDATE messages_user SET
-            name = COALESCE(NULLIF($1, ''), name),
-            company = COALESCE(NULLIF($2, ''), company),
-            id_product = COALESCE($3, id_product),
-            address = COALESCE(NULLIF($4, ''), address),
-            description = COALESCE(NULLIF($5, ''), description),
-            date_schedule = COALESCE($6, date_schedule),
-            phone = COALESCE(NULLIF($7, ''), phone),
-            edited_by = $6
-        WHERE id = $7
-        RETURNING *
-    `
-
-	args := []interface{}{
-		req.Name,
-		req.Company,
-		req.ProductID,
-		req.Address,
-		req.Description,
-		req.DateSchedule,
-		req.Phone,
-		userID,
-		id,
-	}
-
-	var message models.Message
-	err = h.db.QueryRow(
-		context.Background(),
-		query,
-		args...,
-	).Scan(
-		&message.ID,
-		&message.Name,
-		&message.Company,
-		&message.ProductID,
-		&message.Address,
-		&message.Description,
-		&message.DateSchedule,
-		&message.Phone,
-		&message.CreatedAt,
-		&message.CreatedBy,
-		&message.EditedAt,
-		&message.EditedBy,
-		&message.DeletedAt,
-		&message.DeletedBy,
-	)
+	message, err := h.messages.Update(c.UserContext(), id, repository.MessageUpdate{
+		Name:           req.Name,
+		Company:        req.Company,
+		ProductID:      req.ProductID,
+		Address:        req.Address,
+		Description:    req.Description,
+		DateSchedule:   req.DateSchedule,
+		Phone:          req.Phone,
+		EditedBy:       userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	})
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Message not found",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Message not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update message: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to update message")
 	}
 
 	return c.JSON(message)
@@ -316,9 +177,10 @@ func (h *MessageHandler) UpdateMessage(c *fiber.Ctx) error {
 // @Param        id   path      int  true  "Message ID"
 // @Security     ApiKeyAuth
 // @Success      204  "No Content"
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /messages/{id} [delete]
 func (h *MessageHandler) DeleteMessage(c *fiber.Ctx) error {
 	// Dapatkan user yang melakukan delete
@@ -328,45 +190,17 @@ func (h *MessageHandler) DeleteMessage(c *fiber.Ctx) error {
 	messageID := c.Params("id")
 	id, err := strconv.Atoi(messageID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid message ID format",
-		})
+		return apperror.BadRequest("Invalid message ID format")
 	}
 
 	// Lakukan soft delete
-	query := `
-        UPDATE messages_user 
-        SET 
-            deleted_at = $1,
-            deleted_by = $2
-        WHERE 
-            id = $3 
-            AND deleted_at IS NULL
-        RETURNING id, deleted_at
-    `
-
-	var (
-		deletedID int
-		deletedAt time.Time
-	)
-
-	err = h.db.QueryRow(
-		c.Context(),
-		query,
-		time.Now(),
-		userID,
-		id,
-	).Scan(&deletedID, &deletedAt)
+	err = h.messages.SoftDelete(c.UserContext(), id, userID, middleware.Impersonator(c))
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Message not found or already deleted",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Message not found or already deleted")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete message: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to delete message")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -380,8 +214,10 @@ func (h *MessageHandler) DeleteMessage(c *fiber.Ctx) error {
 // @Param        page      query   int     false  "Page number"
 // @Param        limit     query   int     false  "Items per page"
 // @Param        product_id query  int     false  "Filter by product ID"
-// @Success      200  {array}  models.MessageWithProduct
-// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /messages [get]
 func (h *MessageHandler) GetMessages(c *fiber.Ctx) error {
 	// Parse query parameters
@@ -389,93 +225,27 @@ func (h *MessageHandler) GetMessages(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	productID, _ := strconv.Atoi(c.Query("product_id"))
 
-	offset := (page - 1) * limit
-
-	// Build query
-	query := `
-        SELECT 
-            m.id,
-            m.name,
-            m.company,
-            m.id_product,
-            m.address,
-            m.description,
-            m.date_schedule,
-            m.phone,
-            m.created_at,
-            m.created_by,
-            m.edited_at,
-            p.title as product_name,
-            p.image as product_image
-        FROM messages_user m
-        LEFT JOIN products p ON m.id_product = p.id
-        WHERE m.deleted_at IS NULL
-    `
-
-	args := []interface{}{}
-	argCounter := 1
-
-	if productID > 0 {
-		query += fmt.Sprintf(" AND m.id_product = $%d", argCounter)
-		args = append(args, productID)
-		argCounter++
+	// Validasi input
+	if page < 1 {
+		page = 1
 	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
 
-	query += " ORDER BY m.created_at DESC"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
-	args = append(args, limit, offset)
-
-	rows, err := h.db.Query(c.Context(), query, args...)
+	messages, total, err := h.messages.List(c.UserContext(), repository.MessageFilter{
+		ProductID: productID,
+		Page:      repository.Page{Limit: limit, Offset: offset},
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch messages: " + err.Error(),
-		})
-	}
-	defer rows.Close()
-
-	var messages []models.MessageWithProduct
-	for rows.Next() {
-		var msg models.MessageWithProduct
-		err := rows.Scan(
-			&msg.ID,
-			&msg.Name,
-			&msg.Company,
-			&msg.ProductID,
-			&msg.Address,
-			&msg.Description,
-			&msg.DateSchedule,
-			&msg.Phone,
-			&msg.CreatedAt,
-			&msg.CreatedBy,
-			&msg.EditedAt,
-			&msg.ProductName,
-			&msg.ProductImage,
-		)
-
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to parse messages: " + err.Error(),
-			})
-		}
-		messages = append(messages, msg)
+		return apperror.Wrap(err, "Failed to fetch messages")
 	}
 
-	if messages == nil {
+	if len(messages) == 0 {
 		return c.JSON([]interface{}{})
 	}
 
-	var total int
-	err = h.db.QueryRow(
-		context.Background(),
-		"SELECT COUNT(*) FROM messages_user WHERE deleted_at IS NULL",
-	).Scan(&total)
-
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get total data",
-		})
-	}
-
 	return c.JSON(fiber.Map{
 		"data": messages,
 		"meta": fiber.Map{
@@ -490,69 +260,39 @@ func (h *MessageHandler) GetMessages(c *fiber.Ctx) error {
 // GetMessageByID godoc
 // @Summary      Get message by ID
 // @Description  Retrieve a single message with product details
-// @Tags         message
+// @Tags         messages
 // @Produce      json
 // @Param        id   path      int  true  "Message ID"
-// @Success      200  {object}  models.MessageDetail
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.MessageWithProduct
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /messages/{id} [get]
 func (h *MessageHandler) GetMessageByID(c *fiber.Ctx) error {
 	// Parse ID dari parameter URL
 	reviewID := c.Params("id")
 	id, err := strconv.Atoi(reviewID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid review ID format",
-		})
+		return apperror.BadRequest("Invalid review ID format")
 	}
 
-	query := `
-        SELECT 
-            ms.id,
-            ms.id_product,
-            ms.name,
-            ms.address,
-            ms.date_schedule,
-            ms.phone,
-            ms.description,
-            ms.created_at,
-            ms.created_by,
-            ms.edited_at,
-            p.title as product_name,
-            p.image as product_image
-        FROM messages_user ms
-        LEFT JOIN products p ON ms.id_product = p.id
-        WHERE ms.id = $1 AND ms.deleted_at IS NULL
-    `
-
-	var review models.MessageWithProduct
-	err = h.db.QueryRow(context.Background(), query, id).Scan(
-		&review.ID,
-		&review.ProductID,
-		&review.Name,
-		&review.Address,
-		&review.DateSchedule,
-		&review.Phone,
-		&review.Description,
-		&review.CreatedAt,
-		&review.CreatedBy,
-		&review.EditedAt,
-		&review.ProductName,
-		&review.ProductImage,
-	)
+	message, err := h.messages.GetByID(c.UserContext(), id)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Message not found",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Message not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch Message: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to fetch Message")
 	}
 
-	return c.JSON(review)
+	return c.JSON(message)
+}
+
+// trimPtr menghapus spasi di awal dan akhir string yang dikirim client
+func trimPtr(s *string) {
+	if s != nil {
+		*s = strings.TrimSpace(*s)
+	}
 }
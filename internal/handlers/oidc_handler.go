@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/oidc"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// roleRank urutan role dari yang terendah, untuk memilih role tertinggi dari beberapa grup
+var roleRank = []models.UserRole{models.RoleUser, models.RoleStaff, models.RoleAdmin}
+
+// providerMFA nilai claim amr provider (RFC 8176) yang dianggap setara dengan 2FA lokal
+var providerMFA = []string{"mfa", "otp", "hwk"}
+
+// e164 format nomor telepon yang diterima dari claim phone_number
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// Batas kolom users untuk data yang berasal dari provider
+const (
+	maxUsernameLength = 50
+	maxNameLength     = 100
+)
+
+type OIDCHandler struct {
+	provider *oidc.Provider
+	oidc     repository.OIDCRepository
+	users    repository.UserRepository
+	auth     *AuthHandler
+	cfg      config.OIDCConfig
+}
+
+// NewOIDCHandler memakai AuthHandler untuk menerbitkan token setelah login SSO berhasil.
+// Tanpa OIDC_ISSUER endpoint SSO menjawab 404.
+func NewOIDCHandler(oidcRepo repository.OIDCRepository, users repository.UserRepository, auth *AuthHandler, oidcConfig config.OIDCConfig) *OIDCHandler {
+	h := &OIDCHandler{oidc: oidcRepo, users: users, auth: auth, cfg: oidcConfig}
+	if oidcConfig.Issuer != "" {
+		h.provider = oidc.New(oidc.Config{
+			Issuer:       oidcConfig.Issuer,
+			ClientID:     oidcConfig.ClientID,
+			ClientSecret: oidcConfig.ClientSecret,
+			RedirectURL:  oidcConfig.RedirectURL,
+			Scopes:       oidcConfig.Scopes,
+		}, nil)
+	}
+	return h
+}
+
+// Authorize godoc
+// @Summary      Start single sign-on
+// @Description  Start an OpenID Connect login (authorization code flow with PKCE). Send the browser to authorization_url. The identity provider redirects back to the configured redirect URL with code and state, which must be posted to /auth/oidc/callback before expires_at. Returns 404 when single sign-on is not configured.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  models.OIDCAuthorizeResponse
+// @Failure      404  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /auth/oidc/authorize [post]
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	if h.provider == nil {
+		return apperror.NotFound("Single sign-on is not configured")
+	}
+
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate state")
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate state")
+	}
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate state")
+	}
+
+	authURL, err := h.provider.AuthCodeURL(c.UserContext(), state, nonce, verifier)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to reach identity provider")
+	}
+
+	expiresAt := time.Now().Add(h.cfg.StateTTL)
+	if err := h.oidc.CreateState(c.UserContext(), stateHash, models.OIDCState{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    expiresAt,
+	}); err != nil {
+		return apperror.Wrap(err, "Failed to start single sign-on")
+	}
+
+	return c.JSON(models.OIDCAuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        expiresAt,
+	})
+}
+
+// Callback godoc
+// @Summary      Complete single sign-on
+// @Description  Exchange the code and state from the identity provider redirect for this service's access and refresh tokens. Each state can be used once. On first login a user is created from the ID token claims, or linked to the local user with the same username when OIDC_LINK_EXISTING is enabled, the username claim matches exactly and the provider marks it verified (<claim>_verified). Administrators are never linked automatically. The role comes from the provider groups (OIDC_ROLE_MAPPING); users without a mapped group get OIDC_DEFAULT_ROLE or are refused with 403. Deactivated accounts are refused with 403. Users with two-factor authentication enabled receive 202 with a challenge token, to be completed at /auth/2fa/verify, unless the provider reports multi-factor authentication in the amr claim.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        callback  body      models.OIDCCallbackRequest  true  "Code and state from the provider redirect"
+// @Success      200  {object}  models.TokenResponse
+// @Success      202  {object}  models.MFAChallengeResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /auth/oidc/callback [post]
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	if h.provider == nil {
+		return apperror.NotFound("Single sign-on is not configured")
+	}
+
+	var req models.OIDCCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	state, err := h.oidc.ConsumeState(c.UserContext(), hashToken(req.State))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.BadRequest("Invalid or expired single sign-on state")
+		}
+		return apperror.Wrap(err, "Failed to complete single sign-on")
+	}
+
+	// Detail kegagalan dari provider hanya dicatat di log
+	claims, err := h.provider.Exchange(c.UserContext(), req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("Single sign-on failed: %v", err)
+		return apperror.Unauthorized("Single sign-on failed")
+	}
+
+	role, ok := h.role(claims)
+	if !ok {
+		return apperror.Forbidden("Your identity provider account is not allowed to sign in")
+	}
+
+	user, err := h.user(c, claims, role)
+	if err != nil {
+		return err
+	}
+	if !user.Status {
+		return apperror.Forbidden("Account is inactive")
+	}
+
+	// Session dianggap 2FA bila provider melaporkannya lewat amr. Selain itu user yang sudah
+	// mendaftarkan TOTP lokal ditantang seperti login password, agar tidak terkunci di route
+	// enrollment oleh AUTH_TOTP_REQUIRED_ROLES
+	amr := []string{models.AMRFederated}
+	if slices.ContainsFunc(claims.Strings("amr"), func(method string) bool {
+		return slices.Contains(providerMFA, method)
+	}) {
+		amr = append(amr, models.AMROTP)
+	} else {
+		enrollment, err := h.auth.totp.Get(c.UserContext(), user.ID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return apperror.Wrap(err, "Failed to complete single sign-on")
+		}
+		if enrollment != nil && enrollment.ConfirmedAt != nil {
+			return h.auth.challenge(c, user, amr)
+		}
+	}
+
+	response, err := h.auth.startSession(c, user, amr)
+	if err != nil {
+		return err
+	}
+	return c.JSON(response)
+}
+
+// role memetakan grup provider ke role tertinggi yang cocok, atau DefaultRole bila tidak ada
+func (h *OIDCHandler) role(claims *oidc.Claims) (models.UserRole, bool) {
+	best := -1
+	for _, group := range claims.Strings(h.cfg.GroupsClaim) {
+		if role, ok := h.cfg.RoleMapping[group]; ok {
+			best = max(best, slices.Index(roleRank, role))
+		}
+	}
+	if best >= 0 {
+		return roleRank[best], true
+	}
+	return h.cfg.DefaultRole, h.cfg.DefaultRole != ""
+}
+
+// user mengambil user yang tertaut dengan akun provider, atau menautkan/membuatnya pada login
+// pertama. Bila OIDC_ROLE_MAPPING di-set role user disamakan dengan provider di setiap login;
+// tanpa pemetaan role hanya diberikan saat user dibuat.
+func (h *OIDCHandler) user(c *fiber.Ctx, claims *oidc.Claims, role models.UserRole) (*models.User, error) {
+	var user *models.User
+	userID, err := h.oidc.Login(c.UserContext(), claims.Issuer(), claims.Subject())
+	switch {
+	case err == nil:
+		user, err = h.users.GetByID(c.UserContext(), userID)
+		if err != nil {
+			return nil, apperror.Wrap(err, "Failed to fetch user")
+		}
+	case errors.Is(err, repository.ErrNotFound):
+		if user, err = h.provision(c, claims, role); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, apperror.Wrap(err, "Failed to complete single sign-on")
+	}
+
+	if len(h.cfg.RoleMapping) > 0 && user.Role != role {
+		if err := h.users.Update(c.UserContext(), user.ID, repository.UserUpdate{Role: role, EditedBy: user.ID}); err != nil {
+			return nil, apperror.Wrap(err, "Failed to update user role")
+		}
+		user.Role = role
+	}
+	return user, nil
+}
+
+// provision menautkan akun provider ke user lokal dengan username yang sama (bila diizinkan)
+// atau membuat user baru dari claim ID token. User SSO tidak memiliki password lokal.
+func (h *OIDCHandler) provision(c *fiber.Ctx, claims *oidc.Claims, role models.UserRole) (*models.User, error) {
+	claim := claims.String(h.cfg.UsernameClaim)
+	username := ssoUsername(claim)
+	if username == "" {
+		return nil, apperror.Forbidden("Identity provider did not supply a usable username")
+	}
+
+	existing, err := h.users.GetByUsername(c.UserContext(), username)
+	switch {
+	case err == nil:
+		if !h.linkable(claims, claim, existing) {
+			return nil, apperror.Conflict("Username already exists")
+		}
+		if err := h.oidc.Link(c.UserContext(), existing.ID, claims.Issuer(), claims.Subject()); err != nil {
+			return nil, apperror.Wrap(err, "Failed to link account")
+		}
+		return existing, nil
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, apperror.Wrap(err, "Failed to fetch user")
+	}
+
+	phone, err := h.phone(c, claims.String("phone_number"))
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		Name:     ssoName(claims.String("name"), username),
+		Phone:    phone,
+		Username: username,
+		Role:     role,
+	}
+	if err := h.oidc.Provision(c.UserContext(), user, claims.Issuer(), claims.Subject()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, apperror.Conflict("Username already exists")
+		}
+		return nil, apperror.Wrap(err, "Failed to create user")
+	}
+	return user, nil
+}
+
+// linkable menentukan apakah akun provider boleh ditautkan ke user lokal yang sudah ada. Claim
+// harus sama persis dengan username lokal sebelum dinormalisasi ssoUsername (mis. "ad.min" atau
+// "admin@lain" tidak pernah cocok dengan "admin") dan diverifikasi provider lewat claim
+// <nama>_verified. Admin tidak pernah ditautkan otomatis.
+func (h *OIDCHandler) linkable(claims *oidc.Claims, claim string, existing *models.User) bool {
+	return h.cfg.LinkExisting &&
+		claim == existing.Username &&
+		claims.Bool(h.cfg.UsernameClaim+"_verified") &&
+		existing.Role != models.RoleAdmin
+}
+
+// phone nomor telepon dari provider bila formatnya E.164 dan belum dipakai user lain; selain itu
+// user dibuat tanpa nomor telepon dan dapat mengisinya sendiri lewat /me
+func (h *OIDCHandler) phone(c *fiber.Ctx, value string) (string, error) {
+	value = strings.ReplaceAll(value, " ", "")
+	if !e164.MatchString(value) {
+		return "", nil
+	}
+	taken, err := h.users.Conflicts(c.UserContext(), 0, "", value)
+	if err != nil {
+		return "", apperror.Wrap(err, "Failed to check phone number")
+	}
+	if len(taken) > 0 {
+		return "", nil
+	}
+	return value, nil
+}
+
+// ssoUsername menyesuaikan username provider dengan aturan username lokal (alfanumerik,
+// maks. 50 karakter); username berupa email memakai bagian sebelum @
+func ssoUsername(value string) string {
+	value, _, _ = strings.Cut(value, "@")
+	value = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, value)
+	if len(value) > maxUsernameLength {
+		value = value[:maxUsernameLength]
+	}
+	return value
+}
+
+// ssoName nama lengkap dari claim name, atau username bila provider tidak mengirimnya
+func ssoName(name, username string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return username
+	}
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+	return name
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/notify"
+	"backend-go/internal/password"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// notifyTimeout batas waktu pengiriman satu pesan di latar belakang
+const notifyTimeout = 30 * time.Second
+
+type PasswordHandler struct {
+	users    repository.UserRepository
+	resets   repository.PasswordResetRepository
+	sessions repository.SessionRepository
+	notifier notify.Notifier
+	tasks    *background.Group
+	auth     config.AuthConfig
+}
+
+func NewPasswordHandler(users repository.UserRepository, resets repository.PasswordResetRepository, sessions repository.SessionRepository, notifier notify.Notifier, tasks *background.Group, authConfig config.AuthConfig) *PasswordHandler {
+	return &PasswordHandler{users: users, resets: resets, sessions: sessions, notifier: notifier, tasks: tasks, auth: authConfig}
+}
+
+// ForgotPassword godoc
+// @Summary      Request password reset
+// @Description  Send a single-use password reset token to the account's contact. The response is the same whether or not the username exists.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ForgotPasswordRequest  true  "Account username"
+// @Success      202  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /auth/forgot [post]
+func (h *PasswordHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	// Response sama untuk username yang tidak ada agar akun tidak bisa ditebak
+	accepted := func() error {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "If the account exists, password reset instructions have been sent",
+		})
+	}
+
+	user, err := h.users.GetByUsername(c.UserContext(), req.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return accepted()
+		}
+		return apperror.Wrap(err, "Failed to request password reset")
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate reset token")
+	}
+	if err := h.resets.Create(c.UserContext(), user.ID, tokenHash, time.Now().Add(h.auth.ResetTTL)); err != nil {
+		return apperror.Wrap(err, "Failed to request password reset")
+	}
+
+	// Pengiriman di latar belakang supaya waktu response tidak membedakan akun yang ada
+	msg := notify.Message{
+		To:      user.Phone,
+		Subject: "Password reset",
+		Body:    fmt.Sprintf("Hi %s, use this code to reset your password: %s. It expires in %s.", user.Name, token, h.auth.ResetTTL),
+	}
+	h.tasks.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := h.notifier.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send password reset to user %d: %v", user.ID, err)
+		}
+	})
+
+	return accepted()
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password with a reset token. The token can be used once, and every existing session of the account is revoked. The password must satisfy the password policy (length, character classes, no username or phone number, not a common password).
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /auth/reset [post]
+func (h *PasswordHandler) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	// Token diperiksa lebih dulu agar kebijakan password bisa memakai data pemilik akun
+	tokenHash := hashToken(req.Token)
+	userID, err := h.resets.Lookup(c.UserContext(), tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenInvalid) {
+			return apperror.BadRequest("Reset token is invalid, expired, or already used")
+		}
+		return apperror.Wrap(err, "Failed to reset password")
+	}
+	user, err := h.users.GetByID(c.UserContext(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.BadRequest("Reset token is invalid, expired, or already used")
+		}
+		return apperror.Wrap(err, "Failed to reset password")
+	}
+	if err := checkPassword(c, h.auth, "password", req.Password, user.Username, user.Phone); err != nil {
+		return err
+	}
+
+	hashedPassword, err := password.Hash(req.Password, h.auth.BcryptCost)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to process password")
+	}
+
+	userID, err = h.resets.Consume(c.UserContext(), tokenHash, hashedPassword)
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenInvalid) {
+			return apperror.BadRequest("Reset token is invalid, expired, or already used")
+		}
+		return apperror.Wrap(err, "Failed to reset password")
+	}
+
+	// Session lama bisa jadi milik orang yang mengetahui password sebelumnya
+	if _, err := h.sessions.RevokeAll(c.UserContext(), userID, models.SessionRevokedPasswordReset); err != nil {
+		return apperror.Wrap(err, "Failed to revoke sessions")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password has been reset, please log in again",
+	})
+}
+
+// checkPassword menerapkan kebijakan password pada field; personal berisi username dan nomor
+// telepon pemilik akun yang tidak boleh dimuat password
+func checkPassword(c *fiber.Ctx, auth config.AuthConfig, field, pw string, personal ...string) error {
+	if v := auth.Password.Check(pw, personal...); v != nil {
+		return validation.Field(c, field, v.Rule, v.Param)
+	}
+	return nil
+}
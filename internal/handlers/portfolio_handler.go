@@ -1,18 +1,17 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"fmt"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
 	"math"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
 )
 
 // CreatePortfolioReview godoc
@@ -28,8 +27,9 @@ import (
 // @Param        date         formData  string  true  "Review date (YYYY-MM-DD)"
 // @Security     ApiKeyAuth
 // @Success      201  {object}  models.PortfolioReview
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /portfolio/reviews [post]
 func (h *PortfolioHandler) CreatePortfolioReview(c *fiber.Ctx) error {
 	// Dapatkan user yang membuat
@@ -38,17 +38,16 @@ func (h *PortfolioHandler) CreatePortfolioReview(c *fiber.Ctx) error {
 	// Parse form data
 	var req models.PortfolioReviewCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
 	// Parse date
 	date, err := time.Parse("2006-01-02", req.Date)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid date format. Use YYYY-MM-DD",
-		})
+		return apperror.BadRequest("Invalid date format. Use YYYY-MM-DD")
 	}
 
 	// Handle image upload
@@ -57,100 +56,47 @@ func (h *PortfolioHandler) CreatePortfolioReview(c *fiber.Ctx) error {
 
 	if file != nil {
 		// Validasi tipe file
-		allowedTypes := map[string]bool{
-			".jpg":  true,
-			".jpeg": true,
-			".png":  true,
-			".webp": true,
-		}
-		ext := strings.ToLower(filepath.Ext(file.Filename))
-		if !allowedTypes[ext] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid file type. Allowed: JPG, JPEG, PNG, WEBP",
-			})
+		ext, err := imageExt(c, file)
+		if err != nil {
+			return err
 		}
 
 		// Simpan gambar
-		currentDir, _ := os.Getwd()
-		uploadDir := filepath.Join(currentDir, "uploads/portfolio/reviews")
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create upload directory",
-			})
-		}
-
-		filename := fmt.Sprintf("%d-%s%s",
-			time.Now().UnixNano(),
-			strings.ReplaceAll(req.Title, " ", "-"),
-			ext,
-		)
-		filePath := filepath.Join(uploadDir, filename)
-
-		if err := c.SaveFile(file, filePath); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to save image",
-			})
+		imagePath, err = h.uploads.save(c, file, "portfolio/reviews", req.Title, ext)
+		if err != nil {
+			return apperror.Wrap(err, "Failed to save image")
 		}
-		imagePath = "uploads/portfolio/reviews/" + filename
 	}
 
 	// Validasi product_id jika ada
 	if req.ProductID != nil {
-		var exists bool
-		err := h.db.QueryRow(
-			context.Background(),
-			"SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)",
-			*req.ProductID,
-		).Scan(&exists)
+		exists, err := h.products.Exists(c.UserContext(), *req.ProductID)
 
 		if err != nil || !exists {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid product ID",
-			})
+			return apperror.BadRequest("Invalid product ID")
 		}
 	}
 
 	// Insert ke database
-	query := `
-        INSERT INTO portfolio_review (
-            id_product,
-            title,
-            description,
-            image,
-            date,
-            created_by
-        ) VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, created_at
-    `
-
-	var review models.PortfolioReview
-	err = h.db.QueryRow(context.Background(), query,
-		req.ProductID,
-		req.Title,
-		req.Description,
-		imagePath,
-		date,
-		userID,
-	).Scan(&review.ID, &review.CreatedAt)
+	review := models.PortfolioReview{
+		ProductID:      req.ProductID,
+		Title:          req.Title,
+		Description:    req.Description,
+		Image:          imagePath,
+		Date:           date,
+		CreatedBy:      userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	}
+	err = h.reviews.Create(c.UserContext(), &review)
 
 	if err != nil {
 		// Hapus gambar jika gagal insert
 		if imagePath != "" {
-			os.Remove("." + imagePath)
+			h.uploads.remove(imagePath)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create portfolio review: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to create portfolio review")
 	}
 
-	// Isi response
-	review.ProductID = req.ProductID
-	review.Title = req.Title
-	review.Description = req.Description
-	review.Image = imagePath
-	review.Date = date
-	review.CreatedBy = userID
-
 	return c.Status(fiber.StatusCreated).JSON(review)
 }
 
@@ -168,9 +114,10 @@ func (h *PortfolioHandler) CreatePortfolioReview(c *fiber.Ctx) error {
 // @Param        date         formData  string  false "Review date (YYYY-MM-DD)"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.PortfolioReview
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /portfolio/reviews/{id} [put]
 func (h *PortfolioHandler) UpdatePortfolioReview(c *fiber.Ctx) error {
 	// Dapatkan user yang melakukan update
@@ -180,33 +127,26 @@ func (h *PortfolioHandler) UpdatePortfolioReview(c *fiber.Ctx) error {
 	reviewID := c.Params("id")
 	id, err := strconv.Atoi(reviewID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid review ID format",
-		})
+		return apperror.BadRequest("Invalid review ID format")
 	}
 
 	// Cek apakah review ada
-	var existingImage string
-	var existingProductID *int
-	err = h.db.QueryRow(
-		context.Background(),
-		`SELECT image, id_product FROM portfolio_review 
-         WHERE id = $1 AND deleted_at IS NULL`,
-		id,
-	).Scan(&existingImage, &existingProductID)
-
+	existing, err := h.reviews.Get(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Portfolio review not found",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio review not found")
+		}
+		return apperror.From(err)
 	}
+	existingImage := existing.Image
 
 	// Parse form data
 	var req models.PortfolioReviewUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
 	// Handle image upload
@@ -215,125 +155,60 @@ func (h *PortfolioHandler) UpdatePortfolioReview(c *fiber.Ctx) error {
 
 	if file != nil {
 		// Validasi tipe file
-		allowedTypes := map[string]bool{
-			".jpg":  true,
-			".jpeg": true,
-			".png":  true,
-			".webp": true,
-		}
-		ext := strings.ToLower(filepath.Ext(file.Filename))
-		if !allowedTypes[ext] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid file type. Allowed: JPG, JPEG, PNG, WEBP",
-			})
+		ext, err := imageExt(c, file)
+		if err != nil {
+			return err
 		}
 
 		// Simpan gambar baru
-		currentDir, _ := os.Getwd()
-		uploadDir := filepath.Join(currentDir, "uploads/portfolio/reviews")
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create upload directory",
-			})
-		}
-
-		filename := fmt.Sprintf("%d-%s%s",
-			time.Now().UnixNano(),
-			strings.ReplaceAll(req.Title, " ", "-"),
-			ext,
-		)
-		filePath := filepath.Join(uploadDir, filename)
-
-		if err := c.SaveFile(file, filePath); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to save new image",
-			})
+		newImagePath, err = h.uploads.save(c, file, "portfolio/reviews", req.Title, ext)
+		if err != nil {
+			return apperror.Wrap(err, "Failed to save new image")
 		}
-		newImagePath = "uploads/portfolio/reviews/" + filename
-
-		// Hapus gambar lama
-		go func(oldPath string) {
-			if oldPath != "" {
-				os.Remove("" + oldPath)
-			}
-		}(existingImage)
 	}
 
 	// Parse dan validasi date
-	var date time.Time
+	var date *time.Time
 	if req.Date != "" {
-		date, err = time.Parse("2006-01-02", req.Date)
+		parsed, err := time.Parse("2006-01-02", req.Date)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid date format. Use YYYY-MM-DD",
-			})
+			return apperror.BadRequest("Invalid date format. Use YYYY-MM-DD")
 		}
+		date = &parsed
 	}
 
 	// Validasi product_id jika ada
 	if req.ProductID != nil {
-		var exists bool
-		err := h.db.QueryRow(
-			context.Background(),
-			"SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)",
-			*req.ProductID,
-		).Scan(&exists)
+		exists, err := h.products.Exists(c.UserContext(), *req.ProductID)
 
 		if err != nil || !exists {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid product ID",
-			})
+			return apperror.BadRequest("Invalid product ID")
 		}
 	}
 
-	// Build dynamic query
-	query := `UPDATE portfolio_review SET
-                id_product = COALESCE(NULLIF($1, 0), id_product),
-                title = COALESCE(NULLIF($2, ''), title),
-                description = COALESCE(NULLIF($3, ''), description),
-                image = COALESCE(NULLIF($4, ''), image),
-                date = COALESCE($5, date),
-                edited_by = $6
-              WHERE id = $7
-              RETURNING *`
-
-	args := []interface{}{
-		req.ProductID,
-		req.Title,
-		req.Description,
-		newImagePath,
-		date,
-		userID,
-		id,
-	}
-
-	var review models.PortfolioReview
-	err = h.db.QueryRow(
-		context.Background(),
-		query,
-		args...,
-	).Scan(
-		&review.ID,
-		&review.ProductID,
-		&review.Title,
-		&review.Description,
-		&review.Image,
-		&review.Date,
-		&review.CreatedAt,
-		&review.CreatedBy,
-		&review.EditedAt,
-		&review.EditedBy,
-		&review.DeletedAt,
-		&review.DeletedBy,
-	)
+	review, err := h.reviews.Update(c.UserContext(), id, repository.PortfolioReviewUpdate{
+		ProductID:      req.ProductID,
+		Title:          req.Title,
+		Description:    req.Description,
+		Image:          newImagePath,
+		Date:           date,
+		EditedBy:       userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	})
 
 	if err != nil {
 		if newImagePath != "" {
-			os.Remove("." + newImagePath)
+			h.uploads.remove(newImagePath)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio review not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update portfolio review: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to update portfolio review")
+	}
+
+	// Hapus gambar lama setelah update berhasil
+	if newImagePath != "" {
+		h.uploads.removeLater(existingImage)
 	}
 
 	return c.JSON(review)
@@ -348,9 +223,10 @@ func (h *PortfolioHandler) UpdatePortfolioReview(c *fiber.Ctx) error {
 // @Param        id   path      int  true  "Review ID"
 // @Security     ApiKeyAuth
 // @Success      204  "No Content"
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /portfolio/reviews/{id} [delete]
 func (h *PortfolioHandler) DeletePortfolioReview(c *fiber.Ctx) error {
 	// Dapatkan user yang melakukan delete
@@ -360,39 +236,18 @@ func (h *PortfolioHandler) DeletePortfolioReview(c *fiber.Ctx) error {
 	reviewID := c.Params("id")
 	id, err := strconv.Atoi(reviewID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid review ID format",
-		})
+		return apperror.BadRequest("Invalid review ID format")
 	}
 
 	// Lakukan soft delete
-	query := `
-        UPDATE portfolio_review 
-        SET deleted_at = $1, 
-            deleted_by = $2 
-        WHERE id = $3 
-            AND deleted_at IS NULL
-    `
-
-	result, err := h.db.Exec(
-		context.Background(),
-		query,
-		time.Now(),
-		userID,
-		id,
-	)
+	err = h.reviews.SoftDelete(c.UserContext(), id, userID, middleware.Impersonator(c))
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete portfolio review: " + err.Error(),
-		})
-	}
-
-	// Cek apakah data benar-benar terupdate
-	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Portfolio review not found or already deleted",
-		})
+		// Cek apakah data benar-benar terupdate
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio review not found or already deleted")
+		}
+		return apperror.Wrap(err, "Failed to delete portfolio review")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -403,9 +258,11 @@ func (h *PortfolioHandler) DeletePortfolioReview(c *fiber.Ctx) error {
 // @Description  Retrieve all active portfolio reviews with optional product info
 // @Tags         portfolio
 // @Produce      json
-// @Success      200  {array}  handlers.PortfolioReviewWithProduct
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /portfolio/reviews [get]
 func (h *PortfolioHandler) GetPortfolioReviews(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -422,78 +279,15 @@ func (h *PortfolioHandler) GetPortfolioReviews(c *fiber.Ctx) error {
 
 	offset := (page - 1) * limit
 
-	query := `
-        SELECT 
-            pr.id,
-            pr.id_product,
-            pr.title,
-            pr.description,
-            pr.image,
-            pr.date,
-            pr.created_at,
-            pr.created_by,
-            pr.edited_at,
-            pr.edited_by,
-            p.title as product_name,
-            p.image as product_image
-        FROM portfolio_review pr
-        LEFT JOIN products p ON pr.id_product = p.id
-        WHERE pr.deleted_at IS NULL
-        ORDER BY pr.date DESC
-        LIMIT $1 OFFSET $2
-    `
-
-	rows, err := h.db.Query(context.Background(), query, limit, offset)
+	reviews, total, err := h.reviews.List(c.UserContext(), repository.Page{Limit: limit, Offset: offset})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch portfolio reviews: " + err.Error(),
-		})
-	}
-	defer rows.Close()
-
-	var reviews []models.PortfolioReviewWithProduct
-	for rows.Next() {
-		var review models.PortfolioReviewWithProduct
-		err := rows.Scan(
-			&review.ID,
-			&review.ProductID,
-			&review.Title,
-			&review.Description,
-			&review.Image,
-			&review.Date,
-			&review.CreatedAt,
-			&review.CreatedBy,
-			&review.EditedAt,
-			&review.EditedBy,
-			&review.ProductName,
-			&review.ProductImage,
-		)
-
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to parse portfolio reviews: " + err.Error(),
-			})
-		}
-		reviews = append(reviews, review)
+		return apperror.Wrap(err, "Failed to fetch portfolio reviews")
 	}
 
 	if len(reviews) == 0 {
 		return c.Status(fiber.StatusOK).JSON([]interface{}{})
 	}
 
-	// Query untuk total data
-	var total int
-	err = h.db.QueryRow(
-		context.Background(),
-		"SELECT COUNT(*) FROM portfolio_review WHERE deleted_at IS NULL",
-	).Scan(&total)
-
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get total data",
-		})
-	}
-
 	return c.JSON(fiber.Map{
 		"data": reviews,
 		"meta": fiber.Map{
@@ -511,65 +305,28 @@ func (h *PortfolioHandler) GetPortfolioReviews(c *fiber.Ctx) error {
 // @Tags         portfolio
 // @Produce      json
 // @Param        id   path      int  true  "Portfolio Review ID"
-// @Success      200  {object}  models.PortfolioReviewDetail
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.PortfolioReviewWithProduct
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /portfolio/reviews/{id} [get]
 func (h *PortfolioHandler) GetPortfolioReviewByID(c *fiber.Ctx) error {
 	// Parse ID dari parameter URL
 	reviewID := c.Params("id")
 	id, err := strconv.Atoi(reviewID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid review ID format",
-		})
+		return apperror.BadRequest("Invalid review ID format")
 	}
 
-	query := `
-        SELECT 
-            pr.id,
-            pr.id_product,
-            pr.title,
-            pr.description,
-            pr.image,
-            pr.date,
-            pr.created_at,
-            pr.created_by,
-            pr.edited_at,
-            pr.edited_by,
-            p.title as product_name,
-            p.image as product_image
-        FROM portfolio_review pr
-        LEFT JOIN products p ON pr.id_product = p.id
-        WHERE pr.id = $1 AND pr.deleted_at IS NULL
-    `
-
-	var review models.PortfolioReviewWithProduct
-	err = h.db.QueryRow(context.Background(), query, id).Scan(
-		&review.ID,
-		&review.ProductID,
-		&review.Title,
-		&review.Description,
-		&review.Image,
-		&review.Date,
-		&review.CreatedAt,
-		&review.CreatedBy,
-		&review.EditedAt,
-		&review.EditedBy,
-		&review.ProductName,
-		&review.ProductImage,
-	)
+	review, err := h.reviews.GetByID(c.UserContext(), id)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Portfolio review not found",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio review not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch portfolio review: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to fetch portfolio review")
 	}
 
 	return c.JSON(review)
@@ -1,28 +1,39 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"fmt"
-	"log"
+	"backend-go/internal/repository"
+	"errors"
 	"math"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PortfolioHandler struct {
-	db *pgxpool.Pool
+	images   repository.PortfolioImageRepository
+	reviews  repository.PortfolioReviewRepository
+	products repository.ProductRepository
+	uploads  uploadStore
 }
 
-func NewPortfolioHandler(db *pgxpool.Pool) *PortfolioHandler {
-	return &PortfolioHandler{db: db}
+func NewPortfolioHandler(
+	images repository.PortfolioImageRepository,
+	reviews repository.PortfolioReviewRepository,
+	products repository.ProductRepository,
+	uploads config.UploadConfig,
+	tasks *background.Group,
+) *PortfolioHandler {
+	return &PortfolioHandler{
+		images:   images,
+		reviews:  reviews,
+		products: products,
+		uploads:  newUploadStore(uploads, tasks),
+	}
 }
 
 // CreatePortfolioImage godoc
@@ -34,9 +45,10 @@ func NewPortfolioHandler(db *pgxpool.Pool) *PortfolioHandler {
 // @Param        image  formData  file  true  "Portfolio image"
 // @Security     ApiKeyAuth
 // @Success      201  {object}  models.PortfolioImage
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
-// @Router       /portfolio [post]
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /portfolio/images [post]
 func (h *PortfolioHandler) CreatePortfolioImage(c *fiber.Ctx) error {
 	// Dapatkan user yang membuat
 	userID := c.Locals("userID").(int)
@@ -44,71 +56,35 @@ func (h *PortfolioHandler) CreatePortfolioImage(c *fiber.Ctx) error {
 	// Handle image upload
 	file, err := c.FormFile("image")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Image is required",
-		})
+		return apperror.BadRequest("Image is required")
 	}
 
 	// Validasi tipe file
-	allowedTypes := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".webp": true,
-	}
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !allowedTypes[ext] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file type. Allowed: JPG, JPEG, PNG, WEBP",
-		})
-	}
-
-	// Simpan gambar
-	currentDir, _ := os.Getwd()
-	uploadDir := filepath.Join(currentDir, "uploads/portfolio/images")
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create upload directory",
-		})
+	ext, err := imageExt(c, file)
+	if err != nil {
+		return err
 	}
 
-	filename := fmt.Sprintf("%d-%s",
-		time.Now().UnixNano(),
-		filepath.Base(file.Filename),
-	)
-	filePath := filepath.Join(uploadDir, filename)
-
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save image",
-		})
+	// Simpan gambar dengan nama file asli sebagai slug
+	imagePath, err := h.uploads.save(c, file, "portfolio/images", uploadBaseName(file), ext)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to save image")
 	}
 
 	// Simpan ke database
-	query := `
-        INSERT INTO portfolio_images (image, created_by)
-        VALUES ($1, $2)
-        RETURNING id, created_at
-    `
-
-	var portfolioImage models.PortfolioImage
-	err = h.db.QueryRow(context.Background(), query,
-		"uploads/portfolio/images/"+filename,
-		userID,
-	).Scan(&portfolioImage.ID, &portfolioImage.CreatedAt)
+	portfolioImage := models.PortfolioImage{
+		Image:          imagePath,
+		CreatedBy:      userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	}
+	err = h.images.Create(c.UserContext(), &portfolioImage)
 
 	if err != nil {
 		// Hapus file yang sudah diupload jika gagal insert
-		os.Remove(filePath)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create portfolio image: " + err.Error(),
-		})
+		h.uploads.remove(imagePath)
+		return apperror.Wrap(err, "Failed to create portfolio image")
 	}
 
-	// Isi response
-	portfolioImage.Image = "uploads/portfolio/images/" + filename
-	portfolioImage.CreatedBy = userID
-
 	return c.Status(fiber.StatusCreated).JSON(portfolioImage)
 }
 
@@ -122,10 +98,11 @@ func (h *PortfolioHandler) CreatePortfolioImage(c *fiber.Ctx) error {
 // @Param        image  formData  file  true  "New portfolio image"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.PortfolioImage
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
-// @Router       /portfolio/{id} [put]
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /portfolio/images/{id} [put]
 func (h *PortfolioHandler) UpdatePortfolioImage(c *fiber.Ctx) error {
 	// Dapatkan user yang melakukan update
 	userID := c.Locals("userID").(int)
@@ -134,110 +111,58 @@ func (h *PortfolioHandler) UpdatePortfolioImage(c *fiber.Ctx) error {
 	imageID := c.Params("id")
 	id, err := strconv.Atoi(imageID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid image ID format",
-		})
+		return apperror.BadRequest("Invalid image ID format")
 	}
 
 	// Handle image upload
 	file, err := c.FormFile("image")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Image is required",
-		})
+		return apperror.BadRequest("Image is required")
 	}
 
 	// Validasi tipe file
-	allowedTypes := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".webp": true,
-	}
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !allowedTypes[ext] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file type. Allowed: JPG, JPEG, PNG, WEBP",
-		})
+	ext, err := imageExt(c, file)
+	if err != nil {
+		return err
 	}
 
 	// Dapatkan path gambar lama
-	var oldImagePath string
-	err = h.db.QueryRow(
-		context.Background(),
-		"SELECT image FROM portfolio_images WHERE id = $1 AND deleted_at IS NULL",
-		id,
-	).Scan(&oldImagePath)
-
+	existing, err := h.images.GetByID(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Portfolio image not found",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio image not found")
+		}
+		return apperror.From(err)
 	}
+	oldImagePath := existing.Image
 
 	// Simpan gambar baru
-	uploadDir := "uploads/portfolio/images"
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create upload directory",
-		})
-	}
-
-	filename := fmt.Sprintf("%d-%s",
-		time.Now().UnixNano(),
-		filepath.Base(file.Filename),
-	)
-	filePath := filepath.Join(uploadDir, filename)
-
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save new image",
-		})
+	newImagePath, err := h.uploads.save(c, file, "portfolio/images", uploadBaseName(file), ext)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to save new image")
 	}
 
 	// Update database
-	query := `
-        UPDATE portfolio_images 
-        SET 
-            image = $1,
-            edited_by = $2
-        WHERE id = $3
-        RETURNING id, image, created_at, edited_at
-    `
-
-	var updatedImage models.PortfolioImage
-	err = h.db.QueryRow(
-		context.Background(),
-		query,
-		"uploads/portfolio/images/"+filename,
-		userID,
+	updatedImage, err := h.images.UpdateImage(
+		c.UserContext(),
 		id,
-	).Scan(
-		&updatedImage.ID,
-		&updatedImage.Image,
-		&updatedImage.CreatedAt,
-		&updatedImage.EditedAt,
+		newImagePath,
+		userID,
+		middleware.Impersonator(c),
 	)
 
 	if err != nil {
 		// Hapus gambar baru jika gagal update
-		os.Remove(filePath)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update portfolio image: " + err.Error(),
-		})
+		h.uploads.remove(newImagePath)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio image not found")
+		}
+		return apperror.Wrap(err, "Failed to update portfolio image")
 	}
 
 	// Hapus gambar lama
-	go func(oldPath string) {
-		if oldPath != "" {
-			fullPath := "" + oldPath // karena path disimpan sebagai "/uploads/..."
-			if err := os.Remove(fullPath); err != nil {
-				log.Printf("Gagal menghapus gambar lama: %s. Error: %v", oldPath, err)
-			}
-		}
-	}(oldImagePath)
+	h.uploads.removeLater(oldImagePath)
 
-	updatedImage.CreatedBy = userID
 	return c.JSON(updatedImage)
 }
 
@@ -250,83 +175,43 @@ func (h *PortfolioHandler) UpdatePortfolioImage(c *fiber.Ctx) error {
 // @Param        id   path      int  true  "Portfolio Image ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      403  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
-// @Router       /portfolio/{id} [delete]
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /portfolio/images/{id} [delete]
 func (h *PortfolioHandler) DeletePortfolioImage(c *fiber.Ctx) error {
 	// Dapatkan admin yang melakukan delete
 	adminID := c.Locals("userID").(int)
-	adminRole := c.Locals("userRole").(models.UserRole)
-
-	if adminRole != models.RoleAdmin {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required",
-		})
-	}
 
 	// Parse ID
 	imageID := c.Params("id")
 	id, err := strconv.Atoi(imageID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid image ID format",
-		})
+		return apperror.BadRequest("Invalid image ID format")
 	}
 
 	// Dapatkan path gambar
-	var imagePath string
-	err = h.db.QueryRow(
-		context.Background(),
-		`SELECT image FROM portfolio_images 
-         WHERE id = $1 AND deleted_at IS NULL`,
-		id,
-	).Scan(&imagePath)
-
+	image, err := h.images.GetByID(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Portfolio image not found",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio image not found")
+		}
+		return apperror.From(err)
 	}
+	imagePath := image.Image
 
 	// Soft delete di database
-	query := `
-        UPDATE portfolio_images 
-        SET 
-            deleted_at = $1,
-            deleted_by = $2
-        WHERE id = $3
-    `
-	result, err := h.db.Exec(
-		context.Background(),
-		query,
-		time.Now().UTC(),
-		adminID,
-		id,
-	)
-
+	err = h.images.SoftDelete(c.UserContext(), id, adminID, middleware.Impersonator(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete portfolio image",
-		})
-	}
-
-	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Portfolio image not found",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio image not found")
+		}
+		return apperror.Wrap(err, "Failed to delete portfolio image")
 	}
 
 	// Hapus file gambar
-	if imagePath != "" {
-		go func(path string) {
-			fullPath := "" + path // karena path disimpan sebagai "/uploads/..."
-			if err := os.Remove(fullPath); err != nil {
-				log.Printf("Gagal menghapus gambar portfolio: %s. Error: %v", path, err)
-			}
-		}(imagePath)
-	}
+	h.uploads.removeLater(imagePath)
 
 	return c.JSON(fiber.Map{
 		"message": "Portfolio image deleted successfully",
@@ -341,9 +226,11 @@ func (h *PortfolioHandler) DeletePortfolioImage(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        page    query     int     false  "Page number"     default(1)
 // @Param        limit   query     int     false  "Items per page"  default(10)
+// @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      500  {object}  map[string]string
-// @Router       /portfolio [get]
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /portfolio/images [get]
 func (h *PortfolioHandler) GetPortfolioImages(c *fiber.Ctx) error {
 	// Parse query parameters
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -359,49 +246,9 @@ func (h *PortfolioHandler) GetPortfolioImages(c *fiber.Ctx) error {
 	offset := (page - 1) * limit
 
 	// Query untuk mendapatkan data
-	query := `SELECT 
-                id, image, created_at, created_by 
-              FROM portfolio_images 
-              WHERE deleted_at IS NULL
-              ORDER BY created_at DESC 
-              LIMIT $1 OFFSET $2`
-
-	rows, err := h.db.Query(context.Background(), query, limit, offset)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch portfolio images",
-		})
-	}
-	defer rows.Close()
-
-	var images []models.PortfolioImageResponse
-	for rows.Next() {
-		var img models.PortfolioImageResponse
-		err := rows.Scan(
-			&img.ID,
-			&img.Image,
-			&img.CreatedAt,
-			&img.CreatedBy,
-		)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to parse image data",
-			})
-		}
-		images = append(images, img)
-	}
-
-	// Query untuk total data
-	var total int
-	err = h.db.QueryRow(
-		context.Background(),
-		"SELECT COUNT(*) FROM portfolio_images WHERE deleted_at IS NULL",
-	).Scan(&total)
-
+	images, total, err := h.images.List(c.UserContext(), repository.Page{Limit: limit, Offset: offset})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get total images",
-		})
+		return apperror.Wrap(err, "Failed to fetch portfolio images")
 	}
 
 	return c.JSON(fiber.Map{
@@ -416,54 +263,41 @@ func (h *PortfolioHandler) GetPortfolioImages(c *fiber.Ctx) error {
 }
 
 // GetPortfolioImagesByID godoc
-// @Summary      Get PortfolioImages by ID
-// @Description  Retrieve PortfolioImages details by PortfolioImages ID
-// @Tags         PortfolioImages
+// @Summary      Get portfolio image by ID
+// @Description  Retrieve portfolio image details by portfolio image ID
+// @Tags         portfolio
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "PortfolioImages ID"
+// @Param        id   path      int  true  "Portfolio Image ID"
 // @Security     ApiKeyAuth
-// @Success      200  {object}  models.PortfolioImagesResponse
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
-// @Router       /portofolio/image/{id} [get]
+// @Success      200  {object}  models.PortfolioImageResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /portfolio/images/{id} [get]
 func (h *PortfolioHandler) GetPortfolioImageByID(c *fiber.Ctx) error {
 	// Parse ID dari parameter URL
 	PorfolioID := c.Params("id")
 	id, err := strconv.Atoi(PorfolioID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid Portfolio Image ID format",
-		})
+		return apperror.BadRequest("Invalid Portfolio Image ID format")
 	}
 
 	// Query ke database
-	query := `
-        SELECT 
-            id, image, created_at, created_by
-        FROM portfolio_images
-        WHERE id = $1 AND deleted_at IS NULL
-    `
-
-	var portfolio_images models.PortfolioImageResponse
-	err = h.db.QueryRow(context.Background(), query, id).Scan(
-		&portfolio_images.ID,
-		&portfolio_images.Image,
-		&portfolio_images.CreatedAt,
-		&portfolio_images.CreatedBy,
-	)
+	image, err := h.images.GetByID(c.UserContext(), id)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Portfolio Image not found",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Portfolio Image not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch Portfolio Image: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to fetch Portfolio Image")
 	}
 
-	return c.JSON(portfolio_images)
+	return c.JSON(models.PortfolioImageResponse{
+		ID:        image.ID,
+		Image:     image.Image,
+		CreatedAt: image.CreatedAt,
+		CreatedBy: image.CreatedBy,
+	})
 }
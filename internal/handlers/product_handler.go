@@ -1,29 +1,28 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"fmt"
-	"log"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
 	"math"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
 )
 
 type ProductHandler struct {
-	db *pgxpool.Pool
+	products repository.ProductRepository
+	uploads  uploadStore
 }
 
-func NewProductHandler(db *pgxpool.Pool) *ProductHandler {
-	return &ProductHandler{db: db}
+func NewProductHandler(products repository.ProductRepository, uploads config.UploadConfig, tasks *background.Group) *ProductHandler {
+	return &ProductHandler{products: products, uploads: newUploadStore(uploads, tasks)}
 }
 
 // CreateProduct godoc
@@ -40,8 +39,9 @@ func NewProductHandler(db *pgxpool.Pool) *ProductHandler {
 // @Param        status       formData  bool    false "Product status"
 // @Security     ApiKeyAuth
 // @Success      201  {object}  models.Product
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /products [post]
 func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 	// Dapatkan user yang membuat
@@ -50,111 +50,55 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 	// Handle image upload
 	file, err := c.FormFile("image")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Image is required",
-		})
+		return validation.Field(c, "image", "required", "")
 	}
 
 	// Validasi tipe file
-	allowedTypes := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".webp": true,
-	}
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !allowedTypes[ext] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file type. Allowed: JPG, JPEG, PNG, WEBP",
-		})
+	ext, err := imageExt(c, file)
+	if err != nil {
+		return err
 	}
 
 	// Parse form data
 	var req models.ProductCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
-	// Konversi price ke decimal
+	// Konversi price ke decimal (format sudah divalidasi tag decimal)
 	price, err := decimal.NewFromString(req.Price)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid price format",
-		})
-	}
-
-	// Validasi price >= 0
-	if price.LessThan(decimal.Zero) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Price cannot be negative",
-		})
+		return apperror.BadRequest("Invalid price format")
 	}
 
 	// Simpan gambar
-	uploadDir := "uploads/products"
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create upload directory",
-		})
-	}
-
-	filename := fmt.Sprintf("%d-%s%s",
-		time.Now().UnixNano(),
-		strings.ReplaceAll(req.Title, " ", "-"),
-		ext,
-	)
-	filePath := filepath.Join(uploadDir, filename)
-
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save image",
-		})
+	imagePath, err := h.uploads.save(c, file, "products", req.Title, ext)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to save image")
 	}
 
 	// Simpan ke database
-	query := `
-        INSERT INTO products (
-            image,
-            title,
-            description,
-            type_product,
-            price,
-            status,
-            created_by
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id, created_at
-    `
-
-	var product models.Product
-	err = h.db.QueryRow(context.Background(), query,
-		"uploads/products/"+filename,
-		req.Title,
-		req.Description,
-		req.TypeProduct,
-		price,
-		req.Status,
-		userID,
-	).Scan(&product.ID, &product.CreatedAt)
+	product := models.Product{
+		Image:          imagePath,
+		Title:          req.Title,
+		Description:    req.Description,
+		TypeProduct:    req.TypeProduct,
+		Status:         req.Status,
+		CreatedBy:      userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	}
+	product.Price, _ = price.Float64()
 
+	err = h.products.Create(c.UserContext(), &product)
 	if err != nil {
 		// Hapus file yang sudah diupload jika gagal insert
-		os.Remove(filePath)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create product: " + err.Error(),
-		})
+		h.uploads.remove(imagePath)
+		return apperror.Wrap(err, "Failed to create product")
 	}
 
-	// Isi response
-	product.Image = "uploads/products/" + filename
-	product.Title = req.Title
-	product.Description = req.Description
-	product.TypeProduct = req.TypeProduct
-	product.Price, _ = price.Float64()
-	product.Status = req.Status
-	product.CreatedBy = userID
-
 	return c.Status(fiber.StatusCreated).JSON(product)
 }
 
@@ -173,42 +117,38 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 // @Param        status       formData  bool    false "Product status"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.Product
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /products/{id} [put]
 func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	productID := c.Params("id")
 	id, err := strconv.Atoi(productID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID format",
-		})
+		return apperror.BadRequest("Invalid product ID format")
 	}
 
 	// Dapatkan user yang melakukan update
 	userID := c.Locals("userID").(int)
 
 	// Cek apakah product ada
-	var existingImage string
-	err = h.db.QueryRow(context.Background(),
-		`SELECT image FROM products 
-         WHERE id = $1 AND deleted_at IS NULL`,
-		id,
-	).Scan(&existingImage)
-
+	existing, err := h.products.GetByID(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Product not found",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Product not found")
+		}
+		return apperror.From(err)
 	}
+	existingImage := existing.Image
 
 	// Parse form data
 	var req models.ProductUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid form data",
-		})
+		return apperror.BadRequest("Invalid form data")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
 	}
 
 	// Handle image upload
@@ -217,120 +157,53 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 
 	if file != nil {
 		// Validasi tipe file
-		allowedTypes := map[string]bool{
-			".jpg":  true,
-			".jpeg": true,
-			".png":  true,
-			".webp": true,
-		}
-		ext := strings.ToLower(filepath.Ext(file.Filename))
-		if !allowedTypes[ext] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid file type. Allowed: JPG, JPEG, PNG, WEBP",
-			})
+		ext, err := imageExt(c, file)
+		if err != nil {
+			return err
 		}
 
 		// Upload new image
-		uploadDir := "uploads/products"
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create upload directory",
-			})
-		}
-
-		filename := fmt.Sprintf("%d-%s%s",
-			time.Now().UnixNano(),
-			strings.ReplaceAll(req.Title, " ", "-"),
-			ext,
-		)
-		filePath := filepath.Join(uploadDir, filename)
-
-		if err := c.SaveFile(file, filePath); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to save image",
-			})
+		newImagePath, err = h.uploads.save(c, file, "products", req.Title, ext)
+		if err != nil {
+			return apperror.Wrap(err, "Failed to save image")
 		}
-		newImagePath = "uploads/products/" + filename
-
-		// Delete old image
-		go func(oldImage string) {
-			if oldImage != "" {
-				os.Remove("." + oldImage)
-			}
-		}(existingImage)
 	}
 
 	// Konversi price
-	var price decimal.Decimal
+	var price *decimal.Decimal
 	if req.Price != "" {
-		price, err = decimal.NewFromString(req.Price)
+		parsed, err := decimal.NewFromString(req.Price)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid price format",
-			})
-		}
-		if price.LessThan(decimal.Zero) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Price cannot be negative",
-			})
+			return apperror.BadRequest("Invalid price format")
 		}
-	}
-
-	// Build dynamic query
-	query := `UPDATE products SET
-				image = COALESCE(NULLIF($1, ''), image),
-				title = COALESCE(NULLIF($2, ''), title),
-				description = COALESCE(NULLIF($3, ''), description),
-				type_product = CASE 
-					WHEN $4::text = '' THEN type_product 
-					ELSE $4::product_type 
-				END,
-				price = COALESCE(NULLIF($5, 0), price),
-				status = COALESCE($6, status),
-				edited_by = $7
-			WHERE id = $8
-			RETURNING *`
-
-	args := []interface{}{
-		newImagePath,
-		req.Title,
-		req.Description,
-		req.TypeProduct, // Pastikan ini string kosong jika tidak diupdate
-		price,
-		req.Status,
-		userID,
-		id,
-	}
-
-	var product models.Product
-	var priceDB decimal.Decimal
-	err = h.db.QueryRow(context.Background(), query, args...).Scan(
-		&product.ID,
-		&product.Image,
-		&product.Title,
-		&product.Description,
-		&product.TypeProduct,
-		&priceDB,
-		&product.Status,
-		&product.CreatedAt,
-		&product.CreatedBy,
-		&product.EditedAt,
-		&product.EditedBy,
-		&product.DeletedAt,
-		&product.DeletedBy,
-	)
+		price = &parsed
+	}
+
+	product, err := h.products.Update(c.UserContext(), id, repository.ProductUpdate{
+		Image:          newImagePath,
+		Title:          req.Title,
+		Description:    req.Description,
+		TypeProduct:    req.TypeProduct, // String kosong jika tidak diupdate
+		Price:          price,
+		Status:         req.Status,
+		EditedBy:       userID,
+		ImpersonatedBy: middleware.Impersonator(c),
+	})
 
 	if err != nil {
 		if newImagePath != "" {
-			os.Remove("." + newImagePath)
+			h.uploads.remove(newImagePath)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update product: " + err.Error(),
-		})
+		return apperror.Wrap(err, "Failed to update product")
 	}
 
-	// Konversi decimal ke float untuk response
-	product.Price, _ = priceDB.Float64()
+	// Hapus gambar lama setelah update berhasil
+	if newImagePath != "" {
+		h.uploads.removeLater(existingImage)
+	}
 
 	return c.JSON(product)
 }
@@ -344,79 +217,42 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 // @Param        id   path      int  true  "Product ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      403  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	productID := c.Params("id")
 	id, err := strconv.Atoi(productID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID format",
-		})
+		return apperror.BadRequest("Invalid product ID format")
 	}
 
 	// Dapatkan admin yang melakukan delete
 	adminID := c.Locals("userID").(int)
-	adminRole := c.Locals("userRole").(models.UserRole)
-
-	if adminRole != models.RoleAdmin {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required",
-		})
-	}
 
 	// Dapatkan path gambar dan validasi keberadaan
-	var imagePath string
-	err = h.db.QueryRow(context.Background(),
-		`SELECT image FROM products 
-         WHERE id = $1 AND deleted_at IS NULL`,
-		id,
-	).Scan(&imagePath)
-
+	product, err := h.products.GetByID(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Product not found or already deleted",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Product not found or already deleted")
+		}
+		return apperror.From(err)
 	}
+	imagePath := product.Image
 
 	// Soft delete di database
-	query := `
-        UPDATE products 
-        SET deleted_at = $1, deleted_by = $2 
-        WHERE id = $3
-    `
-	result, err := h.db.Exec(
-		context.Background(),
-		query,
-		time.Now().UTC(),
-		adminID,
-		id,
-	)
-
+	err = h.products.SoftDelete(c.UserContext(), id, adminID, middleware.Impersonator(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete product",
-		})
-	}
-
-	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Product not found",
-		})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Product not found")
+		}
+		return apperror.Wrap(err, "Failed to delete product")
 	}
 
 	// Hapus file gambar
-	if imagePath != "" {
-		go func(path string) {
-			fullPath := "." + path // karena path disimpan sebagai "/uploads/..."
-			if err := os.Remove(fullPath); err != nil {
-				log.Printf("Failed to delete product image: %s. Error: %v", path, err)
-			}
-		}(imagePath)
-	}
+	h.uploads.removeLater(imagePath)
 
 	return c.JSON(fiber.Map{
 		"message": "Product deleted successfully",
@@ -435,8 +271,10 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 // @Param        type     query     string  false  "Filter by product type"
 // @Param        minPrice query     number  false  "Minimum price"
 // @Param        maxPrice query     number  false  "Maximum price"
+// @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      500  {object}  map[string]string
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /products [get]
 func (h *ProductHandler) GetProducts(c *fiber.Ctx) error {
 	// Parse query parameters
@@ -456,119 +294,39 @@ func (h *ProductHandler) GetProducts(c *fiber.Ctx) error {
 	}
 	offset := (page - 1) * limit
 
-	// Build query
-	query := `SELECT 
-                id, image, title, description, 
-                type_product, price, status, created_at 
-              FROM products 
-              WHERE deleted_at IS NULL`
-	args := []interface{}{}
-	paramCounter := 1
+	filter := repository.ProductFilter{
+		Type: models.ProductType(productType),
+		Page: repository.Page{Limit: limit, Offset: offset},
+	}
 
 	// Filter status
 	if status != "" {
 		statusBool, err := strconv.ParseBool(status)
 		if err == nil {
-			query += fmt.Sprintf(" AND status = $%d", paramCounter)
-			args = append(args, statusBool)
-			paramCounter++
+			filter.Status = &statusBool
 		}
 	}
 
-	// Filter type
-	if productType != "" {
-		query += fmt.Sprintf(" AND type_product = $%d", paramCounter)
-		args = append(args, productType)
-		paramCounter++
-	}
-
 	// Filter harga
 	if minPrice != "" {
-		query += fmt.Sprintf(" AND price >= $%d", paramCounter)
-		args = append(args, minPrice)
-		paramCounter++
-	}
-	if maxPrice != "" {
-		query += fmt.Sprintf(" AND price <= $%d", paramCounter)
-		args = append(args, maxPrice)
-		paramCounter++
-	}
-
-	// Add pagination
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", paramCounter, paramCounter+1)
-	args = append(args, limit, offset)
-
-	// Eksekusi query
-	rows, err := h.db.Query(context.Background(), query, args...)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch products",
-		})
-	}
-	defer rows.Close()
-
-	var products []models.ProductResponse
-	for rows.Next() {
-		var product models.ProductResponse
-		var price decimal.Decimal
-
-		err := rows.Scan(
-			&product.ID,
-			&product.Image,
-			&product.Title,
-			&product.Description,
-			&product.TypeProduct,
-			&price,
-			&product.Status,
-			&product.CreatedAt,
-		)
-
+		value, err := decimal.NewFromString(minPrice)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to parse product data",
-			})
+			return apperror.BadRequest("Invalid minPrice format")
 		}
-
-		product.Price, _ = price.Float64()
-		products = append(products, product)
-	}
-
-	// Get total count
-	countQuery := `SELECT COUNT(*) FROM products WHERE deleted_at IS NULL`
-	countArgs := []interface{}{}
-	paramCounter = 1
-
-	if status != "" {
-		statusBool, _ := strconv.ParseBool(status)
-		countQuery += fmt.Sprintf(" AND status = $%d", paramCounter)
-		countArgs = append(countArgs, statusBool)
-		paramCounter++
-	}
-
-	if productType != "" {
-		countQuery += fmt.Sprintf(" AND type_product = $%d", paramCounter)
-		countArgs = append(countArgs, productType)
-		paramCounter++
+		filter.MinPrice = &value
 	}
-
-	if minPrice != "" {
-		countQuery += fmt.Sprintf(" AND price >= $%d", paramCounter)
-		countArgs = append(countArgs, minPrice)
-		paramCounter++
-	}
-
 	if maxPrice != "" {
-		countQuery += fmt.Sprintf(" AND price <= $%d", paramCounter)
-		countArgs = append(countArgs, maxPrice)
-		paramCounter++
+		value, err := decimal.NewFromString(maxPrice)
+		if err != nil {
+			return apperror.BadRequest("Invalid maxPrice format")
+		}
+		filter.MaxPrice = &value
 	}
 
-	var total int
-	err = h.db.QueryRow(context.Background(), countQuery, countArgs...).Scan(&total)
+	// Eksekusi query
+	products, total, err := h.products.List(c.UserContext(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get total products",
-		})
+		return apperror.Wrap(err, "Failed to fetch products")
 	}
 
 	return c.JSON(fiber.Map{
@@ -585,57 +343,43 @@ func (h *ProductHandler) GetProducts(c *fiber.Ctx) error {
 // GetProductByID godoc
 // @Summary      Get Product by ID
 // @Description  Retrieve Product details by Product ID
-// @Tags         Products
+// @Tags         products
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Product ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.ProductResponse
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
-// @Router       /product/{id} [get]
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /products/{id} [get]
 func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 	// Parse ID dari parameter URL
 	ProductID := c.Params("id")
 	id, err := strconv.Atoi(ProductID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid Product ID format",
-		})
+		return apperror.BadRequest("Invalid Product ID format")
 	}
 
 	// Query ke database
-	query := `
-        SELECT 
-            id, image, title, description, 
-                type_product, price, status, created_at
-        FROM products
-        WHERE id = $1 AND deleted_at IS NULL
-    `
-
-	var product models.ProductResponse
-	err = h.db.QueryRow(context.Background(), query, id).Scan(
-		&product.ID,
-		&product.Image,
-		&product.Title,
-		&product.Description,
-		&product.TypeProduct,
-		&product.Price,
-		&product.Status,
-		&product.CreatedAt,
-	)
+	product, err := h.products.GetByID(c.UserContext(), id)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Product not found",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch Product: " + err.Error(),
-		})
-	}
-
-	return c.JSON(product)
+		return apperror.Wrap(err, "Failed to fetch Product")
+	}
+
+	return c.JSON(models.ProductResponse{
+		ID:          product.ID,
+		Image:       product.Image,
+		Title:       product.Title,
+		Description: product.Description,
+		TypeProduct: product.TypeProduct,
+		Price:       product.Price,
+		Status:      product.Status,
+		CreatedAt:   product.CreatedAt,
+	})
 }
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/config"
+	"backend-go/internal/middleware"
+	"backend-go/internal/models"
+	"backend-go/internal/password"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ProfileHandler struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	auth     config.AuthConfig
+}
+
+func NewProfileHandler(users repository.UserRepository, sessions repository.SessionRepository, authConfig config.AuthConfig) *ProfileHandler {
+	return &ProfileHandler{users: users, sessions: sessions, auth: authConfig}
+}
+
+// GetMe godoc
+// @Summary      Get my profile
+// @Description  Get the profile of the authenticated user
+// @Tags         profile
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.UserResponse
+// @Failure      401  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me [get]
+func (h *ProfileHandler) GetMe(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(user.Response())
+}
+
+// UpdateMe godoc
+// @Summary      Update my profile
+// @Description  Change the name, phone number or username of the authenticated user. Fields that are left out stay unchanged. Username and phone number must not be in use by another account.
+// @Tags         profile
+// @Accept       json
+// @Produce      json
+// @Param        profile  body      models.UpdateProfileRequest  true  "Profile changes"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.UserResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me [patch]
+func (h *ProfileHandler) UpdateMe(c *fiber.Ctx) error {
+	var req models.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("userID").(int)
+	update := repository.UserUpdate{EditedBy: userID, ImpersonatedBy: middleware.Impersonator(c)}
+	if req.Name != nil {
+		update.Name = *req.Name
+	}
+	if req.Phone != nil {
+		update.Phone = *req.Phone
+	}
+	if req.Username != nil {
+		update.Username = *req.Username
+	}
+
+	if err := checkUserConflicts(c, h.users, userID, update.Username, update.Phone); err != nil {
+		return err
+	}
+
+	if err := h.users.Update(c.UserContext(), userID, update); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User not found")
+		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			return apperror.Conflict("Username or phone number already exists")
+		}
+		return apperror.Wrap(err, "Failed to update profile")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(user.Response())
+}
+
+// ChangePassword godoc
+// @Summary      Change my password
+// @Description  Change the password of the authenticated user. The current password is required, and every other session of the user is signed out. The new password must satisfy the password policy.
+// @Tags         profile
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ChangePasswordRequest  true  "Current and new password"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/password [post]
+func (h *ProfileHandler) ChangePassword(c *fiber.Ctx) error {
+	var req models.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return apperror.BadRequest("Current password is incorrect")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return apperror.BadRequest("New password must be different from the current password")
+	}
+
+	if err := checkPassword(c, h.auth, "new_password", req.NewPassword, user.Username, user.Phone); err != nil {
+		return err
+	}
+
+	hashedPassword, err := password.Hash(req.NewPassword, h.auth.BcryptCost)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to process password")
+	}
+	if err := h.users.Update(c.UserContext(), user.ID, repository.UserUpdate{Password: hashedPassword, EditedBy: user.ID}); err != nil {
+		return apperror.Wrap(err, "Failed to change password")
+	}
+
+	// Session lain bisa jadi milik orang yang mengetahui password lama
+	sessionID, _ := c.Locals("sessionID").(int)
+	ids, err := h.sessions.RevokeOthers(c.UserContext(), user.ID, sessionID, models.SessionRevokedPasswordChange)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to revoke sessions")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":          "Password changed",
+		"revoked_sessions": len(ids),
+	})
+}
+
+// currentUser mengambil user pemilik token
+func (h *ProfileHandler) currentUser(c *fiber.Ctx) (*models.User, error) {
+	user, err := h.users.GetByID(c.UserContext(), c.Locals("userID").(int))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFound("User not found")
+		}
+		return nil, apperror.Wrap(err, "Failed to fetch user")
+	}
+	return user, nil
+}
+
+// checkUserConflicts menolak username atau nomor telepon yang sudah dipakai user lain yang
+// belum dihapus, dengan detail per field
+func checkUserConflicts(c *fiber.Ctx, users repository.UserRepository, id int, username, phone string) error {
+	if username == "" && phone == "" {
+		return nil
+	}
+	fields, err := users.Conflicts(c.UserContext(), id, username, phone)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to check username and phone number")
+	}
+	if len(fields) > 0 {
+		return validation.Conflict(c, "Username or phone number already exists", fields...)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	policy   *authz.Policy
+}
+
+func NewSessionHandler(users repository.UserRepository, sessions repository.SessionRepository, policy *authz.Policy) *SessionHandler {
+	return &SessionHandler{users: users, sessions: sessions, policy: policy}
+}
+
+// GetMySessions godoc
+// @Summary      List my sessions
+// @Description  List the caller's active sessions (one per login) with device, IP address and when each was created and last seen. The session of the token used for this request is marked current.
+// @Tags         sessions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.SessionResponse
+// @Failure      401  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/sessions [get]
+func (h *SessionHandler) GetMySessions(c *fiber.Ctx) error {
+	return h.listSessions(c, c.Locals("userID").(int))
+}
+
+// RevokeMySession godoc
+// @Summary      Revoke one of my sessions
+// @Description  Sign out one of the caller's sessions. Its access and refresh tokens stop working immediately. Revoking the current session is the same as logging out. Not allowed while impersonating.
+// @Tags         sessions
+// @Produce      json
+// @Param        id   path      int  true  "Session ID"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid session ID format")
+	}
+
+	// Session milik user lain diperlakukan sama dengan yang tidak ada
+	session, err := h.sessions.Get(c.UserContext(), id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(err, "Failed to fetch session")
+	}
+	if err != nil || session.UserID != c.Locals("userID").(int) || session.RevokedAt != nil {
+		return apperror.NotFound("Session not found")
+	}
+
+	if err := h.sessions.Revoke(c.UserContext(), id, models.SessionRevokedLogout); err != nil {
+		return apperror.Wrap(err, "Failed to revoke session")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Session revoked",
+	})
+}
+
+// RevokeOtherSessions godoc
+// @Summary      Log out everywhere else
+// @Description  Revoke every session of the caller except the current one. Not allowed while impersonating.
+// @Tags         sessions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+	currentID, _ := c.Locals("sessionID").(int)
+
+	ids, err := h.sessions.RevokeOthers(c.UserContext(), userID, currentID, models.SessionRevokedOthers)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to revoke sessions")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Other sessions revoked",
+		"revoked": len(ids),
+	})
+}
+
+// GetUserSessions godoc
+// @Summary      List user sessions
+// @Description  List the active sessions of a user. The caller must hold every permission of the user's role.
+// @Tags         sessions
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.SessionResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /users/{id}/sessions [get]
+func (h *SessionHandler) GetUserSessions(c *fiber.Ctx) error {
+	user, err := h.targetUser(c)
+	if err != nil {
+		return err
+	}
+	return h.listSessions(c, user.ID)
+}
+
+// ForceLogout godoc
+// @Summary      Force logout user
+// @Description  Revoke every session of a user. The user's access and refresh tokens stop working immediately and they must log in again. The caller must hold every permission of the user's role.
+// @Tags         sessions
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /users/{id}/logout [post]
+func (h *SessionHandler) ForceLogout(c *fiber.Ctx) error {
+	user, err := h.targetUser(c)
+	if err != nil {
+		return err
+	}
+
+	ids, err := h.sessions.RevokeAll(c.UserContext(), user.ID, models.SessionRevokedAdmin)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to revoke sessions")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User logged out from all sessions",
+		"revoked": len(ids),
+	})
+}
+
+// targetUser mengambil user dari parameter :id. Seperti UpdateUser, pemanggil harus memiliki
+// seluruh permission role user tersebut
+func (h *SessionHandler) targetUser(c *fiber.Ctx) (*models.User, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, apperror.BadRequest("Invalid user ID format")
+	}
+
+	user, err := h.users.GetByID(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFound("User not found")
+		}
+		return nil, apperror.Wrap(err, "Failed to fetch user")
+	}
+	if err := requireRolePermissions(c, h.policy, user.Role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// listSessions menampilkan session aktif user dan menandai session request ini
+func (h *SessionHandler) listSessions(c *fiber.Ctx, userID int) error {
+	sessions, err := h.sessions.ListActive(c.UserContext(), userID)
+	if err != nil {
+		return apperror.Wrap(err, "Failed to fetch sessions")
+	}
+
+	currentID, _ := c.Locals("sessionID").(int)
+	response := make([]models.SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, models.SessionResponse{
+			ID:             s.ID,
+			UserAgent:      s.UserAgent,
+			IPAddress:      s.IPAddress,
+			CreatedAt:      s.CreatedAt,
+			LastUsedAt:     s.LastUsedAt,
+			ExpiresAt:      s.ExpiresAt,
+			AMR:            s.AMR,
+			ImpersonatorID: s.ImpersonatorID,
+			Current:        currentID != 0 && s.ID == currentID,
+		})
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"backend-go/internal/totp"
+	"backend-go/internal/validation"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// recoveryCodeCount jumlah kode pemulihan yang dibuat setiap kali 2FA diaktifkan atau kode diganti
+const recoveryCodeCount = 10
+
+type TOTPHandler struct {
+	users    repository.UserRepository
+	totp     repository.TOTPRepository
+	sessions repository.SessionRepository
+	logins   *AuthHandler
+	policy   *authz.Policy
+	auth     config.AuthConfig
+}
+
+// NewTOTPHandler memakai AuthHandler agar kode yang salah dihitung dalam lockout login
+func NewTOTPHandler(users repository.UserRepository, totpRepo repository.TOTPRepository, sessions repository.SessionRepository, logins *AuthHandler, policy *authz.Policy, authConfig config.AuthConfig) *TOTPHandler {
+	return &TOTPHandler{users: users, totp: totpRepo, sessions: sessions, logins: logins, policy: policy, auth: authConfig}
+}
+
+// GetTOTPStatus godoc
+// @Summary      Get two-factor status
+// @Description  Report whether two-factor authentication is enabled for the current user, whether their role requires it, and how many recovery codes are left
+// @Tags         two-factor
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TOTPStatusResponse
+// @Failure      401  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/2fa [get]
+func (h *TOTPHandler) GetTOTPStatus(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+	status := models.TOTPStatusResponse{Required: h.required(c)}
+
+	enrollment, err := h.totp.Get(c.UserContext(), userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(err, "Failed to fetch two-factor status")
+	}
+	if enrollment != nil && enrollment.ConfirmedAt != nil {
+		status.Enabled = true
+		if status.RecoveryCodesRemaining, err = h.totp.CountRecoveryCodes(c.UserContext(), userID); err != nil {
+			return apperror.Wrap(err, "Failed to fetch two-factor status")
+		}
+	}
+
+	return c.JSON(status)
+}
+
+// SetupTOTP godoc
+// @Summary      Start two-factor enrollment
+// @Description  Generate a new TOTP secret and its otpauth:// provisioning URI (render it as a QR code for an authenticator app). Enrollment becomes active after /me/2fa/confirm. Calling this again before confirming replaces the pending secret.
+// @Tags         two-factor
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.TOTPSetupResponse
+// @Failure      401  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/2fa/setup [post]
+func (h *TOTPHandler) SetupTOTP(c *fiber.Ctx) error {
+	user, err := h.users.GetByID(c.UserContext(), c.Locals("userID").(int))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User not found")
+		}
+		return apperror.Wrap(err, "Failed to fetch user")
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate secret")
+	}
+	if err := h.totp.Begin(c.UserContext(), user.ID, secret); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return apperror.Conflict("Two-factor authentication is already enabled")
+		}
+		return apperror.Wrap(err, "Failed to start two-factor enrollment")
+	}
+
+	return c.JSON(models.TOTPSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.URI(h.auth.TOTPIssuer, user.Username, secret),
+	})
+}
+
+// ConfirmTOTP godoc
+// @Summary      Confirm two-factor enrollment
+// @Description  Activate two-factor authentication with a code from the authenticator app. Returns recovery codes, shown only once. Log in again to obtain a token that satisfies roles requiring two-factor authentication.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.TOTPCodeRequest  true  "Current TOTP code"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.RecoveryCodesResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/2fa/confirm [post]
+func (h *TOTPHandler) ConfirmTOTP(c *fiber.Ctx) error {
+	var req models.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("userID").(int)
+	enrollment, err := h.totp.Get(c.UserContext(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.BadRequest("No pending two-factor enrollment, call /me/2fa/setup first")
+		}
+		return apperror.Wrap(err, "Failed to confirm two-factor enrollment")
+	}
+	if enrollment.ConfirmedAt != nil {
+		return apperror.Conflict("Two-factor authentication is already enabled")
+	}
+
+	step, ok := totp.Verify(enrollment.Secret, req.Code, time.Now())
+	if !ok {
+		return apperror.BadRequest("Invalid two-factor code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate recovery codes")
+	}
+	if err := h.totp.Confirm(c.UserContext(), userID, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.Conflict("Two-factor authentication is already enabled")
+		}
+		return apperror.Wrap(err, "Failed to confirm two-factor enrollment")
+	}
+
+	return c.JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate recovery codes
+// @Description  Replace all recovery codes with a new set, shown only once. Requires a current TOTP code. Wrong codes count as failed logins for the account.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.TOTPCodeRequest  true  "Current TOTP code"
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.RecoveryCodesResponse
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/2fa/recovery-codes [post]
+func (h *TOTPHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req models.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("userID").(int)
+	if err := h.checkCode(c, userID, req.Code); err != nil {
+		return err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return apperror.Wrap(err, "Failed to generate recovery codes")
+	}
+	if err := h.totp.ReplaceRecoveryCodes(c.UserContext(), userID, hashes); err != nil {
+		return apperror.Wrap(err, "Failed to save recovery codes")
+	}
+
+	return c.JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP godoc
+// @Summary      Disable two-factor authentication
+// @Description  Remove the authenticator and recovery codes of the current user. Requires a current TOTP code and is not allowed for roles that require two-factor authentication. Wrong codes count as failed logins for the account.
+// @Tags         two-factor
+// @Accept       json
+// @Param        request  body      models.TOTPCodeRequest  true  "Current TOTP code"
+// @Security     ApiKeyAuth
+// @Success      204  "No Content"
+// @Failure      400  {object}  apperror.Response
+// @Failure      401  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      429  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /me/2fa [delete]
+func (h *TOTPHandler) DisableTOTP(c *fiber.Ctx) error {
+	var req models.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest("Invalid request body")
+	}
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	if h.required(c) {
+		return apperror.Forbidden("Two-factor authentication is required for your role")
+	}
+
+	userID := c.Locals("userID").(int)
+	if err := h.checkCode(c, userID, req.Code); err != nil {
+		return err
+	}
+	if err := h.totp.Delete(c.UserContext(), userID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(err, "Failed to disable two-factor authentication")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResetUserTOTP godoc
+// @Summary      Reset user's two-factor authentication
+// @Description  Remove another user's authenticator and recovery codes, e.g. after a lost device, and sign out all of their sessions. The user logs in with their password only and enrolls again; roles that require two-factor authentication are limited to enrollment until then. The caller must hold every permission of the user's role, and your own two-factor authentication is disabled through /me/2fa.
+// @Tags         users
+// @Param        id   path      int  true  "User ID"
+// @Security     ApiKeyAuth
+// @Success      204  "No Content"
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
+// @Router       /users/{id}/2fa [delete]
+func (h *TOTPHandler) ResetUserTOTP(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest("Invalid user ID format")
+	}
+	// 2FA sendiri dinonaktifkan lewat /me/2fa yang meminta kode
+	if id == c.Locals("userID").(int) {
+		return apperror.BadRequest("Use /me/2fa to disable your own two-factor authentication")
+	}
+
+	user, err := h.users.GetByID(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User not found")
+		}
+		return apperror.Wrap(err, "Failed to fetch user")
+	}
+	// Me-reset 2FA akun yang lebih berkuasa sama dengan melewati faktor kedua akun tersebut
+	if err := requireRolePermissions(c, h.policy, user.Role); err != nil {
+		return err
+	}
+
+	if err := h.totp.Delete(c.UserContext(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User has no two-factor enrollment")
+		}
+		return apperror.Wrap(err, "Failed to reset two-factor authentication")
+	}
+
+	if _, err := h.sessions.RevokeAll(c.UserContext(), id, models.SessionRevokedTOTPReset); err != nil {
+		return apperror.Wrap(err, "Failed to revoke sessions")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// required true bila role user yang sedang login wajib memakai 2FA
+func (h *TOTPHandler) required(c *fiber.Ctx) bool {
+	role, _ := c.Locals("userRole").(models.UserRole)
+	return slices.Contains(h.auth.TOTPRequiredRoles, role)
+}
+
+// checkCode memverifikasi kode TOTP user yang 2FA-nya aktif; kode yang sama tidak dapat dipakai dua kali.
+// Percobaan melewati jeda dan lockout yang sama dengan /auth/2fa/verify, agar pemegang access token
+// curian tidak dapat menebak kode lalu mematikan 2FA atau membuat kode pemulihan baru.
+func (h *TOTPHandler) checkCode(c *fiber.Ctx, userID int, code string) error {
+	enrollment, err := h.totp.Get(c.UserContext(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.BadRequest("Two-factor authentication is not enabled")
+		}
+		return apperror.Wrap(err, "Failed to verify code")
+	}
+	if enrollment.ConfirmedAt == nil {
+		return apperror.BadRequest("Two-factor authentication is not enabled")
+	}
+
+	user, err := h.users.GetByID(c.UserContext(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("User not found")
+		}
+		return apperror.Wrap(err, "Failed to fetch user")
+	}
+	failures, err := h.logins.beginLogin(c, user.Username)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Verify(enrollment.Secret, code, time.Now())
+	if ok {
+		ok, err = h.totp.UseStep(c.UserContext(), userID, step)
+		if err != nil {
+			return apperror.Wrap(err, "Failed to verify code")
+		}
+	}
+	if !ok {
+		if err := h.logins.recordLoginFailure(c, user.Username, &user.ID, failures); err != nil {
+			return err
+		}
+		return apperror.BadRequest("Invalid two-factor code")
+	}
+
+	if err := h.logins.attempts.Clear(c.UserContext(), user.Username); err != nil {
+		return apperror.Wrap(err, "Failed to verify code")
+	}
+	return nil
+}
+
+// newRecoveryCodes membuat kode pemulihan berformat xxxxx-xxxxx beserta hash yang disimpan
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		// 10 karakter base32 = 50 bit, cukup untuk kode sekali pakai yang juga dibatasi lockout login
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode menerima kode dengan atau tanpa tanda hubung dan spasi, huruf besar atau kecil
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/validation"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// uploadURLPrefix prefix path publik yang disimpan di database dan disajikan lewat /uploads
+const uploadURLPrefix = "uploads"
+
+// maxUploadSlug panjang maksimal slug judul di nama file upload
+const maxUploadSlug = 50
+
+// allowedImageTypes ekstensi gambar yang boleh diupload
+var allowedImageTypes = []string{".jpg", ".jpeg", ".png", ".webp"}
+
+// imageExt memvalidasi ekstensi file gambar upload dan mengembalikannya dalam huruf kecil
+func imageExt(c *fiber.Ctx, file *multipart.FileHeader) (string, error) {
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !slices.Contains(allowedImageTypes, ext) {
+		return "", validation.Field(c, "image", "filetype", strings.Join(allowedImageTypes, " "))
+	}
+	return ext, nil
+}
+
+// uploadBaseName nama file asli tanpa direktori dan ekstensi, untuk dijadikan slug oleh uploadName
+func uploadBaseName(file *multipart.FileHeader) string {
+	name := filepath.Base(file.Filename)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// uploadStore memetakan path publik "uploads/..." ke direktori upload yang dikonfigurasi
+type uploadStore struct {
+	root  string
+	tasks *background.Group
+}
+
+func newUploadStore(cfg config.UploadConfig, tasks *background.Group) uploadStore {
+	return uploadStore{root: cfg.Root, tasks: tasks}
+}
+
+// dir memastikan subdirektori upload tersedia dan mengembalikan path-nya di disk
+func (s uploadStore) dir(sub string) (string, error) {
+	dir := filepath.Join(s.root, filepath.FromSlash(sub))
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// save menyimpan file upload di subdirektori sub dan mengembalikan path publiknya. Nama file
+// dibentuk oleh uploadName dan ditulis lewat diskPath, sehingga judul dari request tidak
+// dapat membawa file keluar dari root upload.
+func (s uploadStore) save(c *fiber.Ctx, file *multipart.FileHeader, sub, name, ext string) (string, error) {
+	if _, err := s.dir(sub); err != nil {
+		return "", err
+	}
+	publicPath := s.publicPath(sub, uploadName(name, ext))
+	if err := c.SaveFile(file, s.diskPath(publicPath)); err != nil {
+		return "", err
+	}
+	return publicPath, nil
+}
+
+// uploadName menyusun nama file unik dari timestamp dan slug name. Slug hanya berisi huruf,
+// angka dan "-", sehingga pemisah path maupun ".." tidak pernah ikut ke nama file.
+func uploadName(name, ext string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range name {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+			if slug.Len() >= maxUploadSlug {
+				break
+			}
+			continue
+		}
+		dash = true
+	}
+	if slug.Len() == 0 {
+		return fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
+	}
+	return fmt.Sprintf("%d-%s%s", time.Now().UnixNano(), slug.String(), ext)
+}
+
+// publicPath menyusun path publik untuk file yang tersimpan di subdirektori upload
+func (s uploadStore) publicPath(sub, filename string) string {
+	return path.Join(uploadURLPrefix, sub, filename)
+}
+
+// diskPath mengubah path publik menjadi path file di bawah root upload
+func (s uploadStore) diskPath(publicPath string) string {
+	rel := strings.TrimPrefix(publicPath, "/")
+	rel = strings.TrimPrefix(rel, uploadURLPrefix+"/")
+	// Clean dengan prefix "/" agar path tidak bisa keluar dari root
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+rel)))
+}
+
+// remove menghapus file berdasarkan path publik
+func (s uploadStore) remove(publicPath string) error {
+	if publicPath == "" {
+		return nil
+	}
+	return os.Remove(s.diskPath(publicPath))
+}
+
+// removeLater menghapus file di background; shutdown menunggu sampai penghapusan selesai
+func (s uploadStore) removeLater(publicPath string) {
+	if publicPath == "" {
+		return
+	}
+	s.tasks.Go(func() {
+		if err := s.remove(publicPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to delete upload %s: %v", publicPath, err)
+		}
+	})
+}
@@ -1,40 +1,46 @@
 package handlers
 
 import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/config"
+	"backend-go/internal/middleware"
 	"backend-go/internal/models"
-	"context"
-	"fmt"
+	"backend-go/internal/password"
+	"backend-go/internal/repository"
+	"backend-go/internal/validation"
+	"errors"
 	"math"
-	"regexp"
 	"strconv"
-	"strings"
-	"time"
-	"unicode"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserHandler struct {
-	db *pgxpool.Pool
+	users    repository.UserRepository
+	invites  repository.InviteRepository
+	sessions repository.SessionRepository
+	apiKeys  repository.APIKeyRepository
+	policy   *authz.Policy
+	auth     config.AuthConfig
 }
 
-func NewUserHandler(db *pgxpool.Pool) *UserHandler {
-	return &UserHandler{db: db}
+func NewUserHandler(users repository.UserRepository, invites repository.InviteRepository, sessions repository.SessionRepository, apiKeys repository.APIKeyRepository, policy *authz.Policy, authConfig config.AuthConfig) *UserHandler {
+	return &UserHandler{users: users, invites: invites, sessions: sessions, apiKeys: apiKeys, policy: policy, auth: authConfig}
 }
 
 // CreateUser membuat user baru
 // @Summary      Create new user
-// @Description  Create new user account
+// @Description  Create new user account. The caller must hold every permission of the new user's role.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        user  body      models.CreateRequest  true  "User Data"
+// @Security     ApiKeyAuth
 // @Success      201  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /users [post]
 func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
     createdBy := c.Locals("userID").(int)
@@ -42,22 +48,22 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 	
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apperror.BadRequest("Invalid request body")
 	}
 
 	// Validasi input
-	if validationErr := validateUserInput(req); validationErr != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(validationErr)
+	if err := validation.Validate(c, &req); err != nil {
+		return err
+	}
+
+	if err := checkPassword(c, h.auth, "password", req.Password, req.Username, req.Phone); err != nil {
+		return err
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := password.Hash(req.Password, h.auth.BcryptCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process password",
-		})
+		return apperror.Wrap(err, "Failed to process password")
 	}
 
 	// Set default role jika kosong
@@ -65,80 +71,40 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 		req.Role = models.RoleUser
 	}
 
-	// Eksekusi query
-	query := `
-		INSERT INTO users (
-			name, 
-			phone, 
-			username, 
-			password, 
-			role,
-			created_by
-		) VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id
-	`
-
-	var userID int
-	err = h.db.QueryRow(context.Background(), query,
-        req.Name,
-        req.Phone,
-        req.Username,
-        string(hashedPassword),
-        req.Role,
-        createdBy,
-    ).Scan(&userID)
+	// Akun baru tidak boleh lebih berkuasa dari pembuatnya
+	if err := requireRolePermissions(c, h.policy, req.Role); err != nil {
+		return err
+	}
+
+	// Simpan ke database
+	user := models.User{
+		Name:           req.Name,
+		Phone:          req.Phone,
+		Username:       req.Username,
+		Password:       string(hashedPassword),
+		Role:           req.Role,
+		CreatedBy:      &createdBy,
+		ImpersonatedBy: middleware.Impersonator(c),
+	}
+	err = h.users.Create(c.UserContext(), &user)
 
 	if err != nil {
 		// Handle unique constraint violation
-		if isUniqueConstraintViolation(err) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "Username or phone number already exists",
-			})
+		if errors.Is(err, repository.ErrDuplicate) {
+			return apperror.Conflict("Username or phone number already exists")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create user",
-		})
+		return apperror.Wrap(err, "Failed to create user")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"id":      userID,
+		"id":      user.ID,
 		"message": "User created successfully",
 	})
 }
 
-func validateUserInput(req models.CreateRequest) fiber.Map {
-	errors := make(fiber.Map)
-	
-	if len(req.Name) < 3 {
-		errors["name"] = "Name must be at least 3 characters"
-	}
-	
-	if req.Phone == "" {
-		errors["phone"] = "Phone number is required"
-	}
-	
-	if req.Username == "" {
-		errors["username"] = "Username is required"
-	}
-	
-	if len(req.Password) < 8 {
-		errors["password"] = "Password must be at least 8 characters"
-	}
-	
-	if len(errors) > 0 {
-		return fiber.Map{"errors": errors}
-	}
-	return nil
-}
-
-func isUniqueConstraintViolation(err error) bool {
-	// Error code 23505 adalah unique_violation di PostgreSQL
-	return err.Error()[0:5] == "ERROR" && err.Error()[6:10] == "23505"
-}
-
 // UpdateUser godoc
 // @Summary      Update user data
-// @Description  Update existing user's information
+// @Description  Update existing user's information. Setting status to false signs the user out of every session and revokes their API keys, and a new password signs them out of every session; role changes apply to the user's next request. Managing another user requires every permission of both their current and new role, and your own role cannot be changed. Users change their own profile and password through /me and /me/password instead.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -146,74 +112,120 @@ func isUniqueConstraintViolation(err error) bool {
 // @Param        user body      models.UpdateRequest true  "User Data"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      403  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
     userID := c.Params("id")
     targetID, err := strconv.Atoi(userID)
     if err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid user ID format",
-        })
+        return apperror.BadRequest("Invalid user ID format")
     }
 
     // Dapatkan ID user yang melakukan request dari JWT
     requesterID := c.Locals("userID").(int)
-    requesterRole := c.Locals("userRole").(models.UserRole)
 
-    // Authorization check
-    if requesterRole != models.RoleAdmin && requesterID != targetID {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-            "error": "You can only update your own profile",
-        })
+    // User boleh mengubah profilnya sendiri; user lain butuh users:update
+    canManage := middleware.Can(c, h.policy, authz.UsersUpdate)
+    if !canManage && requesterID != targetID {
+        return apperror.PermissionDenied(string(authz.UsersUpdate))
     }
 
     var req models.UpdateRequest
     if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+        return apperror.BadRequest("Invalid request body")
     }
 
     // Validasi input
-    if validationErr := validateUpdateRequest(req); validationErr != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(validationErr)
+    if err := validation.Validate(c, &req); err != nil {
+        return err
+    }
+
+    // Password sendiri hanya bisa diganti lewat /me/password yang meminta password lama
+    if req.Password != "" && requesterID == targetID {
+        return apperror.BadRequest("Use /me/password to change your own password")
+    }
+
+    // Role sendiri tidak dapat diubah, termasuk oleh pemilik users:update
+    if req.Role != "" && requesterID == targetID {
+        return apperror.Forbidden("You cannot change your own role")
+    }
+
+    if err := checkUserConflicts(c, h.users, targetID, req.Username, req.Phone); err != nil {
+        return err
+    }
+
+    var target *models.User
+    if requesterID != targetID || req.Password != "" {
+        target, err = h.users.GetByID(c.UserContext(), targetID)
+        if err != nil {
+            if errors.Is(err, repository.ErrNotFound) {
+                return apperror.NotFound("User not found")
+            }
+            return apperror.Wrap(err, "Failed to fetch user")
+        }
+    }
+
+    // Mengelola user lain butuh seluruh permission role-nya saat ini dan role barunya,
+    // agar pemilik users:update tidak dapat mengambil alih atau membuat akun yang lebih berkuasa
+    if requesterID != targetID {
+        if err := requireRolePermissions(c, h.policy, target.Role); err != nil {
+            return err
+        }
+        if req.Role != "" {
+            if err := requireRolePermissions(c, h.policy, req.Role); err != nil {
+                return err
+            }
+        }
     }
 
     // Hash password jika diupdate
     var hashedPassword string
     if req.Password != "" {
-        hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+        // Data pribadi yang ikut diubah di request yang sama juga diperiksa
+        if err := checkPassword(c, h.auth, "password", req.Password, target.Username, target.Phone, req.Username, req.Phone); err != nil {
+            return err
+        }
+
+        hashedPassword, err = password.Hash(req.Password, h.auth.BcryptCost)
         if err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "Failed to process password",
-            })
+            return apperror.Wrap(err, "Failed to process password")
         }
-        hashedPassword = string(hash)
     }
 
-    // Build dynamic query
-    query, args := buildUpdateQuery(req, hashedPassword, requesterID, targetID, requesterRole)
-    
-    result, err := h.db.Exec(context.Background(), query, args...)
+    // Susun perubahan data
+    update := buildUserUpdate(req, hashedPassword, requesterID, canManage)
+    update.ImpersonatedBy = middleware.Impersonator(c)
+
+    err = h.users.Update(c.UserContext(), targetID, update)
     if err != nil {
-        if isUniqueConstraintViolation(err) {
-            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-                "error": "Username or phone number already exists",
-            })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("User not found")
         }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to update user",
-        })
+        if errors.Is(err, repository.ErrDuplicate) {
+            return apperror.Conflict("Username or phone number already exists")
+        }
+        return apperror.Wrap(err, "Failed to update user")
     }
 
-    if result.RowsAffected() == 0 {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-            "error": "User not found",
-        })
+    // User yang dinonaktifkan kehilangan seluruh session dan API key-nya
+    if update.Status != nil && !*update.Status {
+        if _, err := h.sessions.RevokeAll(c.UserContext(), targetID, models.SessionRevokedDeactivated); err != nil {
+            return apperror.Wrap(err, "Failed to revoke user sessions")
+        }
+        if _, err := h.apiKeys.RevokeByCreator(c.UserContext(), targetID); err != nil {
+            return apperror.Wrap(err, "Failed to revoke user API keys")
+        }
+    }
+
+    // Password yang diganti admin membatalkan session lama, sama seperti reset password
+    if hashedPassword != "" {
+        if _, err := h.sessions.RevokeAll(c.UserContext(), targetID, models.SessionRevokedPasswordReset); err != nil {
+            return apperror.Wrap(err, "Failed to revoke user sessions")
+        }
     }
 
     return c.JSON(fiber.Map{
@@ -221,102 +233,34 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
     })
 }
 
-func validateUpdateRequest(req models.UpdateRequest) fiber.Map {
-    errors := make(fiber.Map)
-    
-    if req.Name != "" && len(req.Name) < 3 {
-        errors["name"] = "Name must be at least 3 characters"
+// buildUserUpdate menyusun perubahan user; role dan status hanya boleh diubah oleh pemilik users:update
+func buildUserUpdate(req models.UpdateRequest, hashedPassword string, editedBy int, canManage bool) repository.UserUpdate {
+    update := repository.UserUpdate{
+        Name:     req.Name,
+        Phone:    req.Phone,
+        Username: req.Username,
+        Password: hashedPassword,
+        EditedBy: editedBy,
     }
-    
-    if req.Phone != "" {
-        if !isValidPhone(req.Phone) {
-            errors["phone"] = "Invalid phone number format"
-        }
-    }
-    
-    if req.Username != "" && !isAlphanumeric(req.Username) {
-        errors["username"] = "Username must be alphanumeric"
-    }
-    
-    if req.Password != "" && len(req.Password) < 8 {
-        errors["password"] = "Password must be at least 8 characters"
-    }
-    
-    if len(errors) > 0 {
-        return fiber.Map{"errors": errors}
-    }
-    return nil
-}
-
-func buildUpdateQuery(req models.UpdateRequest, hashedPassword string, editedBy, targetID int, requestRole models.UserRole) (string, []interface{}) {
-    var query strings.Builder
-    args := make([]interface{}, 0)
-    counter := 1
 
-    query.WriteString("UPDATE users SET ")
-    
-    if req.Name != "" {
-        query.WriteString(fmt.Sprintf("name = $%d, ", counter))
-        args = append(args, req.Name)
-        counter++
-    }
-    
-    if req.Phone != "" {
-        query.WriteString(fmt.Sprintf("phone = $%d, ", counter))
-        args = append(args, req.Phone)
-        counter++
-    }
-    
-    if req.Username != "" {
-        query.WriteString(fmt.Sprintf("username = $%d, ", counter))
-        args = append(args, req.Username)
-        counter++
-    }
-    
-    if hashedPassword != "" {
-        query.WriteString(fmt.Sprintf("password = $%d, ", counter))
-        args = append(args, hashedPassword)
-        counter++
+    if canManage {
+        update.Role = req.Role
+        update.Status = req.Status
     }
-    
-    if req.Role != "" && requestRole == models.RoleAdmin { // Gunakan requesterRole
-        query.WriteString(fmt.Sprintf("role = $%d, ", counter))
-        args = append(args, req.Role)
-        counter++
-    }
-    
-    if req.Status != nil && requestRole == models.RoleAdmin { // Gunakan requesterRole
-        query.WriteString(fmt.Sprintf("status = $%d, ", counter))
-        args = append(args, *req.Status)
-        counter++
-    }
-    
-    // Update edited_by dan edited_at
-    query.WriteString(fmt.Sprintf(
-        "edited_by = $%d ",
-        counter,
-    ))
-    args = append(args, editedBy)
-    counter++
-    
-    query.WriteString(fmt.Sprintf("WHERE id = $%d", counter))
-    args = append(args, targetID)
-    
-    return query.String(), args
+
+    return update
 }
 
-func isAlphanumeric(s string) bool {
-    for _, r := range s {
-        if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
-            return false
+// requireRolePermissions menolak request bila pemanggil tidak memiliki seluruh permission role,
+// karena matriks permission dapat memberi users:create, users:update, atau invites:create
+// kepada role selain admin
+func requireRolePermissions(c *fiber.Ctx, policy *authz.Policy, role models.UserRole) error {
+    for _, perm := range policy.Permissions(role) {
+        if !middleware.Can(c, policy, perm) {
+            return apperror.PermissionDenied(string(perm))
         }
     }
-    return true
-}
-
-func isValidPhone(phone string) bool {
-    // Implementasi validasi nomor telepon sesuai kebutuhan
-    return strings.HasPrefix(phone, "+") && len(phone) > 8
+    return nil
 }
 
 // GetUsers godoc
@@ -331,18 +275,11 @@ func isValidPhone(phone string) bool {
 // @Param        status  query     bool     false  "Filter by status"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /users [get]
 func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
-    // Authorization - hanya admin yang bisa akses
-    requesterRole := c.Locals("userRole").(models.UserRole)
-    if requesterRole != models.RoleAdmin {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-            "error": "Admin access required",
-        })
-    }
-
     // Parse query parameters
     page, _ := strconv.Atoi(c.Query("page", "1"))
     limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -358,88 +295,21 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
     }
     offset := (page - 1) * limit
 
-    // Build query
-    query := `SELECT 
-                id, name, phone, username, role, status, 
-                created_at, created_by, edited_at, edited_by 
-              FROM users 
-              WHERE deleted_at IS NULL`
-    args := []interface{}{}
-    paramCounter := 1
-
-    // Filter role
-    if role != "" {
-        query += fmt.Sprintf(" AND role = $%d", paramCounter)
-        args = append(args, role)
-        paramCounter++
+    filter := repository.UserFilter{
+        Role: role,
+        Page: repository.Page{Limit: limit, Offset: offset},
     }
 
     // Filter status
     if status != "" {
-        query += fmt.Sprintf(" AND status = $%d", paramCounter)
-        args = append(args, status == "true")
-        paramCounter++
+        statusBool := status == "true"
+        filter.Status = &statusBool
     }
 
-    // Add pagination
-    query += fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", paramCounter, paramCounter+1)
-    args = append(args, limit, offset)
-
     // Eksekusi query
-    rows, err := h.db.Query(context.Background(), query, args...)
+    users, total, err := h.users.List(c.UserContext(), filter)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to fetch users",
-        })
-    }
-    defer rows.Close()
-
-    var users []models.UserResponse
-    for rows.Next() {
-        var user models.UserResponse
-        err := rows.Scan(
-            &user.ID,
-            &user.Name,
-            &user.Phone,
-            &user.Username,
-            &user.Role,
-            &user.Status,
-            &user.CreatedAt,
-            &user.CreatedBy,
-            &user.EditedAt,
-            &user.EditedBy,
-        )
-        if err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "Failed to parse user data",
-            })
-        }
-        users = append(users, user)
-    }
-
-    // Get total count
-    countQuery := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
-    countArgs := []interface{}{}
-    paramCounter = 1
-
-    if role != "" {
-        countQuery += fmt.Sprintf(" AND role = $%d", paramCounter)
-        countArgs = append(countArgs, role)
-        paramCounter++
-    }
-
-    if status != "" {
-        countQuery += fmt.Sprintf(" AND status = $%d", paramCounter)
-        countArgs = append(countArgs, status == "true")
-        paramCounter++
-    }
-
-    var total int
-    err = h.db.QueryRow(context.Background(), countQuery, countArgs...).Scan(&total)
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to get total users",
-        })
+        return apperror.Wrap(err, "Failed to fetch users")
     }
 
     return c.JSON(fiber.Map{
@@ -455,65 +325,60 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 
 // DeleteUser godoc
 // @Summary      Delete a user (soft delete)
-// @Description  Mark user as deleted by setting deleted_at timestamp
+// @Description  Mark user as deleted by setting deleted_at timestamp and revoke all of the user's sessions and API keys. The caller must hold every permission of the user's role.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "User ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      403  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /users/{id} [delete]
 func (h * UserHandler) DeleteUser(c * fiber.Ctx) error {
     // Dapatkan ID user target
     userID := c.Params("id")
     targetID, err := strconv.Atoi(userID)
     if err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid user ID format",
-        })
+        return apperror.BadRequest("Invalid user ID format")
     }
 
     // Dapatkan ID user yang melakukan request
     adminID := c.Locals("userID").(int)
-    adminRole := c.Locals("userRole").(models.UserRole)
-
-    // Authorization: hanya admin yang bisa menghapus user
-    if adminRole != models.RoleAdmin {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-            "error": "Admin access required",
-        })
-    }
 
     // Cegah admin menghapus dirinya sendiri
     if targetID == adminID {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-            "error": "Admin cannot delete their own account",
-        })
+        return apperror.Forbidden("Admin cannot delete their own account")
     }
 
-    // Soft delete user
-    query := `
-        UPDATE users 
-        SET deleted_at = $1, deleted_by = $2 
-        WHERE id = $3 AND deleted_at IS NULL
-    `
-
-    result, err := h.db.Exec(context.Background(), query, time.Now().UTC(), adminID, targetID)
+    // Seperti UpdateUser, akun dengan role yang lebih berkuasa dari pemanggil tidak dapat dihapus
+    target, err := h.users.GetByID(c.UserContext(), targetID)
+    if err != nil {
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("User not found or already deleted")
+        }
+        return apperror.Wrap(err, "Failed to fetch user")
+    }
+    if err := requireRolePermissions(c, h.policy, target.Role); err != nil {
+        return err
+    }
 
+    // Soft delete user
+    err = h.users.SoftDelete(c.UserContext(), targetID, adminID, middleware.Impersonator(c))
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to delete user",
-        })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("User not found or already deleted")
+        }
+        return apperror.Wrap(err, "Failed to delete user")
     }
 
-    if result.RowsAffected() == 0 {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-            "error": "User not found or already deleted",
-        })
+    if _, err := h.sessions.RevokeAll(c.UserContext(), targetID, models.SessionRevokedDeleted); err != nil {
+        return apperror.Wrap(err, "Failed to revoke user sessions")
+    }
+    if _, err := h.apiKeys.RevokeByCreator(c.UserContext(), targetID); err != nil {
+        return apperror.Wrap(err, "Failed to revoke user API keys")
     }
 
     return c.JSON(fiber.Map{
@@ -523,160 +388,71 @@ func (h * UserHandler) DeleteUser(c * fiber.Ctx) error {
 
 // RegisterUser godoc
 // @Summary      Register new user
-// @Description  Create user account for public registration
+// @Description  Create a user account. Without an invite code the account always gets the user role, and public registration can be disabled per deployment. With an invite code the account gets the role bound to the invite.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request  body      models.RegisterRequest  true  "Registration data"
 // @Success      201  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]string
-// @Failure      409  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      409  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /register [post]
 func (h *UserHandler) RegisterUser(c *fiber.Ctx) error {
     var req models.RegisterRequest
     
     // Parse request body
     if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+        return apperror.BadRequest("Invalid request body")
     }
 
     // Validasi input khusus registrasi
-    if validationErr := validateRegistrationInput(req); validationErr != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(validationErr)
+    if err := validation.Validate(c, &req); err != nil {
+        return err
     }
 
-    // Hash password
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to process password",
-        })
-    }
-
-    // Set default role untuk registrasi publik
-    if req.Role == "" {
-        req.Role = string(models.RoleAdmin)
+    if err := checkPassword(c, h.auth, "password", req.Password, req.Username, req.Phone); err != nil {
+        return err
     }
 
-    // Eksekusi query
-    query := `
-        INSERT INTO users (
-            name, 
-            phone, 
-            username, 
-            password, 
-            role
-        ) VALUES ($1, $2, $3, $4, $5)
-        RETURNING id
-    `
-
-    var userID int
-    err = h.db.QueryRow(context.Background(), query,
-        req.Name,
-        req.Phone,
-        req.Username,
-        string(hashedPassword),
-        req.Role,
-    ).Scan(&userID)
-
+    // Hash password
+    hashedPassword, err := password.Hash(req.Password, h.auth.BcryptCost)
     if err != nil {
-        if isUniqueConstraintViolation(err) {
-            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-                "error": "Username or phone number already exists",
-            })
-        }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to create user",
-        })
+        return apperror.Wrap(err, "Failed to process password")
     }
 
-    return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-        "id":      userID,
-        "message": "User registered successfully",
-    })
-}
-
-func validateRegistrationInput(req models.RegisterRequest) fiber.Map {
-    errors := make(map[string]string)
-    
-    // Validasi Nama
-    req.Name = strings.TrimSpace(req.Name)
-    if req.Name == "" {
-        errors["name"] = "Nama harus diisi"
-    } else if len(req.Name) < 3 {
-        errors["name"] = "Nama minimal 3 karakter"
-    } else if len(req.Name) > 100 {
-        errors["name"] = "Nama maksimal 100 karakter"
-    }
-
-    // Validasi Nomor Telepon
-    req.Phone = strings.TrimSpace(req.Phone)
-    if req.Phone == "" {
-        errors["phone"] = "Nomor telepon harus diisi"
-    } else {
-        // Cek apakah numeric
-        if _, err := strconv.Atoi(req.Phone); err != nil {
-            errors["phone"] = "Nomor telepon harus angka"
-        } else if len(req.Phone) < 10 {
-            errors["phone"] = "Nomor telepon minimal 10 digit"
-        } else if len(req.Phone) > 15 {
-            errors["phone"] = "Nomor telepon maksimal 15 digit"
-        }
+    // Registrasi publik selalu ber-role user; role lain hanya lewat undangan
+    user := models.User{
+        Name:     req.Name,
+        Phone:    req.Phone,
+        Username: req.Username,
+        Password: string(hashedPassword),
+        Role:     models.RoleUser,
     }
 
-    // Validasi Username
-    req.Username = strings.TrimSpace(req.Username)
-    if req.Username == "" {
-        errors["username"] = "Username harus diisi"
-    } else if len(req.Username) < 5 {
-        errors["username"] = "Username minimal 5 karakter"
-    } else if len(req.Username) > 50 {
-        errors["username"] = "Username maksimal 50 karakter"
+    if req.InviteCode != "" {
+        err = h.invites.Redeem(c.UserContext(), hashToken(req.InviteCode), &user)
+    } else if !h.auth.PublicRegistration {
+        return apperror.Forbidden("Public registration is disabled, an invite code is required")
     } else {
-        // Cek format username (hanya huruf, angka, dan underscore)
-        matched, _ := regexp.MatchString(`^[a-zA-Z0-9_]+$`, req.Username)
-        if !matched {
-            errors["username"] = "Username hanya boleh mengandung huruf, angka, dan underscore"
-        }
+        err = h.users.Create(c.UserContext(), &user)
     }
 
-    // Validasi Password
-    req.Password = strings.TrimSpace(req.Password)
-    if req.Password == "" {
-        errors["password"] = "Password harus diisi"
-    } else if len(req.Password) < 8 {
-        errors["password"] = "Password minimal 8 karakter"
-    } else if len(req.Password) > 72 {
-        errors["password"] = "Password maksimal 72 karakter"
-    } else {
-        // Cek kompleksitas password
-        var (
-            hasUpper  = regexp.MustCompile(`[A-Z]`).MatchString(req.Password)
-            hasLower  = regexp.MustCompile(`[a-z]`).MatchString(req.Password)
-            hasNumber = regexp.MustCompile(`[0-9]`).MatchString(req.Password)
-        )
-        
-        if !hasUpper {
-            errors["password"] = "Password harus mengandung minimal 1 huruf besar"
-        }
-        if !hasLower {
-            errors["password"] = "Password harus mengandung minimal 1 huruf kecil"
+    if err != nil {
+        if errors.Is(err, repository.ErrInviteInvalid) {
+            return apperror.BadRequest("Invite code is invalid, expired, or already used")
         }
-        if !hasNumber {
-            errors["password"] = "Password harus mengandung minimal 1 angka"
+        if errors.Is(err, repository.ErrDuplicate) {
+            return apperror.Conflict("Username or phone number already exists")
         }
+        return apperror.Wrap(err, "Failed to create user")
     }
 
-    if len(errors) > 0 {
-        return fiber.Map{
-            "error":   "Validasi gagal",
-            "details": errors,
-        }
-    }
-    return nil
+    return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+        "id":      user.ID,
+        "message": "User registered successfully",
+    })
 }
 
 // GetUserByID godoc
@@ -688,55 +464,33 @@ func validateRegistrationInput(req models.RegisterRequest) fiber.Map {
 // @Param        id   path      int  true  "User ID"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  models.UserResponse
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Failure      500  {object}  map[string]string
+// @Failure      400  {object}  apperror.Response
+// @Failure      403  {object}  apperror.Response
+// @Failure      404  {object}  apperror.Response
+// @Failure      500  {object}  apperror.Response
 // @Router       /users/{id} [get]
 func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
     // Parse ID dari parameter URL
     userID := c.Params("id")
     id, err := strconv.Atoi(userID)
     if err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid user ID format",
-        })
+        return apperror.BadRequest("Invalid user ID format")
+    }
+
+    // User boleh melihat datanya sendiri; user lain butuh users:read
+    if id != c.Locals("userID").(int) && !middleware.Can(c, h.policy, authz.UsersRead) {
+        return apperror.PermissionDenied(string(authz.UsersRead))
     }
 
     // Query ke database
-    query := `
-        SELECT 
-            id,
-            name,
-            username,
-            phone,
-            role,
-            created_at,
-            created_by
-        FROM users 
-        WHERE id = $1 AND deleted_at IS NULL
-    `
-    
-    var user models.UserResponse
-    err = h.db.QueryRow(context.Background(), query, id).Scan(
-        &user.ID,
-        &user.Name,
-        &user.Username,
-        &user.Phone,
-        &user.Role,
-        &user.CreatedAt,
-        &user.CreatedBy,
-    )
+    user, err := h.users.GetByID(c.UserContext(), id)
 
     if err != nil {
-        if err == pgx.ErrNoRows {
-            return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-                "error": "User not found",
-            })
+        if errors.Is(err, repository.ErrNotFound) {
+            return apperror.NotFound("User not found")
         }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to fetch user: " + err.Error(),
-        })
+        return apperror.Wrap(err, "Failed to fetch user")
     }
 
-    return c.JSON(user)
+    return c.JSON(user.Response())
 }
\ No newline at end of file
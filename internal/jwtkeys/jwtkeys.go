@@ -0,0 +1,284 @@
+// Package jwtkeys kunci penandatanganan dan verifikasi JWT. Satu kunci dipakai untuk
+// menandatangani, kunci lain disimpan untuk verifikasi sehingga kunci dapat dirotasi tanpa
+// membatalkan token yang sudah terbit. Setiap token membawa header kid untuk memilih kuncinya.
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits ukuran minimal kunci RSA yang diterima
+const minRSABits = 2048
+
+// Key satu kunci JWT. Kunci asimetris memiliki ID (thumbprint RFC 7638) yang dikirim sebagai kid;
+// secret HS256 tidak memiliki ID dan tidak pernah dipublikasikan lewat JWKS.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// FromSecret membuat kunci HS256 dari secret bersama
+func FromSecret(secret string) *Key {
+	return &Key{Method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}
+}
+
+// LoadFile membaca kunci PEM dari file, lihat ParsePEM
+func LoadFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ParsePEM membaca private key (PKCS#8 atau PKCS#1) yang dapat menandatangani, atau public key
+// (PKIX) yang hanya dapat memverifikasi. RSA dipakai dengan RS256, Ed25519 dengan EdDSA.
+func ParsePEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+	if pub, ok := key.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+	}
+
+	key.ID = thumbprint(key.JWK())
+	return key, nil
+}
+
+// CanSign true bila kunci memiliki bagian privat
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// JWK representasi publik kunci asimetris (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (OKP)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS dokumen /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK bagian publik kunci; kosong (Kty "") untuk secret HS256
+func (k *Key) JWK() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty, jwk.N, jwk.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv, jwk.X = "OKP", "Ed25519", b64(pub)
+	default:
+		return JWK{}
+	}
+	return jwk
+}
+
+// FromJWK membuat kunci verifikasi dari JWK publik, misalnya dari JWKS identity provider.
+// ID kunci mengikuti kid pada JWK bila ada.
+func FromJWK(jwk JWK) (*Key, error) {
+	b64 := base64.RawURLEncoding.DecodeString
+	key := &Key{}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := b64(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := b64(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		key.Method, key.public = jwt.SigningMethodRS256, pub
+	case "OKP":
+		x, err := b64(jwk.X)
+		if jwk.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		key.Method, key.public = jwt.SigningMethodEdDSA, ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q, use RSA or Ed25519", jwk.Kty)
+	}
+	if jwk.Alg != "" && jwk.Alg != key.Method.Alg() {
+		return nil, fmt.Errorf("unsupported algorithm %q for key type %s", jwk.Alg, jwk.Kty)
+	}
+
+	key.ID = jwk.Kid
+	if key.ID == "" {
+		key.ID = thumbprint(key.JWK())
+	}
+	return key, nil
+}
+
+// Verify memverifikasi token dengan kunci ini saja; algoritma token harus sama dengan algoritma kunci
+func (k *Key) Verify(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	opts = append([]jwt.ParserOption{jwt.WithValidMethods([]string{k.Method.Alg()})}, opts...)
+	return jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return k.public, nil
+	}, opts...)
+}
+
+// thumbprint JWK thumbprint RFC 7638: SHA-256 dari member wajib dengan urutan leksikografis
+func thumbprint(jwk JWK) string {
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Set kunci penandatangan ditambah kunci verifikasi, diindeks berdasarkan kid
+type Set struct {
+	signing *Key
+	keys    map[string]*Key
+	methods []string
+}
+
+// NewSet membuat Set. Kunci penandatangan selalu ikut dipakai untuk verifikasi; kunci
+// verifikasi berisi kunci lama (atau kunci berikutnya) selama masa rotasi.
+func NewSet(signing *Key, verification ...*Key) (*Set, error) {
+	if !signing.CanSign() {
+		return nil, errors.New("signing key must be a private key")
+	}
+
+	s := &Set{signing: signing, keys: make(map[string]*Key)}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if other, ok := s.keys[key.ID]; ok && other != key {
+			if key.ID == "" {
+				return nil, errors.New("only one HS256 secret can be configured")
+			}
+			continue
+		}
+		s.keys[key.ID] = key
+		if !slices.Contains(s.methods, key.Method.Alg()) {
+			s.methods = append(s.methods, key.Method.Alg())
+		}
+	}
+	return s, nil
+}
+
+// Sign menandatangani claims dengan kunci penandatangan dan mengisi header kid
+func (s *Set) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	if s.signing.ID != "" {
+		token.Header["kid"] = s.signing.ID
+	}
+	return token.SignedString(s.signing.private)
+}
+
+// Parse memverifikasi token dengan kunci sesuai kid; token tanpa kid hanya cocok dengan secret
+// HS256. Algoritma token harus sama dengan algoritma kunci.
+func (s *Set) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	opts = append([]jwt.ParserOption{jwt.WithValidMethods(s.methods)}, opts...)
+	return jwt.ParseWithClaims(tokenString, claims, s.keyfunc, opts...)
+}
+
+func (s *Set) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("kid %q does not use %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWKS kunci publik asimetris untuk diverifikasi layanan lain; secret HS256 tidak disertakan
+func (s *Set) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	// Kunci penandatangan di urutan pertama, sisanya mengikuti urutan kid agar stabil
+	ids := []string{s.signing.ID}
+	for id := range s.keys {
+		if id != s.signing.ID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids[1:])
+	for _, id := range ids {
+		if jwk := s.keys[id].JWK(); jwk.Kty != "" {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyPrefix awalan setiap API key, membedakannya dari JWT pada header Authorization
+const APIKeyPrefix = "bck_"
+
+// apiKeyTouchInterval jarak minimal antar pembaruan last_used_at agar tidak menulis ke database di setiap request
+const apiKeyTouchInterval = time.Minute
+
+// HashAPIKey hash SHA-256 key lengkap yang disimpan di database
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyFromRequest mengambil API key dari X-API-Key atau Authorization: Bearer bck_...
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	if key := c.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token := ExtractToken(c.Get(fiber.HeaderAuthorization)); strings.HasPrefix(token, APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+// authenticateAPIKey menjalankan request atas nama pembuat key tanpa role; permission berasal
+// dari scope key yang masih dimiliki role pembuatnya saat ini (lihat Can). Key milik user yang
+// dinonaktifkan atau dihapus ditolak walaupun belum dicabut.
+func authenticateAPIKey(c *fiber.Ctx, key string, apiKeys repository.APIKeyRepository, users repository.UserRepository) error {
+	apiKey, err := apiKeys.GetActiveByHash(c.UserContext(), HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.Unauthorized("Invalid or revoked API key")
+		}
+		return apperror.Wrap(err, "Failed to check API key")
+	}
+
+	creator, err := users.AuthState(c.UserContext(), apiKey.CreatedBy)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(err, "Failed to check API key owner")
+	}
+	if err != nil || !creator.Active {
+		return apperror.Unauthorized("Invalid or revoked API key")
+	}
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := apiKeys.Touch(c.UserContext(), apiKey.ID); err != nil {
+			log.Printf("Failed to update last use of API key %s: %v", apiKey.Prefix, err)
+		}
+	}
+
+	c.Locals("userID", apiKey.CreatedBy)
+	c.Locals("userRole", models.UserRole(""))
+	c.Locals("sessionID", 0)
+	c.Locals("apiKey", apiKey)
+	c.Locals("apiKeyOwnerRole", creator.Role)
+	return c.Next()
+}
+
+// RejectAPIKey menolak API key pada route tanpa permission (mis. logout, profil sendiri),
+// karena route tersebut bergantung pada identitas user, bukan scope
+func RejectAPIKey(c *fiber.Ctx) error {
+	if _, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		return apperror.Forbidden("API keys cannot access this endpoint")
+	}
+	return c.Next()
+}
@@ -1,59 +1,104 @@
 package middleware
 
 import (
-	"backend-go/internal/database"
+	"backend-go/internal/apperror"
+	"backend-go/internal/config"
+	"backend-go/internal/jwtkeys"
 	"backend-go/internal/models"
-	"context"
-	"os"
+	"backend-go/internal/repository"
+	"errors"
+	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(c *fiber.Ctx) error {
+// sessionTouchInterval jarak minimal antar pembaruan last_used_at session
+const sessionTouchInterval = time.Minute
+
+// NewAuthMiddleware membuat middleware JWT. Token wajib memiliki jti dan ditolak bila jti-nya
+// dicabut atau session-nya (claim sid) sudah dicabut. Pasang cache lewat
+// Repositories.WithRevocationCache agar pemeriksaan ini tidak ke database di setiap request.
+// Role dan status user dibaca ulang dari users, sehingga user yang dinonaktifkan atau dihapus
+// langsung ditolak dan perubahan role langsung berlaku tanpa menunggu token kedaluwarsa.
+// API key (header X-API-Key atau bearer berprefix APIKeyPrefix) diterima di samping JWT.
+// Request dengan token impersonasi (claim act) dicatat di audit log.
+func NewAuthMiddleware(jwtConfig config.JWTConfig, users repository.UserRepository, tokens repository.TokenRepository, sessions repository.SessionRepository, apiKeys repository.APIKeyRepository, audit repository.AuditRepository) fiber.Handler {
+    return func(c *fiber.Ctx) error {
+        if key := apiKeyFromRequest(c); key != "" {
+            return authenticateAPIKey(c, key, apiKeys, users)
+        }
+        return authenticate(c, jwtConfig.Keys, users, tokens, sessions, audit)
+    }
+}
+
+func authenticate(c *fiber.Ctx, keys *jwtkeys.Set, users repository.UserRepository, tokens repository.TokenRepository, sessions repository.SessionRepository, audit repository.AuditRepository) error {
     authHeader := c.Get("Authorization")
     if authHeader == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Authorization header required",
-        })
+        return apperror.Unauthorized("Authorization header required")
     }
 
     tokenString := ExtractToken(authHeader)
     if tokenString == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid token format",
-        })
+        return apperror.Unauthorized("Invalid token format")
     }
 
-    claims, err := validateToken(tokenString)
+    claims, err := ParseToken(tokenString, keys)
     if err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid token",
-        })
+        return apperror.Unauthorized("Invalid token")
+    }
+
+    // Token tanpa jti diterbitkan sebelum pencabutan per jti ada, pemiliknya harus login ulang
+    if claims.ID == "" {
+        return apperror.Unauthorized("Invalid token")
     }
 
-    var exists bool
-    err = database.DB.QueryRow(context.Background(),
-        "SELECT EXISTS(SELECT 1 FROM token_blacklist WHERE token = $1)",
-        tokenString,
-    ).Scan(&exists)
-    
+    revoked, err := tokens.IsRevoked(c.UserContext(), claims.ID)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to check token status",
-        })
+        return apperror.Wrap(err, "Failed to check token status")
     }
-    
-    if exists {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Token revoked",
-        })
+    if revoked {
+        return apperror.Unauthorized("Token revoked")
+    }
+
+    if claims.SessionID != 0 {
+        active, err := sessions.IsActive(c.UserContext(), claims.SessionID)
+        if err != nil {
+            return apperror.Wrap(err, "Failed to check session status")
+        }
+        if !active {
+            return apperror.Unauthorized("Session revoked")
+        }
+        // last_used_at ditampilkan di daftar session sebagai waktu terakhir terlihat
+        if err := sessions.Touch(c.UserContext(), claims.SessionID, time.Now().Add(-sessionTouchInterval)); err != nil {
+            log.Printf("Failed to update last use of session %d: %v", claims.SessionID, err)
+        }
+    }
+
+    // Role di token bisa sudah usang; yang berlaku adalah role user saat ini
+    state, err := users.AuthState(c.UserContext(), claims.UserID)
+    if err != nil && !errors.Is(err, repository.ErrNotFound) {
+        return apperror.Wrap(err, "Failed to check user status")
+    }
+    if err != nil || !state.Active {
+        return apperror.Unauthorized("Account is inactive")
     }
 
     // Simpan claims di context
     c.Locals("userID", claims.UserID)
-    c.Locals("userRole", claims.Role)
-    
+    c.Locals("userRole", state.Role)
+    c.Locals("sessionID", claims.SessionID)
+    c.Locals("amr", claims.AMR)
+
+    // Token impersonasi selalu terikat session agar dapat dicabut
+    if claims.Act != nil {
+        if claims.SessionID == 0 {
+            return apperror.Unauthorized("Invalid token")
+        }
+        return impersonate(c, claims, users, audit)
+    }
+
     return c.Next()
 }
 
@@ -64,15 +109,30 @@ func ExtractToken(header string) string {
     return ""
 }
 
-func validateToken(tokenString string) (*models.Claims, error) {
+// ParseToken memverifikasi signature (kunci dipilih lewat kid) dan masa berlaku access token
+func ParseToken(tokenString string, keys *jwtkeys.Set) (*models.Claims, error) {
     claims := &models.Claims{}
-    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        return []byte(os.Getenv("JWT_SECRET")), nil
-    })
+    token, err := keys.Parse(tokenString, claims)
 
     if err != nil || !token.Valid {
         return nil, fiber.ErrUnauthorized
     }
+    // Access token tidak memiliki audience; token bertujuan lain (mis. tantangan 2FA) ditolak
+    if len(claims.Audience) > 0 {
+        return nil, fiber.ErrUnauthorized
+    }
+
+    return claims, nil
+}
+
+// ParseChallenge memverifikasi token tantangan 2FA yang diterbitkan login
+func ParseChallenge(tokenString string, keys *jwtkeys.Set) (*models.MFAChallengeClaims, error) {
+    claims := &models.MFAChallengeClaims{}
+    token, err := keys.Parse(tokenString, claims, jwt.WithAudience(models.MFAChallengeAudience))
+
+    if err != nil || !token.Valid || claims.ID == "" {
+        return nil, fiber.ErrUnauthorized
+    }
 
     return claims, nil
 }
\ No newline at end of file
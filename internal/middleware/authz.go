@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/models"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Require menolak request dengan 403 bila role user tidak memiliki permission.
+// Dipasang setelah auth middleware karena membaca role dari Locals.
+func Require(policy *authz.Policy, perm authz.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !Can(c, policy, perm) {
+			return apperror.PermissionDenied(string(perm))
+		}
+		return c.Next()
+	}
+}
+
+// Can memeriksa permission user yang sedang login, untuk aturan yang bergantung pada
+// data request (mis. user boleh mengubah profilnya sendiri tanpa users:update).
+// Request dengan API key dinilai dari scope key, dibatasi permission role pembuatnya saat ini
+// agar key milik user yang turun role tidak mempertahankan hak lamanya.
+func Can(c *fiber.Ctx, policy *authz.Policy, perm authz.Permission) bool {
+	if key, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		owner, _ := c.Locals("apiKeyOwnerRole").(models.UserRole)
+		return slices.Contains(key.Scopes, string(perm)) && (!key.ReadOnly || perm.IsRead()) && policy.Allows(owner, perm)
+	}
+	role, _ := c.Locals("userRole").(models.UserRole)
+	return policy.Allows(role, perm)
+}
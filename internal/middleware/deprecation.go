@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/config"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header untuk menandai endpoint usang (RFC 9745 dan RFC 8594)
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// Deprecation dipasang pada path lama tanpa prefix versi. Response diberi header Deprecation,
+// Sunset, dan Link ke path pengganti di bawah successorPrefix. Setelah tanggal sunset lewat,
+// path lama menjawab 410 Gone sehingga client yang belum migrasi terdeteksi.
+func Deprecation(cfg config.APIConfig, successorPrefix string) fiber.Handler {
+	deprecation := "true"
+	if !cfg.LegacyDeprecatedAt.IsZero() {
+		deprecation = "@" + strconv.FormatInt(cfg.LegacyDeprecatedAt.Unix(), 10)
+	}
+	sunset := ""
+	if !cfg.LegacySunset.IsZero() {
+		sunset = cfg.LegacySunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *fiber.Ctx) error {
+		successor := successorPrefix + c.Path()
+		c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		c.Set(HeaderDeprecation, deprecation)
+		if sunset != "" {
+			c.Set(HeaderSunset, sunset)
+		}
+
+		if !cfg.LegacySunset.IsZero() && time.Now().After(cfg.LegacySunset) {
+			return apperror.Gone("This endpoint has been removed, use " + successor)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// HeaderImpersonatedBy dikirim di setiap response request dengan token impersonasi,
+// berisi ID admin yang sebenarnya
+const HeaderImpersonatedBy = "X-Impersonated-By"
+
+// impersonate menjalankan request atas nama user yang diperankan lalu mencatatnya di audit log
+// bersama admin yang sebenarnya. Admin yang sudah dinonaktifkan atau dihapus tidak dapat
+// melanjutkan impersonasi. Kegagalan mencatat hanya masuk log karena request sudah diproses.
+func impersonate(c *fiber.Ctx, claims *models.Claims, users repository.UserRepository, audit repository.AuditRepository) error {
+	actor, err := users.AuthState(c.UserContext(), claims.Act.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperror.Wrap(err, "Failed to check user status")
+	}
+	if err != nil || !actor.Active {
+		return apperror.Unauthorized("Impersonating account is inactive")
+	}
+
+	c.Locals("impersonatorID", claims.Act.UserID)
+	c.Set(HeaderImpersonatedBy, strconv.Itoa(claims.Act.UserID))
+
+	err = c.Next()
+
+	status := c.Response().StatusCode()
+	if err != nil {
+		status = apperror.From(err).Status
+	}
+	requestID, _ := c.Locals(apperror.RequestIDKey).(string)
+	entry := models.AuditEntry{
+		Action:    models.AuditImpersonatedRequest,
+		ActorID:   claims.Act.UserID,
+		UserID:    claims.UserID,
+		SessionID: claims.SessionID,
+		Method:    utils.CopyString(c.Method()),
+		Path:      utils.CopyString(c.Path()),
+		Status:    status,
+		RequestID: requestID,
+		IPAddress: utils.CopyString(c.IP()),
+	}
+	// Context request bisa sudah habis (mis. timeout), tetapi request tetap harus tercatat
+	if auditErr := audit.Create(context.WithoutCancel(c.UserContext()), &entry); auditErr != nil {
+		log.Printf("Failed to record impersonated request %s %s by user %d: %v", entry.Method, entry.Path, entry.ActorID, auditErr)
+	}
+	return err
+}
+
+// Impersonator mengembalikan ID admin di balik token impersonasi, atau nil untuk request
+// biasa. Handler menyimpannya bersama edited_by agar perubahan tercatat atas nama keduanya
+func Impersonator(c *fiber.Ctx) *int {
+	if id, ok := c.Locals("impersonatorID").(int); ok {
+		return &id
+	}
+	return nil
+}
+
+// RejectImpersonation menolak token impersonasi pada route yang mengubah kredensial user
+// (password, 2FA, API key), mencabut session user, atau memulai impersonasi lain
+func RejectImpersonation(c *fiber.Ctx) error {
+	if Impersonator(c) != nil {
+		return apperror.Forbidden("This action is not allowed while impersonating a user")
+	}
+	return c.Next()
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/models"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireTOTP menolak token tanpa metode otp untuk role yang wajib 2FA, sehingga user role
+// tersebut harus mendaftarkan authenticator lalu login ulang. Dipasang setelah auth middleware.
+func RequireTOTP(roles []models.UserRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("userRole").(models.UserRole)
+		if !slices.Contains(roles, role) {
+			return c.Next()
+		}
+		amr, _ := c.Locals("amr").([]string)
+		if !slices.Contains(amr, models.AMROTP) {
+			return apperror.Forbidden("Two-factor authentication is required for your role; enroll at /me/2fa/setup and log in again")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimit membatasi jumlah request per IP dalam satu window. Limiter menyimpan hitungan
+// di memori proses dan mengisi header Retry-After saat batas tercapai. max <= 0 mematikan pembatasan.
+func RateLimit(max int, window time.Duration) fiber.Handler {
+	if max <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return apperror.TooManyRequests("Too many requests, please try again later")
+		},
+	})
+}
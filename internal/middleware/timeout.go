@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"backend-go/internal/apperror"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout memasang context ber-deadline sebagai c.UserContext() sehingga query database
+// ikut dibatalkan saat request terlalu lama. Dapat dipasang ulang per route untuk
+// mengganti deadline default, karena context selalu dibuat baru dari context.Background().
+// c.Context() sengaja tidak dipakai: Done() milik fasthttp ditutup saat server shutdown,
+// bukan saat client memutus koneksi, sehingga request yang sedang di-drain ikut batal.
+// fasthttp tidak memberi sinyal saat client memutus koneksi, jadi request tersebut tetap
+// berjalan sampai selesai atau sampai deadline ini. Status 499 (context.Canceled) hanya
+// muncul bila pembatalan datang dari dalam aplikasi, mis. context yang dibatalkan handler.
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(context.Background(), d)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		// Kegagalan saat context sudah habis dilaporkan sebagai 503/499, bukan 404/500 dari handler
+		if ctxErr := ctx.Err(); ctxErr != nil && (err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest) {
+			return apperror.From(ctxErr)
+		}
+		return err
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// APIKey kredensial klien mesin dengan permission terbatas pada Scopes
+type APIKey struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	// Scopes permission yang sudah diekspansi dari wildcard saat key dibuat
+	Scopes []string `json:"scopes"`
+	// ReadOnly membatasi key pada permission read walaupun Scopes berisi permission lain
+	ReadOnly   bool       `json:"read_only"`
+	CreatedBy  int        `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// Active true bila key belum dicabut dan belum kedaluwarsa
+func (k APIKey) Active(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
+
+// CreateAPIKeyRequest input pembuatan API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,notblank,max=100"`
+	// Scopes nama permission atau wildcard (products:*); harus dimiliki role pembuat key
+	Scopes   []string `json:"scopes" validate:"required,min=1"`
+	ReadOnly bool     `json:"read_only"`
+	// ExpiresInDays kosong berarti key berlaku sampai dicabut
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=3650"`
+}
+
+// APIKeyCreatedResponse API key baru beserta key lengkap yang hanya ditampilkan sekali
+type APIKeyCreatedResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
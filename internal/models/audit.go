@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Jenis entri audit log
+const (
+	// AuditImpersonationStarted admin mulai memerankan user; Detail berisi alasannya
+	AuditImpersonationStarted = "impersonation_started"
+	// AuditImpersonatedRequest request yang dikirim dengan token impersonasi
+	AuditImpersonatedRequest = "impersonated_request"
+)
+
+// AuditEntry satu aksi yang dilakukan admin atas nama user lain
+type AuditEntry struct {
+	ID     int    `json:"id"`
+	Action string `json:"action"`
+	// ActorID admin yang sebenarnya, UserID user yang diperankan
+	ActorID   int       `json:"actor_id"`
+	UserID    int       `json:"user_id"`
+	SessionID int       `json:"session_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"request_id"`
+	IPAddress string    `json:"ip_address"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImpersonateRequest alasan impersonasi, dicatat di audit log
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,notblank,min=3,max=500"`
+}
+
+// ImpersonationResponse access token yang bertindak sebagai user lain. Tidak ada refresh token;
+// setelah Expires admin harus memulai impersonasi baru.
+type ImpersonationResponse struct {
+	Token          string            `json:"token"`
+	Expires        string            `json:"expires"`
+	SessionID      int               `json:"session_id"`
+	ImpersonatorID int               `json:"impersonator_id"`
+	User           UserLoginResponse `json:"user"`
+}
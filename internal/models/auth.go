@@ -4,15 +4,25 @@ import "github.com/golang-jwt/jwt/v5"
 
 type LoginRequest struct {
     Username string `json:"username" validate:"required"`
-    Password string `json:"password" validate:"required,min=8"`
+    Password string `json:"password" validate:"required"`
 }
 
 type Claims struct {
-    UserID int      `json:"user_id"`
-    Role   UserRole `json:"role"`
+    UserID    int      `json:"user_id"`
+    Role      UserRole `json:"role"`
+    SessionID int      `json:"sid,omitempty"`
+    // AMR metode autentikasi session (pwd, otp)
+    AMR       []string `json:"amr,omitempty"`
+    // Act admin yang memerankan user (actor claim, RFC 8693); nil untuk token biasa
+    Act       *Actor   `json:"act,omitempty"`
     jwt.RegisteredClaims
 }
 
+// Actor identitas sebenarnya di balik token impersonasi
+type Actor struct {
+    UserID int `json:"user_id"`
+}
+
 type UserLoginResponse struct {
     ID       int    `json:"id"`
     Username string `json:"username"`
@@ -7,8 +7,8 @@ import (
 type Carousel struct {
 	ID        	int       `json:"id"`
 	Image	 	string    `json:"image" validate:"required,url"`
-	Title	 	string    `json:"title" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	Title	 	string    `json:"title" validate:"required,notblank"`
+	Description string `json:"description" validate:"required,notblank"`
 	Status		bool      `json:"status"`
 	CreatedAt 	time.Time `json:"created_at"`
 	CreatedBy 	*int      `json:"created_by"`
@@ -16,13 +16,13 @@ type Carousel struct {
 	EditedBy  	*int      `json:"edited_by"`
 	DeletedAt 	*time.Time `json:"deleted_at"`
 	DeletedBy 	*int      `json:"deleted_by"`
+	ImpersonatedBy *int   `json:"impersonated_by,omitempty"`
 }
 
 type CarouselCreateRequest struct {
-	Image       string `json:"image" validate:"required,url"`
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description" validate:"required"`
-	Status	  	bool   `json:"status" validate:"required"`
+	Title       string `form:"title" validate:"required,notblank,max=100"`
+	Description string `form:"description"`
+	Status	  	bool   `form:"status"`
 }
 
 type CarouselUpdateRequest struct {
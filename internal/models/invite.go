@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Invite undangan registrasi sekali pakai yang menentukan role user baru
+type Invite struct {
+	ID        int        `json:"id"`
+	Role      UserRole   `json:"role"`
+	CreatedBy int        `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	UsedBy    *int       `json:"used_by"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// CreateInviteRequest input pembuatan undangan
+type CreateInviteRequest struct {
+	Role UserRole `json:"role" validate:"required,oneof=admin staff user"`
+	// ExpiresInHours masa berlaku undangan; kosong memakai AUTH_INVITE_TTL
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+}
+
+// InviteCreatedResponse undangan baru beserta kode yang hanya ditampilkan sekali
+type InviteCreatedResponse struct {
+	Invite
+	Code string `json:"code"`
+}
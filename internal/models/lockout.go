@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Jenis lockout event
+const (
+	LockoutEventLocked   = "locked"
+	LockoutEventUnlocked = "unlocked"
+)
+
+// LoginFailures status percobaan login gagal untuk satu username
+type LoginFailures struct {
+	Username      string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// LockoutEvent catatan penguncian akun karena gagal login berulang atau pembukaan kunci oleh admin
+type LockoutEvent struct {
+	ID          int        `json:"id"`
+	Username    string     `json:"username"`
+	UserID      *int       `json:"user_id"`
+	Event       string     `json:"event"`
+	Failures    int        `json:"failures"`
+	IPAddress   string     `json:"ip_address"`
+	ActorID     *int       `json:"actor_id"`
+	LockedUntil *time.Time `json:"locked_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
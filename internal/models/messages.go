@@ -3,40 +3,41 @@ package models
 import "time"
 
 type Message struct {
-	ID           int        `json:"id"`
-	Name         string     `json:"name"`
-	Company      string     `json:"company,omitempty"`
-	Address      string     `json:"address,omitempty"`
-	Description  string     `json:"description"`
-	CreatedAt    time.Time  `json:"created_at"`
-	CreatedBy    int        `json:"created_by"`
-	EditedAt     *time.Time `json:"edited_at,omitempty"`
-	EditedBy     *int       `json:"edited_by,omitempty"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
-	DeletedBy    *int       `json:"deleted_by,omitempty"`
-	ProductID    *int       `json:"product_id,omitempty"`
-	DateSchedule *time.Time `json:"date_schedule"`
-	Phone        string     `json:"phone"`
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	Company        string     `json:"company,omitempty"`
+	Address        string     `json:"address,omitempty"`
+	Description    string     `json:"description"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CreatedBy      int        `json:"created_by"`
+	EditedAt       *time.Time `json:"edited_at,omitempty"`
+	EditedBy       *int       `json:"edited_by,omitempty"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy      *int       `json:"deleted_by,omitempty"`
+	ImpersonatedBy *int       `json:"impersonated_by,omitempty"`
+	ProductID      *int       `json:"product_id,omitempty"`
+	DateSchedule   *time.Time `json:"date_schedule"`
+	Phone          string     `json:"phone"`
 }
 
 type MessageCreateRequest struct {
-	Name         string     `form:"name"`
-	Company      string     `form:"company"`
-	ProductID    *int       `form:"product_id"`
+	Name         string     `form:"name" validate:"required,notblank,max=100"`
+	Company      string     `form:"company" validate:"max=100"`
+	ProductID    *int       `form:"product_id" validate:"omitempty,min=1"`
 	Address      string     `form:"address"`
-	Description  string     `form:"description"`
+	Description  string     `form:"description" validate:"required,notblank"`
 	DateSchedule *time.Time `json:"date_schedule"`
-	Phone        string     `json:"phone"`
+	Phone        string     `json:"phone" validate:"required,e164"`
 }
 
 type MessageUpdateRequest struct {
-	Name         *string    `form:"name"`
-	Company      *string    `form:"company"`
-	ProductID    *int       `form:"product_id"`
+	Name         *string    `form:"name" validate:"omitempty,notblank,max=100"`
+	Company      *string    `form:"company" validate:"omitempty,max=100"`
+	ProductID    *int       `form:"product_id" validate:"omitempty,min=1"`
 	Address      *string    `form:"address"`
-	Description  *string    `form:"description"`
+	Description  *string    `form:"description" validate:"omitempty,notblank"`
 	DateSchedule *time.Time `json:"date_schedule,omitempty"`
-	Phone        string     `json:"phone"`
+	Phone        string     `json:"phone" validate:"omitempty,e164"`
 }
 
 type MessageWithProduct struct {
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// AMRFederated metode autentikasi untuk session hasil login lewat identity provider (RFC 8176)
+const AMRFederated = "fed"
+
+// OIDCState login SSO yang sedang berjalan, disimpan sampai callback
+type OIDCState struct {
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// UserIdentity akun identity provider yang tertaut ke user
+type UserIdentity struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// OIDCAuthorizeResponse URL login provider; state dikembalikan provider ke redirect URL
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// OIDCCallbackRequest code dan state yang diterima redirect URL dari provider
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// ForgotPasswordRequest permintaan token reset password
+type ForgotPasswordRequest struct {
+	Username string `json:"username" validate:"required,max=50"`
+}
+
+// ResetPasswordRequest penggantian password dengan token reset
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,max=72"`
+}
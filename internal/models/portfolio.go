@@ -3,24 +3,25 @@ package models
 import "time"
 
 type PortfolioReview struct {
-	ID          int        `json:"id"`
-	ProductID   *int       `json:"product_id,omitempty"`
-	Title       string     `json:"title" validate:"required,max=100"`
-	Description string     `json:"description" validate:"required"`
-	Image       string     `json:"image,omitempty"`
-	Date        time.Time  `json:"date" validate:"required"`
-	CreatedAt   time.Time  `json:"created_at"`
-	CreatedBy   int        `json:"created_by"`
-	EditedAt    *time.Time `json:"edited_at,omitempty"`
-	EditedBy    *int       `json:"edited_by,omitempty"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
-	DeletedBy   *int       `json:"deleted_by,omitempty"`
+	ID             int        `json:"id"`
+	ProductID      *int       `json:"product_id,omitempty"`
+	Title          string     `json:"title" validate:"required,notblank,max=100"`
+	Description    string     `json:"description" validate:"required,notblank"`
+	Image          string     `json:"image,omitempty"`
+	Date           time.Time  `json:"date" validate:"required"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CreatedBy      int        `json:"created_by"`
+	EditedAt       *time.Time `json:"edited_at,omitempty"`
+	EditedBy       *int       `json:"edited_by,omitempty"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy      *int       `json:"deleted_by,omitempty"`
+	ImpersonatedBy *int       `json:"impersonated_by,omitempty"`
 }
 
 type PortfolioReviewCreateRequest struct {
 	ProductID   *int   `form:"product_id,omitempty"`
-	Title       string `form:"title" validate:"required,max=100"`
-	Description string `form:"description" validate:"required"`
+	Title       string `form:"title" validate:"required,notblank,max=100"`
+	Description string `form:"description" validate:"required,notblank"`
 	Date        string `form:"date" validate:"required,datetime=2006-01-02"`
 }
 
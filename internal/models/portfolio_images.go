@@ -13,6 +13,7 @@ type PortfolioImage struct {
     EditedBy   *int       `json:"edited_by,omitempty"`
     DeletedAt  *time.Time `json:"deleted_at,omitempty"`
     DeletedBy  *int       `json:"deleted_by,omitempty"`
+    ImpersonatedBy *int   `json:"impersonated_by,omitempty"`
 }
 
 type PortfolioImageCreateRequest struct {
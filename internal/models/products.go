@@ -16,7 +16,7 @@ const (
 type Product struct {
     ID           int          `json:"id"`
     Image        string       `json:"image" validate:"required,url"`
-    Title        string       `json:"title" validate:"required,max=100"`
+    Title        string       `json:"title" validate:"required,notblank,max=100"`
     Description  string       `json:"description,omitempty"`
     TypeProduct  ProductType  `json:"type_product" validate:"required,oneof=physical digital service"`
     Price        float64      `json:"price" validate:"required,min=0"`
@@ -27,10 +27,11 @@ type Product struct {
     EditedBy     *int         `json:"edited_by,omitempty"`
     DeletedAt    *time.Time   `json:"deleted_at,omitempty"`
     DeletedBy    *int         `json:"deleted_by,omitempty"`
+    ImpersonatedBy *int       `json:"impersonated_by,omitempty"`
 }
 
 type ProductCreateRequest struct {
-    Title        string      `form:"title" validate:"required,max=100"`
+    Title        string      `form:"title" validate:"required,notblank,max=100"`
     Description  string      `form:"description,omitempty"`
     TypeProduct  ProductType `form:"type_product" validate:"required,oneof=physical digital service"`
     Price        string      `form:"price" validate:"required,decimal=2"`
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Metode autentikasi untuk claim amr (RFC 8176)
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+)
+
+// Alasan pencabutan session
+const (
+	SessionRevokedLogout = "logout"
+	SessionRevokedReuse  = "refresh_token_reuse"
+	// SessionRevokedPasswordReset seluruh session user dicabut setelah password di-reset
+	SessionRevokedPasswordReset = "password_reset"
+	// SessionRevokedPasswordChange session lain dicabut setelah user mengganti password-nya
+	SessionRevokedPasswordChange = "password_changed"
+	// SessionRevokedDeactivated dan SessionRevokedDeleted seluruh session user dicabut saat
+	// admin menonaktifkan atau menghapus akunnya
+	SessionRevokedDeactivated = "user_deactivated"
+	SessionRevokedDeleted     = "user_deleted"
+	// SessionRevokedOthers session lain dicabut pemiliknya lewat "logout di semua perangkat"
+	SessionRevokedOthers = "logout_others"
+	// SessionRevokedAdmin seluruh session user dicabut paksa oleh admin
+	SessionRevokedAdmin = "admin_logout"
+	// SessionRevokedTOTPReset seluruh session user dicabut saat admin me-reset 2FA-nya, karena
+	// amr session lama masih menyatakan otp
+	SessionRevokedTOTPReset = "totp_reset"
+)
+
+// Session satu login yang dapat diperpanjang dengan refresh token
+type Session struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	UserAgent     string     `json:"user_agent"`
+	IPAddress     string     `json:"ip_address"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	AMR           []string   `json:"amr"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason *string    `json:"revoked_reason,omitempty"`
+	// ImpersonatorID admin yang memerankan user lewat session ini
+	ImpersonatorID *int `json:"impersonator_id,omitempty"`
+}
+
+// Active true bila session belum dicabut dan belum kedaluwarsa
+func (s Session) Active(at time.Time) bool {
+	return s.RevokedAt == nil && at.Before(s.ExpiresAt)
+}
+
+// SessionResponse session aktif yang ditampilkan ke pemiliknya atau admin
+type SessionResponse struct {
+	ID         int       `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	AMR        []string  `json:"amr"`
+	// ImpersonatorID terisi bila session ini dipakai admin untuk memerankan user
+	ImpersonatorID *int `json:"impersonator_id,omitempty"`
+	// Current true untuk session milik token yang dipakai request ini
+	Current bool `json:"current"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenResponse pasangan access token dan refresh token hasil login atau refresh
+type TokenResponse struct {
+	Token          string             `json:"token"`
+	Expires        string             `json:"expires"`
+	RefreshToken   string             `json:"refresh_token"`
+	RefreshExpires string             `json:"refresh_expires"`
+	User           *UserLoginResponse `json:"user,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TOTP pendaftaran authenticator milik user
+type TOTP struct {
+	UserID       int
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// MFAChallengeAudience audience token tantangan 2FA
+const MFAChallengeAudience = "mfa-challenge"
+
+// MFAChallengeClaims claims token tantangan yang diterbitkan login untuk user dengan 2FA aktif.
+// Audience membedakannya dari access token sehingga tidak dapat dipakai untuk mengakses API.
+type MFAChallengeClaims struct {
+	UserID int `json:"user_id"`
+	// AMR faktor pertama yang sudah lolos (pwd atau fed), dibawa ke session setelah kode benar
+	AMR []string `json:"amr,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// MFAChallengeResponse response login yang masih menunggu kode TOTP
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	Expires        string `json:"expires"`
+}
+
+// MFAVerifyRequest menyelesaikan login dengan kode TOTP atau salah satu kode pemulihan
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"omitempty,len=6,number"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// TOTPCodeRequest kode TOTP untuk mengonfirmasi tindakan pada pendaftaran 2FA
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,number"`
+}
+
+// TOTPSetupResponse secret dan URI otpauth:// untuk dirender sebagai QR code
+type TOTPSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse kode pemulihan, hanya ditampilkan sekali saat dibuat
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TOTPStatusResponse status 2FA user yang sedang login
+type TOTPStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
@@ -16,7 +16,7 @@ const (
 // User struktur data untuk tabel users
 type User struct {
 	ID         int        `json:"id"`
-	Name       string     `json:"name" validate:"required,min=3"`
+	Name       string     `json:"name" validate:"required,notblank,min=3"`
 	Phone      string     `json:"phone" validate:"required,e164"`
 	Username   string     `json:"username" validate:"required,alphanum"`
 	Password   string     `json:"password" validate:"required,min=8"`
@@ -28,27 +28,50 @@ type User struct {
 	EditedBy   *int       `json:"edited_by"`
 	DeletedAt  *time.Time `json:"deleted_at"`
 	DeletedBy  *int       `json:"deleted_by"`
+	// ImpersonatedBy admin yang memerankan user pada penulisan terakhir (created_by,
+	// edited_by, atau deleted_by); nil bila dilakukan user sendiri
+	ImpersonatedBy *int   `json:"impersonated_by,omitempty"`
+}
+
+// UserAuthState role dan status terkini user yang diperiksa middleware auth di setiap request,
+// sehingga perubahan role, penonaktifan dan penghapusan berlaku tanpa menunggu token kedaluwarsa
+type UserAuthState struct {
+	Role   UserRole
+	Active bool
 }
 
 // CreateRequest struktur untuk input create user
 type CreateRequest struct {
-    Name     string   `json:"name" validate:"required,min=3"`
+    Name     string   `json:"name" validate:"required,notblank,min=3,max=100"`
     Phone    string   `json:"phone" validate:"required,e164"`
-    Username string   `json:"username" validate:"required,alphanum"`
-    Password string   `json:"password" validate:"required,min=8"`
-    Role     UserRole `json:"role,omitempty"`
+    Username string   `json:"username" validate:"required,alphanum,max=50"`
+    Password string   `json:"password" validate:"required,max=72"`
+    Role     UserRole `json:"role,omitempty" validate:"omitempty,oneof=admin staff user"`
 }
 
 // UpdateRequest struktur untuk input update user
 type UpdateRequest struct {
-    Name     string   `json:"name,omitempty"`
-    Phone    string   `json:"phone,omitempty"`
-    Username string   `json:"username,omitempty"`
-    Password string   `json:"password,omitempty"`
-    Role     UserRole `json:"role,omitempty"`
+    Name     string   `json:"name,omitempty" validate:"omitempty,notblank,min=3,max=100"`
+    Phone    string   `json:"phone,omitempty" validate:"omitempty,e164"`
+    Username string   `json:"username,omitempty" validate:"omitempty,alphanum,max=50"`
+    Password string   `json:"password,omitempty" validate:"omitempty,max=72"`
+    Role     UserRole `json:"role,omitempty" validate:"omitempty,oneof=admin staff user"`
     Status   *bool    `json:"status,omitempty"`
 }
 
+// UpdateProfileRequest perubahan profil sendiri; field yang tidak dikirim tidak diubah
+type UpdateProfileRequest struct {
+    Name     *string `json:"name,omitempty" validate:"omitempty,notblank,min=3,max=100"`
+    Phone    *string `json:"phone,omitempty" validate:"omitempty,e164"`
+    Username *string `json:"username,omitempty" validate:"omitempty,alphanum,max=50"`
+}
+
+// ChangePasswordRequest penggantian password sendiri dengan konfirmasi password lama
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" validate:"required"`
+    NewPassword     string `json:"new_password" validate:"required,max=72"`
+}
+
 // UserResponse struktur untuk output user
 type UserResponse struct {
     ID        int        `json:"id"`
@@ -61,12 +84,32 @@ type UserResponse struct {
     CreatedBy *int       `json:"created_by"`
     EditedAt  *time.Time `json:"edited_at"`
     EditedBy  *int       `json:"edited_by"`
+    ImpersonatedBy *int  `json:"impersonated_by,omitempty"`
+}
+
+// Response memetakan user ke response tanpa password; satu-satunya pemetaan User ke
+// UserResponse agar field baru tidak terlewat di salah satu endpoint
+func (u User) Response() UserResponse {
+    return UserResponse{
+        ID:             u.ID,
+        Name:           u.Name,
+        Phone:          u.Phone,
+        Username:       u.Username,
+        Role:           u.Role,
+        Status:         u.Status,
+        CreatedAt:      u.CreatedAt,
+        CreatedBy:      u.CreatedBy,
+        EditedAt:       u.EditedAt,
+        EditedBy:       u.EditedBy,
+        ImpersonatedBy: u.ImpersonatedBy,
+    }
 }
 
 type RegisterRequest struct {
-    Name     string `json:"name" validate:"required,min=3,max=100"`
-    Phone    string `json:"phone" validate:"required,numeric,min=10,max=15"`
-    Username string `json:"username" validate:"required,min=5,max=50"`
-    Password string `json:"password" validate:"required,min=8,max=72"`
-    Role    string `json:"role" validate:"required,oneof=admin staff user"`
+    Name       string `json:"name" validate:"required,notblank,min=3,max=100"`
+    Phone      string `json:"phone" validate:"required,e164"`
+    Username   string `json:"username" validate:"required,alphanum,max=50"`
+    Password   string `json:"password" validate:"required,max=72"`
+    // InviteCode kode undangan; role akun mengikuti undangan. Tanpa kode, akun dibuat ber-role user.
+    InviteCode string `json:"invite_code" validate:"omitempty,max=100"`
 }
\ No newline at end of file
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"backend-go/internal/validation"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestValidateTags membaca source package ini dan memeriksa tag `validate` setiap field,
+// sehingga aturan yang salah ketik gagal di test, bukan saat request pertama masuk
+func TestValidateTags(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range st.Fields.List {
+				if field.Tag == nil {
+					continue
+				}
+				raw, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					t.Fatalf("%s: %v", fset.Position(field.Pos()), err)
+				}
+				tag, ok := reflect.StructTag(raw).Lookup("validate")
+				if !ok || tag == "" || tag == "-" {
+					continue
+				}
+				checked++
+				if err := validation.CheckTag(tag); err != nil {
+					t.Errorf("%s: %s: %v", fset.Position(field.Pos()), spec.Name.Name, err)
+				}
+			}
+			return true
+		})
+	}
+	if checked == 0 {
+		t.Fatal("no validate tags found")
+	}
+}
@@ -0,0 +1,77 @@
+// Package notify mengirim pesan ke user (mis. kode reset password) lewat kanal yang dapat diganti.
+package notify
+
+import (
+	"backend-go/internal/config"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Driver yang didukung NOTIFY_DRIVER
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+// Message pesan untuk satu penerima. To berisi kontak user (nomor telepon).
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier mengirim pesan. Implementasi produksi (SMS, WhatsApp, email) cukup memenuhi interface ini.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New memilih notifier sesuai konfigurasi; driver kosong memakai log
+func New(cfg config.NotifyConfig) Notifier {
+	switch cfg.Driver {
+	case DriverFile:
+		return &fileNotifier{path: cfg.File}
+	default:
+		return logNotifier{}
+	}
+}
+
+// logNotifier menulis pesan ke log aplikasi, hanya untuk development
+type logNotifier struct{}
+
+func (logNotifier) Send(ctx context.Context, msg Message) error {
+	log.Printf("notify: to=%s subject=%q body=%q", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// fileNotifier menambahkan pesan sebagai satu baris JSON ke file, untuk development dan pengujian manual
+type fileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (n *fileNotifier) Send(ctx context.Context, msg Message) error {
+	line, err := json.Marshal(struct {
+		Time time.Time `json:"time"`
+		Message
+	}{time.Now().UTC(), msg})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
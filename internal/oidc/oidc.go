@@ -0,0 +1,400 @@
+// Package oidc klien OpenID Connect untuk login authorization code dengan PKCE (RFC 7636).
+// Metadata provider diambil lewat discovery, JWKS di-cache dan diambil ulang ketika provider
+// merotasi kunci (kid tidak dikenal).
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"backend-go/internal/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// keyRefreshInterval jarak minimal antar pengambilan ulang JWKS karena kid tidak dikenal,
+// agar token dengan kid palsu tidak membuat setiap request memanggil provider
+const keyRefreshInterval = time.Minute
+
+// maxResponseBytes batas ukuran response provider yang dibaca
+const maxResponseBytes = 1 << 20
+
+// clockSkew toleransi selisih jam dengan provider untuk exp dan iat
+const clockSkew = time.Minute
+
+// Config client yang terdaftar di identity provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// metadata bagian dokumen /.well-known/openid-configuration yang dipakai
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider identity provider yang dikonfigurasi. Aman dipakai bersamaan.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	// group menggabungkan discovery dan pengambilan JWKS yang bersamaan menjadi satu request
+	group singleflight.Group
+
+	mu        sync.Mutex
+	meta      *metadata
+	keys      map[string]*jwtkeys.Key
+	keysFetch time.Time
+}
+
+// New membuat Provider; discovery baru dilakukan saat pertama dipakai sehingga server tetap
+// bisa start walaupun provider sedang tidak bisa dihubungi
+func New(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+// NewVerifier membuat code verifier PKCE acak (43 karakter base64url)
+func NewVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge code challenge S256 untuk verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL URL halaman login provider. state dan nonce harus acak per login; verifier
+// disimpan server dan dikirim kembali saat Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan token, lalu memverifikasi ID token
+// (signature, issuer, audience, masa berlaku, dan nonce)
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.do(req, &token)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	if status != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("token request rejected (%d): %s %s", status, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.verify(ctx, meta, token.IDToken, nonce)
+}
+
+// verify memeriksa ID token dengan kunci dari JWKS provider
+func (p *Provider) verify(ctx context.Context, meta *metadata, raw, nonce string) (*Claims, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(raw, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("malformed id_token: %w", err)
+	}
+	kid, _ := unverified.Header["kid"].(string)
+	key, err := p.key(ctx, meta, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = key.Verify(raw, claims,
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	c := &Claims{raw: claims}
+	if c.Subject() == "" {
+		return nil, errors.New("id_token has no sub")
+	}
+	if c.String("nonce") != nonce {
+		return nil, errors.New("id_token nonce does not match")
+	}
+	// Dengan lebih dari satu audience, azp wajib menunjuk client ini (OIDC Core 3.1.3.7)
+	if aud, _ := claims.GetAudience(); len(aud) > 1 && c.String("azp") != p.cfg.ClientID {
+		return nil, errors.New("id_token azp does not match client")
+	}
+	return c, nil
+}
+
+// discover mengambil metadata provider sekali lalu menyimpannya
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	meta := p.meta
+	p.mu.Unlock()
+	if meta != nil {
+		return meta, nil
+	}
+
+	v, err := p.fetch(ctx, "discovery", p.fetchMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*metadata), nil
+}
+
+// fetchMetadata mengambil dokumen discovery dan menyimpannya bila valid
+func (p *Provider) fetchMetadata(ctx context.Context) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	status, err := p.do(req, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("discovery: provider returned %d", status)
+	}
+	// Issuer di metadata wajib sama persis dengan yang dikonfigurasi (OIDC Discovery 4.3)
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match configured %q", meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("discovery: metadata is missing endpoints")
+	}
+
+	p.mu.Lock()
+	p.meta = &meta
+	p.mu.Unlock()
+	return &meta, nil
+}
+
+// key mencari kunci verifikasi berdasarkan kid, mengambil ulang JWKS bila kid belum dikenal
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (*jwtkeys.Key, error) {
+	p.mu.Lock()
+	key, ok := p.lookup(kid)
+	recent := time.Since(p.keysFetch) < keyRefreshInterval
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if recent {
+		return nil, fmt.Errorf("unknown id_token kid %q", kid)
+	}
+
+	if _, err := p.fetch(ctx, "jwks", func(ctx context.Context) (interface{}, error) {
+		return nil, p.fetchKeys(ctx, meta.JWKSURI)
+	}); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	key, ok = p.lookup(kid)
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown id_token kid %q", kid)
+}
+
+// fetchKeys mengambil JWKS dan mengganti kunci yang di-cache
+func (p *Provider) fetchKeys(ctx context.Context, uri string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	var jwks jwtkeys.JWKS
+	status, err := p.do(req, &jwks)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("jwks: provider returned %d", status)
+	}
+
+	// Kunci dengan tipe yang tidak didukung (mis. kunci enkripsi) dilewati
+	keys := make(map[string]*jwtkeys.Key, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwtkeys.FromJWK(jwk); err == nil {
+			keys[key.ID] = key
+		}
+	}
+
+	p.mu.Lock()
+	p.keys, p.keysFetch = keys, time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// fetch menjalankan fn sekali untuk semua pemanggil yang bersamaan dengan name yang sama,
+// tanpa memegang mu selama request ke provider. Request tidak ikut batal bila pemanggil
+// pertama batal karena pemanggil lain menunggu hasil yang sama; pemanggil yang batal
+// langsung kembali tanpa menunggu.
+func (p *Provider) fetch(ctx context.Context, name string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	ch := p.group.DoChan(name, func() (interface{}, error) {
+		return fn(context.WithoutCancel(ctx))
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// lookup kunci untuk kid; token tanpa kid hanya diterima bila JWKS berisi satu kunci.
+// Pemanggil harus memegang mu.
+func (p *Provider) lookup(kid string) (*jwtkeys.Key, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// do mengirim request dan men-decode body JSON ke out, juga untuk status selain 200
+func (p *Provider) do(req *http.Request, out interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// Claims claim ID token yang sudah diverifikasi
+type Claims struct {
+	raw jwt.MapClaims
+}
+
+// Subject identitas user yang stabil di provider
+func (c *Claims) Subject() string {
+	sub, _ := c.raw.GetSubject()
+	return sub
+}
+
+// Issuer penerbit ID token
+func (c *Claims) Issuer() string {
+	iss, _ := c.raw.GetIssuer()
+	return iss
+}
+
+// String nilai claim berupa string; kosong bila tidak ada atau bukan string
+func (c *Claims) String(name string) string {
+	s, _ := c.raw[name].(string)
+	return s
+}
+
+// Bool nilai claim boolean (mis. email_verified); false bila tidak ada. Sebagian provider
+// mengirimnya sebagai string "true".
+func (c *Claims) Bool(name string) bool {
+	switch v := c.raw[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Strings nilai claim berupa array string. Claim berupa string tunggal (mis. "amr" atau
+// grup dari sebagian provider) dipisah berdasarkan spasi atau koma.
+func (c *Claims) Strings(name string) []string {
+	switch v := c.raw[name].(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+	return nil
+}
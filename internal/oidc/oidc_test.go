@@ -0,0 +1,294 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend-go/internal/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "backend"
+
+// fakeProvider provider yang menerbitkan ID token dengan claim dan kunci yang diatur test
+type fakeProvider struct {
+	*httptest.Server
+	t *testing.T
+
+	mu     sync.Mutex
+	served *jwtkeys.Set
+	signer *jwtkeys.Set
+	claims func(issuer string) jwt.MapClaims
+
+	// latency jeda sebelum discovery dan JWKS dijawab
+	latency     time.Duration
+	discoveries atomic.Int32
+	jwksFetches atomic.Int32
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	f := &fakeProvider{t: t}
+	f.served = newKeySet(t)
+	f.signer = f.served
+	f.claims = validClaims
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		f.discoveries.Add(1)
+		time.Sleep(f.latency)
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.URL,
+			"authorization_endpoint": f.URL + "/authorize",
+			"token_endpoint":         f.URL + "/token",
+			"jwks_uri":               f.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.jwksFetches.Add(1)
+		time.Sleep(f.latency)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		json.NewEncoder(w).Encode(f.served.JWKS())
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		idToken, err := f.signer.Sign(f.claims(f.URL))
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeProvider) provider() *Provider {
+	return New(Config{Issuer: f.URL, ClientID: testClientID, RedirectURL: "http://app.test/callback"}, nil)
+}
+
+// set mengganti kunci di JWKS, kunci penandatangan, dan claim token berikutnya
+func (f *fakeProvider) set(served, signer *jwtkeys.Set, claims func(issuer string) jwt.MapClaims) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if served != nil {
+		f.served = served
+	}
+	if signer != nil {
+		f.signer = signer
+	}
+	if claims != nil {
+		f.claims = claims
+	}
+}
+
+func newKeySet(t *testing.T) *jwtkeys.Set {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwtkeys.ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := jwtkeys.NewSet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func validClaims(issuer string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   issuer,
+		"sub":   "user-1",
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": "nonce-1",
+	}
+}
+
+func withClaims(modify func(c jwt.MapClaims)) func(issuer string) jwt.MapClaims {
+	return func(issuer string) jwt.MapClaims {
+		c := validClaims(issuer)
+		modify(c)
+		return c
+	}
+}
+
+func TestExchangeVerifiesIDToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  func(c jwt.MapClaims)
+		wantErr string
+	}{
+		{name: "valid", claims: func(c jwt.MapClaims) {}},
+		{name: "bad nonce", claims: func(c jwt.MapClaims) { c["nonce"] = "nonce-2" }, wantErr: "nonce does not match"},
+		{name: "missing nonce", claims: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: "nonce does not match"},
+		{name: "wrong audience", claims: func(c jwt.MapClaims) { c["aud"] = "other-client" }, wantErr: "invalid id_token"},
+		{name: "wrong issuer", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.test" }, wantErr: "invalid id_token"},
+		{name: "expired", claims: func(c jwt.MapClaims) {
+			c["iat"] = time.Now().Add(-time.Hour).Unix()
+			c["exp"] = time.Now().Add(-30 * time.Minute).Unix()
+		}, wantErr: "invalid id_token"},
+		{name: "missing exp", claims: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: "invalid id_token"},
+		{name: "missing sub", claims: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: "no sub"},
+		{name: "multiple audiences without azp", claims: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+		}, wantErr: "azp does not match"},
+		{name: "multiple audiences with other azp", claims: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = "other-client"
+		}, wantErr: "azp does not match"},
+		{name: "multiple audiences with azp", claims: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = testClientID
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeProvider(t)
+			f.set(nil, nil, withClaims(tt.claims))
+
+			claims, err := f.provider().Exchange(context.Background(), "code", "verifier", "nonce-1")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Exchange() error = %v", err)
+				}
+				if claims.Subject() != "user-1" || claims.Issuer() != f.URL {
+					t.Errorf("claims = %s/%s, want user-1/%s", claims.Issuer(), claims.Subject(), f.URL)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Exchange() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsTokenSignedByUnknownKey(t *testing.T) {
+	f := newFakeProvider(t)
+	f.set(nil, newKeySet(t), nil)
+
+	_, err := f.provider().Exchange(context.Background(), "code", "verifier", "nonce-1")
+	if err == nil || !strings.Contains(err.Error(), "unknown id_token kid") {
+		t.Fatalf("Exchange() error = %v, want unknown kid", err)
+	}
+}
+
+func TestUnknownKidRefreshesJWKS(t *testing.T) {
+	f := newFakeProvider(t)
+	p := f.provider()
+	ctx := context.Background()
+
+	if _, err := p.Exchange(ctx, "code", "verifier", "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.jwksFetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", got)
+	}
+
+	// Provider merotasi kunci: kid baru belum ada di cache
+	rotated := newKeySet(t)
+	f.set(rotated, rotated, nil)
+
+	// Dalam keyRefreshInterval sejak pengambilan terakhir JWKS tidak diambil ulang
+	if _, err := p.Exchange(ctx, "code", "verifier", "nonce-1"); err == nil || !strings.Contains(err.Error(), "unknown id_token kid") {
+		t.Fatalf("Exchange() within refresh interval error = %v, want unknown kid", err)
+	}
+	if got := f.jwksFetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times within refresh interval, want 1", got)
+	}
+
+	p.mu.Lock()
+	p.keysFetch = time.Now().Add(-keyRefreshInterval)
+	p.mu.Unlock()
+
+	if _, err := p.Exchange(ctx, "code", "verifier", "nonce-1"); err != nil {
+		t.Fatalf("Exchange() after rotation error = %v", err)
+	}
+	if got := f.jwksFetches.Load(); got != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", got)
+	}
+
+	// Token dengan kid palsu tidak membuat JWKS diambil lagi
+	f.set(nil, newKeySet(t), nil)
+	if _, err := p.Exchange(ctx, "code", "verifier", "nonce-1"); err == nil {
+		t.Fatal("Exchange() with forged kid succeeded")
+	}
+	if got := f.jwksFetches.Load(); got != 2 {
+		t.Fatalf("JWKS fetched %d times after forged kid, want 2", got)
+	}
+}
+
+func TestConcurrentExchangesShareFetches(t *testing.T) {
+	f := newFakeProvider(t)
+	f.latency = 50 * time.Millisecond
+	p := f.provider()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Exchange(context.Background(), "code", "verifier", "nonce-1"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Pemanggil yang datang saat fetch sedang berjalan ikut menunggu hasilnya
+	if got := f.discoveries.Load(); got != 1 {
+		t.Errorf("discovery fetched %d times, want 1", got)
+	}
+	if got := f.jwksFetches.Load(); got != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", got)
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	f := newFakeProvider(t)
+	p := New(Config{Issuer: f.URL + "/", ClientID: testClientID}, nil)
+
+	_, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err == nil || !strings.Contains(err.Error(), "does not match configured") {
+		t.Fatalf("AuthCodeURL() error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestClaimsBool(t *testing.T) {
+	c := &Claims{raw: jwt.MapClaims{"a": true, "b": "true", "c": false, "d": "yes"}}
+	for name, want := range map[string]bool{"a": true, "b": true, "c": false, "d": false, "missing": false} {
+		if got := c.Bool(name); got != want {
+			t.Errorf("Bool(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Package oidcmock identity provider OpenID Connect tiruan untuk development dan pengujian
+// login SSO tanpa provider sungguhan. Halaman /authorize langsung menyetujui login user yang
+// dipilih lewat login_hint, lalu /token memeriksa PKCE dan menerbitkan ID token RS256.
+// Jangan dipakai di production.
+package oidcmock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend-go/internal/jwtkeys"
+	"backend-go/internal/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// codeTTL masa berlaku authorization code
+const codeTTL = time.Minute
+
+// idTokenTTL masa berlaku ID token
+const idTokenTTL = 5 * time.Minute
+
+// User akun di provider tiruan
+type User struct {
+	Username string
+	Name     string
+	Email    string
+	Phone    string
+	Groups   []string
+	// AMR metode autentikasi yang dilaporkan di ID token; kosong berarti pwd
+	AMR []string
+	// Verified claim yang dilaporkan sudah diverifikasi provider (mis. "email" menjadi
+	// email_verified=true)
+	Verified []string
+}
+
+// Config pengaturan provider tiruan
+type Config struct {
+	// Issuer URL provider; kosong berarti diambil dari host request (http://host)
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Users        []User
+}
+
+// authCode authorization code yang belum ditukar
+type authCode struct {
+	user        User
+	redirectURI string
+	challenge   string
+	nonce       string
+	expiresAt   time.Time
+}
+
+// Provider http.Handler yang melayani discovery, JWKS, /authorize dan /token
+type Provider struct {
+	cfg  Config
+	keys *jwtkeys.Set
+	mux  *http.ServeMux
+
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+// New membuat provider dengan kunci RSA baru yang hanya hidup selama proses berjalan
+func New(cfg Config) (*Provider, error) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwtkeys.ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return nil, err
+	}
+	keys, err := jwtkeys.NewSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{cfg: cfg, keys: keys, mux: http.NewServeMux(), codes: make(map[string]authCode)}
+	p.mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	p.mux.HandleFunc("GET /jwks", p.jwks)
+	p.mux.HandleFunc("GET /authorize", p.authorize)
+	p.mux.HandleFunc("POST /token", p.token)
+	return p, nil
+}
+
+// ParseUsers membaca daftar user dari format "alice=admins+staff,bob": username dipisah koma,
+// grup setelah = dipisah +
+func ParseUsers(value string) []User {
+	var users []User
+	for _, item := range strings.Split(value, ",") {
+		name, groups, _ := strings.Cut(strings.TrimSpace(item), "=")
+		if name == "" {
+			continue
+		}
+		user := User{Username: name, Name: strings.ToUpper(name[:1]) + name[1:], Email: name + "@example.test"}
+		if groups != "" {
+			user.Groups = strings.Split(groups, "+")
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+func (p *Provider) issuer(r *http.Request) string {
+	if p.cfg.Issuer != "" {
+		return p.cfg.Issuer
+	}
+	return "http://" + r.Host
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := p.issuer(r)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "profile", "email", "phone"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, p.keys.JWKS())
+}
+
+// pickTemplate halaman pemilihan user bila login_hint tidak diisi
+var pickTemplate = template.Must(template.New("pick").Parse(`<!DOCTYPE html>
+<title>Mock identity provider</title>
+<h1>Sign in as</h1>
+<ul>{{range .}}<li><a href="{{.URL}}">{{.Username}}</a> {{.Groups}}</li>{{end}}</ul>
+`))
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != p.cfg.ClientID || redirectURI == "" {
+		http.Error(w, "unknown client_id or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	fail := func(code, description string) {
+		params := redirect.Query()
+		params.Set("error", code)
+		params.Set("error_description", description)
+		params.Set("state", q.Get("state"))
+		redirect.RawQuery = params.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	}
+	if q.Get("response_type") != "code" {
+		fail("unsupported_response_type", "only response_type=code is supported")
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		fail("invalid_request", "PKCE with code_challenge_method=S256 is required")
+		return
+	}
+
+	hint := q.Get("login_hint")
+	if hint == "" {
+		p.pick(w, r)
+		return
+	}
+	user, ok := p.user(hint)
+	if !ok {
+		fail("access_denied", "unknown user "+hint)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authCode{
+		user:        user,
+		redirectURI: redirectURI,
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		expiresAt:   time.Now().Add(codeTTL),
+	}
+	p.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// pick menampilkan daftar user; setiap tautan mengulang /authorize dengan login_hint
+func (p *Provider) pick(w http.ResponseWriter, r *http.Request) {
+	type choice struct {
+		Username string
+		Groups   string
+		URL      string
+	}
+	choices := make([]choice, 0, len(p.cfg.Users))
+	for _, user := range p.cfg.Users {
+		q := r.URL.Query()
+		q.Set("login_hint", user.Username)
+		choices = append(choices, choice{
+			Username: user.Username,
+			Groups:   strings.Join(user.Groups, ", "),
+			URL:      "/authorize?" + q.Encode(),
+		})
+	}
+	sort.Slice(choices, func(i, j int) bool { return choices[i].Username < choices[j].Username })
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pickTemplate.Execute(w, choices)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", "malformed form")
+		return
+	}
+	if !p.authenticateClient(r) {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	// Code sekali pakai: dihapus sebelum diperiksa
+	p.mu.Lock()
+	code, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	switch {
+	case !ok || time.Now().After(code.expiresAt):
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "unknown or expired code")
+		return
+	case r.PostForm.Get("redirect_uri") != code.redirectURI:
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match")
+		return
+	case oidc.Challenge(r.PostForm.Get("code_verifier")) != code.challenge:
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match code_challenge")
+		return
+	}
+
+	amr := code.user.AMR
+	if len(amr) == 0 {
+		amr = []string{"pwd"}
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                p.issuer(r),
+		"sub":                "mock-" + code.user.Username,
+		"aud":                p.cfg.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(idTokenTTL).Unix(),
+		"auth_time":          now.Unix(),
+		"amr":                amr,
+		"preferred_username": code.user.Username,
+		"name":               code.user.Name,
+		"email":              code.user.Email,
+		"groups":             code.user.Groups,
+	}
+	if code.nonce != "" {
+		claims["nonce"] = code.nonce
+	}
+	if code.user.Phone != "" {
+		claims["phone_number"] = code.user.Phone
+	}
+	for _, name := range code.user.Verified {
+		claims[name+"_verified"] = true
+	}
+	idToken, err := p.keys.Sign(claims)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(idTokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+// authenticateClient menerima client_secret_basic atau client_secret_post; tanpa ClientSecret
+// client dianggap public dan cukup mengirim client_id
+func (p *Provider) authenticateClient(r *http.Request) bool {
+	id, secret, basic := r.BasicAuth()
+	if basic {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != p.cfg.ClientID {
+		return false
+	}
+	return p.cfg.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(p.cfg.ClientSecret)) == 1
+}
+
+func (p *Provider) user(username string) (User, bool) {
+	for _, user := range p.cfg.Users {
+		if user.Username == username {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
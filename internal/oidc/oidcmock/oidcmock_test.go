@@ -0,0 +1,90 @@
+package oidcmock
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"backend-go/internal/oidc"
+)
+
+// TestLogin alur lengkap klien oidc terhadap provider tiruan: AuthCodeURL, /authorize, lalu Exchange
+func TestLogin(t *testing.T) {
+	mock, err := New(Config{
+		ClientID:     "backend",
+		ClientSecret: "secret",
+		Users: []User{{
+			Username: "alice",
+			Email:    "alice@example.test",
+			Groups:   []string{"admins"},
+			Verified: []string{"email"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := oidc.New(oidc.Config{
+		Issuer:       srv.URL,
+		ClientID:     "backend",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app.test/callback",
+		Scopes:       []string{"openid", "email"},
+	}, nil)
+	ctx := context.Background()
+
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL + "&login_hint=alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := location.Query().Get("state"); got != "state-1" {
+		t.Fatalf("state = %q, want state-1", got)
+	}
+	code := location.Query().Get("code")
+
+	if _, err := p.Exchange(ctx, code, "wrong-verifier", "nonce-1"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange() with wrong verifier error = %v, want invalid_grant", err)
+	}
+
+	// Code sekali pakai: percobaan di atas sudah menghabiskannya
+	resp, err = client.Get(authURL + "&login_hint=alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, _ = url.Parse(resp.Header.Get("Location"))
+
+	claims, err := p.Exchange(ctx, location.Query().Get("code"), verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if claims.Subject() != "mock-alice" || claims.String("email") != "alice@example.test" {
+		t.Errorf("claims = %s %s", claims.Subject(), claims.String("email"))
+	}
+	if !claims.Bool("email_verified") {
+		t.Error("email_verified = false, want true")
+	}
+	if groups := claims.Strings("groups"); len(groups) != 1 || groups[0] != "admins" {
+		t.Errorf("groups = %v, want [admins]", groups)
+	}
+}
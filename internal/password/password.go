@@ -0,0 +1,179 @@
+// Package password kebijakan kekuatan password dan hashing bcrypt dengan cost yang bisa dinaikkan.
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxBytes batas panjang input bcrypt; byte setelahnya tidak ikut di-hash
+const MaxBytes = 72
+
+//go:embed common.txt
+var commonList string
+
+// common daftar password umum/bocor yang dibundel bersama binary
+var common = mustParseList(strings.NewReader(commonList))
+
+// Policy aturan password baru. Nilai nol berarti aturan tersebut tidak diterapkan.
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DisallowPersonal menolak password yang memuat username atau nomor telepon pemiliknya
+	DisallowPersonal bool
+	// CheckCommon menolak password dari daftar bawaan dan Blocklist
+	CheckCommon bool
+	// Blocklist daftar tambahan per deployment (huruf kecil), lihat LoadList
+	Blocklist map[string]struct{}
+}
+
+// Violation aturan pertama yang dilanggar. Rule sama dengan kunci pesan validasi,
+// Param parameter aturan (mis. panjang minimum).
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+// Check memeriksa password terhadap kebijakan. personal berisi username dan nomor telepon
+// pemilik password. Mengembalikan nil bila password memenuhi seluruh aturan.
+func (p Policy) Check(password string, personal ...string) *Violation {
+	if len([]rune(password)) < p.MinLength {
+		return &Violation{Rule: "min", Param: strconv.Itoa(p.MinLength)}
+	}
+	if len(password) > MaxBytes {
+		return &Violation{Rule: "max", Param: strconv.Itoa(MaxBytes)}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	switch {
+	case p.RequireUpper && !upper:
+		return &Violation{Rule: "password_upper"}
+	case p.RequireLower && !lower:
+		return &Violation{Rule: "password_lower"}
+	case p.RequireDigit && !digit:
+		return &Violation{Rule: "password_digit"}
+	case p.RequireSymbol && !symbol:
+		return &Violation{Rule: "password_symbol"}
+	}
+
+	lowered := strings.ToLower(password)
+	if p.DisallowPersonal && containsPersonal(lowered, personal) {
+		return &Violation{Rule: "password_personal"}
+	}
+	if p.CheckCommon && p.isCommon(lowered) {
+		return &Violation{Rule: "password_common"}
+	}
+	return nil
+}
+
+// containsPersonal true bila password memuat salah satu data pribadi. Nomor telepon dicocokkan
+// per digit (tanpa +) dan juga delapan digit terakhirnya, agar variasi awalan negara tetap tertangkap.
+func containsPersonal(lowered string, personal []string) bool {
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, value)
+
+		if len(digits) >= 8 && len(digits) == len(strings.TrimPrefix(value, "+")) {
+			if strings.Contains(lowered, digits) || strings.Contains(lowered, digits[len(digits)-8:]) {
+				return true
+			}
+			continue
+		}
+		if len(value) >= 3 && strings.Contains(lowered, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) isCommon(lowered string) bool {
+	if _, ok := common[lowered]; ok {
+		return true
+	}
+	_, ok := p.Blocklist[lowered]
+	return ok
+}
+
+// LoadList membaca daftar password tambahan dari file teks, satu password per baris.
+// Baris kosong dan baris berawalan # diabaikan.
+func LoadList(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	list, err := parseList(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return list, nil
+}
+
+func parseList(r io.Reader) (map[string]struct{}, error) {
+	list := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+	return list, scanner.Err()
+}
+
+func mustParseList(r io.Reader) map[string]struct{} {
+	list, err := parseList(r)
+	if err != nil {
+		panic(err)
+	}
+	return list
+}
+
+// Hash membuat hash bcrypt dengan cost; cost di bawah bcrypt.MinCost memakai bcrypt.DefaultCost
+func Hash(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// NeedsRehash true bila hash dibuat dengan cost lebih rendah dari cost yang dikonfigurasi,
+// sehingga perlu di-hash ulang saat password-nya diketahui (mis. ketika login berhasil)
+func NeedsRehash(hash string, cost int) bool {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	current, err := bcrypt.Cost([]byte(hash))
+	return err == nil && current < cost
+}
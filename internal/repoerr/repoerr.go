@@ -0,0 +1,13 @@
+// Package repoerr error sentinel umum dari lapisan data. Dipisah dari package repository agar
+// apperror dapat memetakannya ke status HTTP tanpa bergantung pada repository; package
+// repository mengekspornya ulang sebagai repository.ErrNotFound dan repository.ErrDuplicate.
+package repoerr
+
+import "errors"
+
+var (
+	// ErrNotFound dikembalikan ketika data tidak ada atau sudah dihapus
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate dikembalikan ketika data melanggar unique constraint
+	ErrDuplicate = errors.New("duplicate record")
+)
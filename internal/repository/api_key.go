@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// APIKeyRepository akses data tabel api_keys
+type APIKeyRepository interface {
+	// Create menyimpan key baru (mengisi ID dan CreatedAt) beserta hash key lengkap
+	Create(ctx context.Context, key *models.APIKey, keyHash string) error
+	List(ctx context.Context, page Page) ([]models.APIKey, int, error)
+	// GetActiveByHash mencari key yang belum dicabut, belum kedaluwarsa, dan pembuatnya masih
+	// aktif (tidak dinonaktifkan atau dihapus); selain itu ErrNotFound
+	GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	// Touch memperbarui last_used_at
+	Touch(ctx context.Context, id int) error
+	// Revoke mencabut key; ErrNotFound bila tidak ada atau sudah dicabut
+	Revoke(ctx context.Context, id int) error
+	// RevokeByCreator mencabut seluruh key aktif buatan user dan mengembalikan jumlahnya
+	RevokeByCreator(ctx context.Context, userID int) (int, error)
+}
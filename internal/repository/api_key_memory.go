@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"backend-go/internal/models"
+)
+
+type memoryAPIKeyRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.apiKeyHashes[keyHash]; ok {
+		return ErrDuplicate
+	}
+	for _, other := range r.s.apiKeys {
+		if other.Prefix == key.Prefix {
+			return ErrDuplicate
+		}
+	}
+
+	key.ID = r.s.id("api_keys")
+	key.CreatedAt = now()
+	key.Scopes = slices.Clone(key.Scopes)
+	r.s.apiKeys[key.ID] = *key
+	r.s.apiKeyHashes[keyHash] = key.ID
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) List(ctx context.Context, page Page) ([]models.APIKey, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(r.s.apiKeys))
+	for _, id := range sortedIDs(r.s.apiKeys) {
+		keys = append(keys, r.s.apiKeys[id])
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	return paginate(keys, page), len(keys), nil
+}
+
+func (r *memoryAPIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, ok := r.s.apiKeyHashes[keyHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	key := r.s.apiKeys[id]
+	creator, ok := r.s.users[key.CreatedBy]
+	if !key.Active(now()) || !ok || !creator.Status || creator.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &key, nil
+}
+
+func (r *memoryAPIKeyRepository) Touch(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key, ok := r.s.apiKeys[id]
+	if !ok {
+		return nil
+	}
+	t := now()
+	key.LastUsedAt = &t
+	r.s.apiKeys[id] = key
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key, ok := r.s.apiKeys[id]
+	if !ok || key.RevokedAt != nil {
+		return ErrNotFound
+	}
+	t := now()
+	key.RevokedAt = &t
+	r.s.apiKeys[id] = key
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) RevokeByCreator(ctx context.Context, userID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t := now()
+	revoked := 0
+	for id, key := range r.s.apiKeys {
+		if key.CreatedBy != userID || key.RevokedAt != nil {
+			continue
+		}
+		key.RevokedAt = &t
+		r.s.apiKeys[id] = key
+		revoked++
+	}
+	return revoked, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresAPIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+const apiKeyColumns = `k.id, k.name, k.prefix, k.scopes, k.read_only, k.created_by, k.created_at,
+            k.expires_at, k.last_used_at, k.revoked_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Scopes,
+		&key.ReadOnly,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *postgresAPIKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO api_keys (name, prefix, key_hash, scopes, read_only, created_by, expires_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)
+         RETURNING id, created_at`,
+		key.Name, key.Prefix, keyHash, key.Scopes, key.ReadOnly, key.CreatedBy, key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+	return translateError(err)
+}
+
+func (r *postgresAPIKeyRepository) List(ctx context.Context, page Page) ([]models.APIKey, int, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys k ORDER BY k.created_at DESC LIMIT $1 OFFSET $2`,
+		page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM api_keys`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return keys, total, nil
+}
+
+func (r *postgresAPIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return scanAPIKey(r.db.QueryRow(ctx, `
+        SELECT `+apiKeyColumns+`
+        FROM api_keys k
+        JOIN users u ON u.id = k.created_by AND u.deleted_at IS NULL AND u.status
+        WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())`,
+		keyHash,
+	))
+}
+
+func (r *postgresAPIKeyRepository) Touch(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (r *postgresAPIKeyRepository) Revoke(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresAPIKeyRepository) RevokeByCreator(ctx context.Context, userID int) (int, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE created_by = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// AuditFilter filter untuk daftar audit log; nol berarti tidak difilter
+type AuditFilter struct {
+	ActorID int
+	UserID  int
+	Page
+}
+
+// AuditRepository akses data tabel audit_log
+type AuditRepository interface {
+	// Create menyimpan entri audit dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, entry *models.AuditEntry) error
+	// List daftar entri audit, yang terbaru lebih dulu
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, int, error)
+}
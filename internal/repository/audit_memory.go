@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+type memoryAuditRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryAuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	entry.ID = r.s.id("audit_log")
+	entry.CreatedAt = now()
+	r.s.auditLog[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryAuditRepository) List(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ids := sortedIDs(r.s.auditLog)
+	entries := []models.AuditEntry{}
+	// ID naik sesuai waktu, dibalik agar yang terbaru lebih dulu
+	for i := len(ids) - 1; i >= 0; i-- {
+		entry := r.s.auditLog[ids[i]]
+		if filter.ActorID != 0 && entry.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.UserID != 0 && entry.UserID != filter.UserID {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return paginate(entries, filter.Page), len(entries), nil
+}
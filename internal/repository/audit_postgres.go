@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresAuditRepository struct {
+	db *pgxpool.Pool
+}
+
+const auditColumns = `id, action, actor_id, user_id, session_id, method, path, status,
+            request_id, ip_address, detail, created_at`
+
+func (r *postgresAuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO audit_log (action, actor_id, user_id, session_id, method, path, status, request_id, ip_address, detail)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id, created_at`,
+		entry.Action,
+		entry.ActorID,
+		entry.UserID,
+		entry.SessionID,
+		entry.Method,
+		entry.Path,
+		entry.Status,
+		entry.RequestID,
+		entry.IPAddress,
+		entry.Detail,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	return translateError(err)
+}
+
+func (r *postgresAuditRepository) List(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, int, error) {
+	var where whereBuilder
+	if filter.ActorID != 0 {
+		where.add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.UserID != 0 {
+		where.add("user_id = $%d", filter.UserID)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE TRUE` + where.sql() + fmt.Sprintf(
+		" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", where.next(), where.next()+1,
+	)
+	args := append(append([]interface{}{}, where.args...), filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(
+			&e.ID,
+			&e.Action,
+			&e.ActorID,
+			&e.UserID,
+			&e.SessionID,
+			&e.Method,
+			&e.Path,
+			&e.Status,
+			&e.RequestID,
+			&e.IPAddress,
+			&e.Detail,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM audit_log WHERE TRUE` + where.sql()
+	if err := r.db.QueryRow(ctx, countQuery, where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
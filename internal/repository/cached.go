@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-go/internal/cache"
+	"backend-go/internal/models"
+)
+
+// WithRevocationCache mengembalikan salinan Repositories dengan cache in-process di depan
+// pemeriksaan pencabutan token, status session dan status user, sehingga middleware auth tidak
+// perlu ke database di setiap request. Perubahan lewat repository ini langsung terlihat;
+// perubahan dari instance lain terlihat paling lambat setelah ttl. size atau ttl nol mematikan cache.
+func (r *Repositories) WithRevocationCache(size int, ttl time.Duration) *Repositories {
+	if size <= 0 || ttl <= 0 {
+		return r
+	}
+	cached := *r
+	cached.Tokens = &cachedTokenRepository{TokenRepository: r.Tokens, revoked: cache.New[string, bool](size, ttl)}
+	cached.Sessions = &cachedSessionRepository{
+		SessionRepository: r.Sessions,
+		active:            cache.New[int, bool](size, ttl),
+		touched:           cache.New[int, bool](size, ttl),
+	}
+	cached.Users = &cachedUserRepository{UserRepository: r.Users, states: cache.New[int, models.UserAuthState](size, ttl)}
+	return &cached
+}
+
+type cachedTokenRepository struct {
+	TokenRepository
+	revoked *cache.Cache[string, bool]
+}
+
+func (r *cachedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if err := r.TokenRepository.Revoke(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+	r.revoked.Set(jti, true)
+	return nil
+}
+
+func (r *cachedTokenRepository) Use(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	used, err := r.TokenRepository.Use(ctx, jti, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	r.revoked.Set(jti, true)
+	return used, nil
+}
+
+func (r *cachedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if revoked, ok := r.revoked.Get(jti); ok {
+		return revoked, nil
+	}
+	revoked, err := r.TokenRepository.IsRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	r.revoked.Set(jti, revoked)
+	return revoked, nil
+}
+
+type cachedSessionRepository struct {
+	SessionRepository
+	active *cache.Cache[int, bool]
+	// touched session yang last_used_at-nya baru diperbarui instance ini, dilewati sampai ttl habis
+	touched *cache.Cache[int, bool]
+}
+
+func (r *cachedSessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	session, err := r.SessionRepository.Rotate(ctx, oldHash, newHash, expiresAt)
+	if errors.Is(err, ErrTokenReused) && session != nil {
+		r.active.Set(session.ID, false)
+	}
+	return session, err
+}
+
+func (r *cachedSessionRepository) IsActive(ctx context.Context, id int) (bool, error) {
+	if active, ok := r.active.Get(id); ok {
+		return active, nil
+	}
+	active, err := r.SessionRepository.IsActive(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	r.active.Set(id, active)
+	return active, nil
+}
+
+func (r *cachedSessionRepository) Revoke(ctx context.Context, id int, reason string) error {
+	if err := r.SessionRepository.Revoke(ctx, id, reason); err != nil {
+		return err
+	}
+	r.active.Set(id, false)
+	return nil
+}
+
+func (r *cachedSessionRepository) RevokeAll(ctx context.Context, userID int, reason string) ([]int, error) {
+	ids, err := r.SessionRepository.RevokeAll(ctx, userID, reason)
+	for _, id := range ids {
+		r.active.Set(id, false)
+	}
+	return ids, err
+}
+
+func (r *cachedSessionRepository) Touch(ctx context.Context, id int, staleBefore time.Time) error {
+	if _, ok := r.touched.Get(id); ok {
+		return nil
+	}
+	if err := r.SessionRepository.Touch(ctx, id, staleBefore); err != nil {
+		return err
+	}
+	r.touched.Set(id, true)
+	return nil
+}
+
+func (r *cachedSessionRepository) RevokeOthers(ctx context.Context, userID, keepID int, reason string) ([]int, error) {
+	ids, err := r.SessionRepository.RevokeOthers(ctx, userID, keepID, reason)
+	for _, id := range ids {
+		r.active.Set(id, false)
+	}
+	return ids, err
+}
+
+type cachedUserRepository struct {
+	UserRepository
+	states *cache.Cache[int, models.UserAuthState]
+}
+
+func (r *cachedUserRepository) AuthState(ctx context.Context, id int) (models.UserAuthState, error) {
+	if state, ok := r.states.Get(id); ok {
+		return state, nil
+	}
+	state, err := r.UserRepository.AuthState(ctx, id)
+	if err != nil {
+		return models.UserAuthState{}, err
+	}
+	r.states.Set(id, state)
+	return state, nil
+}
+
+func (r *cachedUserRepository) Update(ctx context.Context, id int, update UserUpdate) error {
+	err := r.UserRepository.Update(ctx, id, update)
+	r.states.Delete(id)
+	return err
+}
+
+func (r *cachedUserRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	err := r.UserRepository.SoftDelete(ctx, id, deletedBy, impersonatedBy)
+	r.states.Delete(id)
+	return err
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// CarouselFilter filter untuk daftar carousel
+type CarouselFilter struct {
+	Status *bool
+	Page
+}
+
+// CarouselUpdate perubahan data carousel; string kosong dan pointer nil berarti tidak diubah
+type CarouselUpdate struct {
+	Image       string
+	Title       string
+	Description string
+	Status      *bool
+	EditedBy    int
+	// ImpersonatedBy admin yang memerankan EditedBy; nil untuk perubahan oleh user sendiri
+	ImpersonatedBy *int
+}
+
+// CarouselRepository akses data tabel carousel
+type CarouselRepository interface {
+	// Create menyimpan carousel baru dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, carousel *models.Carousel) error
+	GetByID(ctx context.Context, id int) (*models.Carousel, error)
+	List(ctx context.Context, filter CarouselFilter) ([]models.CarouselResponse, int, error)
+	Update(ctx context.Context, id int, update CarouselUpdate) (*models.Carousel, error)
+	SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error
+}
+
+func toCarouselResponse(c models.Carousel) models.CarouselResponse {
+	response := models.CarouselResponse{
+		ID:          c.ID,
+		Image:       c.Image,
+		Title:       c.Title,
+		Description: c.Description,
+		Status:      c.Status,
+		CreatedAt:   c.CreatedAt,
+	}
+	if c.CreatedBy != nil {
+		response.CreatedBy = *c.CreatedBy
+	}
+	return response
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"backend-go/internal/models"
+)
+
+type memoryCarouselRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryCarouselRepository) Create(ctx context.Context, carousel *models.Carousel) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	carousel.ID = r.s.id("carousel")
+	carousel.CreatedAt = now()
+	r.s.carousels[carousel.ID] = *carousel
+	return nil
+}
+
+func (r *memoryCarouselRepository) GetByID(ctx context.Context, id int) (*models.Carousel, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	c, ok := r.s.carousels[id]
+	if !ok || c.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (r *memoryCarouselRepository) List(ctx context.Context, filter CarouselFilter) ([]models.CarouselResponse, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var carousels []models.Carousel
+	for _, c := range r.s.carousels {
+		if c.DeletedAt != nil {
+			continue
+		}
+		if filter.Status != nil && c.Status != *filter.Status {
+			continue
+		}
+		carousels = append(carousels, c)
+	}
+	sort.Slice(carousels, func(i, j int) bool {
+		return carousels[i].CreatedAt.After(carousels[j].CreatedAt)
+	})
+
+	responses := make([]models.CarouselResponse, 0, len(carousels))
+	for _, c := range carousels {
+		responses = append(responses, toCarouselResponse(c))
+	}
+	return paginate(responses, filter.Page), len(responses), nil
+}
+
+func (r *memoryCarouselRepository) Update(ctx context.Context, id int, update CarouselUpdate) (*models.Carousel, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.carousels[id]
+	if !ok || c.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	if update.Image != "" {
+		c.Image = update.Image
+	}
+	if update.Title != "" {
+		c.Title = update.Title
+	}
+	if update.Description != "" {
+		c.Description = update.Description
+	}
+	if update.Status != nil {
+		c.Status = *update.Status
+	}
+	editedAt := now()
+	c.EditedAt = &editedAt
+	c.EditedBy = &update.EditedBy
+	c.ImpersonatedBy = update.ImpersonatedBy
+
+	r.s.carousels[id] = c
+	return &c, nil
+}
+
+func (r *memoryCarouselRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.carousels[id]
+	if !ok || c.DeletedAt != nil {
+		return ErrNotFound
+	}
+	deletedAt := now()
+	c.DeletedAt = &deletedAt
+	c.DeletedBy = &deletedBy
+	c.ImpersonatedBy = impersonatedBy
+
+	r.s.carousels[id] = c
+	return nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresCarouselRepository struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresCarouselRepository) Create(ctx context.Context, carousel *models.Carousel) error {
+	query := `
+        INSERT INTO carousel (
+            image,
+            title,
+            description,
+            status,
+            created_by,
+            impersonated_by
+        ) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRow(ctx, query,
+		carousel.Image,
+		carousel.Title,
+		carousel.Description,
+		carousel.Status,
+		carousel.CreatedBy,
+		carousel.ImpersonatedBy,
+	).Scan(&carousel.ID, &carousel.CreatedAt)
+
+	return translateError(err)
+}
+
+func (r *postgresCarouselRepository) GetByID(ctx context.Context, id int) (*models.Carousel, error) {
+	query := `
+        SELECT
+            id, image, title, description, status,
+            created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by
+        FROM carousel
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+
+	var carousel models.Carousel
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&carousel.ID,
+		&carousel.Image,
+		&carousel.Title,
+		&carousel.Description,
+		&carousel.Status,
+		&carousel.CreatedAt,
+		&carousel.CreatedBy,
+		&carousel.EditedAt,
+		&carousel.EditedBy,
+		&carousel.DeletedAt,
+		&carousel.DeletedBy,
+		&carousel.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &carousel, nil
+}
+
+func (r *postgresCarouselRepository) List(ctx context.Context, filter CarouselFilter) ([]models.CarouselResponse, int, error) {
+	var where whereBuilder
+	if filter.Status != nil {
+		where.add("status = $%d", *filter.Status)
+	}
+
+	query := fmt.Sprintf(`SELECT
+                id, image, title, description, status, created_at, created_by
+              FROM carousel
+              WHERE deleted_at IS NULL%s
+              ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		where.sql(), where.next(), where.next()+1,
+	)
+	args := append(append([]interface{}{}, where.args...), filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	carousels := []models.CarouselResponse{}
+	for rows.Next() {
+		var (
+			carousel  models.CarouselResponse
+			createdBy *int
+		)
+		err := rows.Scan(
+			&carousel.ID,
+			&carousel.Image,
+			&carousel.Title,
+			&carousel.Description,
+			&carousel.Status,
+			&carousel.CreatedAt,
+			&createdBy,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if createdBy != nil {
+			carousel.CreatedBy = *createdBy
+		}
+		carousels = append(carousels, carousel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM carousel WHERE deleted_at IS NULL` + where.sql()
+	if err := r.db.QueryRow(ctx, countQuery, where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return carousels, total, nil
+}
+
+func (r *postgresCarouselRepository) Update(ctx context.Context, id int, update CarouselUpdate) (*models.Carousel, error) {
+	query := `UPDATE carousel SET
+                image = COALESCE(NULLIF($1, ''), image),
+                title = COALESCE(NULLIF($2, ''), title),
+                description = COALESCE(NULLIF($3, ''), description),
+                status = COALESCE($4, status),
+                edited_by = $5,
+                impersonated_by = $6
+              WHERE id = $7 AND deleted_at IS NULL
+              RETURNING id, image, title, description, status,
+                created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by`
+
+	var carousel models.Carousel
+	err := r.db.QueryRow(ctx, query,
+		update.Image,
+		update.Title,
+		update.Description,
+		update.Status,
+		update.EditedBy,
+		update.ImpersonatedBy,
+		id,
+	).Scan(
+		&carousel.ID,
+		&carousel.Image,
+		&carousel.Title,
+		&carousel.Description,
+		&carousel.Status,
+		&carousel.CreatedAt,
+		&carousel.CreatedBy,
+		&carousel.EditedAt,
+		&carousel.EditedBy,
+		&carousel.DeletedAt,
+		&carousel.DeletedBy,
+		&carousel.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &carousel, nil
+}
+
+func (r *postgresCarouselRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	query := `
+        UPDATE carousel
+        SET deleted_at = $1, deleted_by = $2, impersonated_by = $3
+        WHERE id = $4 AND deleted_at IS NULL
+    `
+
+	result, err := r.db.Exec(ctx, query, time.Now().UTC(), deletedBy, impersonatedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// InviteRepository akses data tabel invites
+type InviteRepository interface {
+	// Create menyimpan undangan baru beserta hash kodenya dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, invite *models.Invite, codeHash string) error
+	List(ctx context.Context, page Page) ([]models.Invite, int, error)
+	// Revoke membatalkan undangan yang belum dipakai; ErrNotFound bila tidak ada atau sudah dipakai
+	Revoke(ctx context.Context, id int) error
+	// Redeem memakai undangan dan membuat user dengan role dari undangan dalam satu transaksi.
+	// Undangan yang tidak ada, kedaluwarsa, dibatalkan, atau sudah dipakai menghasilkan ErrInviteInvalid.
+	Redeem(ctx context.Context, codeHash string, user *models.User) error
+}
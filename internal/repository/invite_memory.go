@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"backend-go/internal/models"
+)
+
+type memoryInviteRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryInviteRepository) Create(ctx context.Context, invite *models.Invite, codeHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.inviteCodes[codeHash]; ok {
+		return ErrDuplicate
+	}
+
+	invite.ID = r.s.id("invites")
+	invite.CreatedAt = now()
+	r.s.invites[invite.ID] = *invite
+	r.s.inviteCodes[codeHash] = invite.ID
+	return nil
+}
+
+func (r *memoryInviteRepository) List(ctx context.Context, page Page) ([]models.Invite, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	invites := make([]models.Invite, 0, len(r.s.invites))
+	for _, id := range sortedIDs(r.s.invites) {
+		invites = append(invites, r.s.invites[id])
+	}
+	sort.SliceStable(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+
+	return paginate(invites, page), len(invites), nil
+}
+
+func (r *memoryInviteRepository) Revoke(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	invite, ok := r.s.invites[id]
+	if !ok || invite.UsedAt != nil || invite.RevokedAt != nil {
+		return ErrNotFound
+	}
+	t := now()
+	invite.RevokedAt = &t
+	r.s.invites[id] = invite
+	return nil
+}
+
+func (r *memoryInviteRepository) Redeem(ctx context.Context, codeHash string, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, ok := r.s.inviteCodes[codeHash]
+	if !ok {
+		return ErrInviteInvalid
+	}
+	invite := r.s.invites[id]
+	t := now()
+	if invite.UsedAt != nil || invite.RevokedAt != nil || !t.Before(invite.ExpiresAt) {
+		return ErrInviteInvalid
+	}
+
+	user.Role = invite.Role
+	user.CreatedBy = &invite.CreatedBy
+	if err := (&memoryUserRepository{s: r.s}).insert(user); err != nil {
+		return err
+	}
+
+	invite.UsedAt, invite.UsedBy = &t, &user.ID
+	r.s.invites[id] = invite
+	return nil
+}
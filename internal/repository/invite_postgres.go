@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresInviteRepository struct {
+	db *pgxpool.Pool
+}
+
+const inviteColumns = `id, role, created_by, created_at, expires_at, used_at, used_by, revoked_at`
+
+func scanInvite(row interface{ Scan(...interface{}) error }) (*models.Invite, error) {
+	var invite models.Invite
+	err := row.Scan(
+		&invite.ID,
+		&invite.Role,
+		&invite.CreatedBy,
+		&invite.CreatedAt,
+		&invite.ExpiresAt,
+		&invite.UsedAt,
+		&invite.UsedBy,
+		&invite.RevokedAt,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &invite, nil
+}
+
+func (r *postgresInviteRepository) Create(ctx context.Context, invite *models.Invite, codeHash string) error {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO invites (code_hash, role, created_by, expires_at)
+         VALUES ($1, $2, $3, $4)
+         RETURNING id, created_at`,
+		codeHash, invite.Role, invite.CreatedBy, invite.ExpiresAt,
+	).Scan(&invite.ID, &invite.CreatedAt)
+	return translateError(err)
+}
+
+func (r *postgresInviteRepository) List(ctx context.Context, page Page) ([]models.Invite, int, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+inviteColumns+` FROM invites ORDER BY created_at DESC LIMIT $1 OFFSET $2`,
+		page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	invites := []models.Invite{}
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invites = append(invites, *invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM invites`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return invites, total, nil
+}
+
+func (r *postgresInviteRepository) Revoke(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE invites SET revoked_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresInviteRepository) Redeem(ctx context.Context, codeHash string, user *models.User) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var (
+			inviteID  int
+			createdBy int
+		)
+		// FOR UPDATE membuat redeem bersamaan menunggu; yang kedua tidak lagi lolos syarat used_at IS NULL
+		err := tx.QueryRow(ctx, `
+            SELECT id, role, created_by FROM invites
+            WHERE code_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+            FOR UPDATE`, codeHash,
+		).Scan(&inviteID, &user.Role, &createdBy)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInviteInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		user.CreatedBy = &createdBy
+		if err := insertUser(ctx, tx, user); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE invites SET used_at = NOW(), used_by = $2 WHERE id = $1`, inviteID, user.ID)
+		return err
+	})
+}
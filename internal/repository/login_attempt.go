@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// LockoutEventFilter filter untuk daftar lockout event
+type LockoutEventFilter struct {
+	Username string
+	Page
+}
+
+// LoginThrottle aturan percobaan login per username. Hitungan dimulai ulang bila percobaan
+// terakhir lebih lama dari Since. Mulai hitungan kedua, percobaan berikutnya harus menunggu
+// Delay sejak percobaan terakhir, berlipat dua setiap percobaan, paling lama MaxDelay.
+type LoginThrottle struct {
+	Since    time.Time
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// Wait jeda yang diwajibkan setelah failures percobaan yang belum dihapus
+func (t LoginThrottle) Wait(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	if shift := failures - 2; shift < 16 {
+		return min(t.Delay<<shift, t.MaxDelay)
+	}
+	return t.MaxDelay
+}
+
+// allow menerapkan throttle pada status username. Bila percobaan diizinkan, hitungan langsung
+// ditambah dan waktu percobaan dicatat; bila tidak, status tidak diubah.
+func (t LoginThrottle) allow(failures *models.LoginFailures, now time.Time) bool {
+	if failures.LockedUntil != nil && now.Before(*failures.LockedUntil) {
+		return false
+	}
+	if failures.LastFailureAt.Before(t.Since) {
+		failures.Failures = 0
+	}
+	if now.Before(failures.LastFailureAt.Add(t.Wait(failures.Failures))) {
+		return false
+	}
+	failures.Failures++
+	failures.LastFailureAt = now
+	return true
+}
+
+// LoginAttemptRepository akses data tabel login_failures dan lockout_events
+type LoginAttemptRepository interface {
+	// Attempt memeriksa throttle dan, bila diizinkan, mencatat percobaan sebagai kegagalan
+	// sebelum kredensial diperiksa, dalam satu langkah atomik per username sehingga percobaan
+	// paralel tidak bisa melewati jeda atau lockout. Mengembalikan status setelah percobaan
+	// dicatat, atau status yang menolak percobaan bila tidak diizinkan.
+	Attempt(ctx context.Context, username string, throttle LoginThrottle) (*models.LoginFailures, bool, error)
+	// Lock mengunci username sampai event.LockedUntil, mengosongkan hitungan gagal, dan mencatat event
+	Lock(ctx context.Context, event *models.LockoutEvent) error
+	// Clear menghapus status kegagalan setelah login berhasil
+	Clear(ctx context.Context, username string) error
+	// Unlock menghapus status kegagalan dan mencatat event bila username sedang terkunci.
+	// Mengembalikan true bila username memang terkunci.
+	Unlock(ctx context.Context, event *models.LockoutEvent) (bool, error)
+	ListEvents(ctx context.Context, filter LockoutEventFilter) ([]models.LockoutEvent, int, error)
+	// PurgeStale menghapus status kegagalan yang tidak lagi berpengaruh sebelum waktu before
+	PurgeStale(ctx context.Context, before time.Time) (int64, error)
+}
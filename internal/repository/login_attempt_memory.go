@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+type memoryLoginAttemptRepository struct {
+	s *memoryStore
+}
+
+// addLockoutEvent pemanggil harus memegang lock tulis
+func (r *memoryLoginAttemptRepository) addLockoutEvent(event *models.LockoutEvent) {
+	event.ID = r.s.id("lockout_events")
+	event.CreatedAt = now()
+	r.s.lockoutEvents[event.ID] = *event
+}
+
+func (r *memoryLoginAttemptRepository) Attempt(ctx context.Context, username string, throttle LoginThrottle) (*models.LoginFailures, bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	failures, ok := r.s.loginFailures[username]
+	if !ok {
+		failures = models.LoginFailures{Username: username}
+	}
+	allowed := throttle.allow(&failures, now())
+	if allowed {
+		r.s.loginFailures[username] = failures
+	}
+	return &failures, allowed, nil
+}
+
+func (r *memoryLoginAttemptRepository) Lock(ctx context.Context, event *models.LockoutEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	failures := r.s.loginFailures[event.Username]
+	failures.Username = event.Username
+	failures.Failures = 0
+	failures.LockedUntil = event.LockedUntil
+	r.s.loginFailures[event.Username] = failures
+
+	r.addLockoutEvent(event)
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) Clear(ctx context.Context, username string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.loginFailures, username)
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) Unlock(ctx context.Context, event *models.LockoutEvent) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	failures, ok := r.s.loginFailures[event.Username]
+	delete(r.s.loginFailures, event.Username)
+	if !ok || failures.LockedUntil == nil || !now().Before(*failures.LockedUntil) {
+		return false, nil
+	}
+
+	r.addLockoutEvent(event)
+	return true, nil
+}
+
+func (r *memoryLoginAttemptRepository) ListEvents(ctx context.Context, filter LockoutEventFilter) ([]models.LockoutEvent, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ids := sortedIDs(r.s.lockoutEvents)
+	events := []models.LockoutEvent{}
+	// ID naik sesuai waktu, dibalik agar yang terbaru lebih dulu
+	for i := len(ids) - 1; i >= 0; i-- {
+		event := r.s.lockoutEvents[ids[i]]
+		if filter.Username != "" && event.Username != filter.Username {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return paginate(events, filter.Page), len(events), nil
+}
+
+func (r *memoryLoginAttemptRepository) PurgeStale(ctx context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var n int64
+	for username, failures := range r.s.loginFailures {
+		if failures.LastFailureAt.Before(before) && (failures.LockedUntil == nil || failures.LockedUntil.Before(before)) {
+			delete(r.s.loginFailures, username)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresLoginAttemptRepository struct {
+	db *pgxpool.Pool
+}
+
+const lockoutEventColumns = `id, username, user_id, event, failures, ip_address, actor_id, locked_until, created_at`
+
+func scanLockoutEvent(row interface{ Scan(...interface{}) error }) (*models.LockoutEvent, error) {
+	var event models.LockoutEvent
+	err := row.Scan(
+		&event.ID,
+		&event.Username,
+		&event.UserID,
+		&event.Event,
+		&event.Failures,
+		&event.IPAddress,
+		&event.ActorID,
+		&event.LockedUntil,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &event, nil
+}
+
+// insertLockoutEvent mengisi ID dan CreatedAt event
+func insertLockoutEvent(ctx context.Context, tx pgx.Tx, event *models.LockoutEvent) error {
+	return tx.QueryRow(ctx, `
+        INSERT INTO lockout_events (username, user_id, event, failures, ip_address, actor_id, locked_until)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at`,
+		event.Username, event.UserID, event.Event, event.Failures, event.IPAddress, event.ActorID, event.LockedUntil,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+// Attempt mengunci baris username (dibuat bila belum ada) selama pemeriksaan sehingga percobaan
+// paralel, juga dari instance lain, diperiksa satu per satu
+func (r *postgresLoginAttemptRepository) Attempt(ctx context.Context, username string, throttle LoginThrottle) (*models.LoginFailures, bool, error) {
+	failures := models.LoginFailures{Username: username}
+	var allowed bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO login_failures (username, failures, last_failure_at)
+            VALUES ($1, 0, 'epoch')
+            ON CONFLICT (username) DO NOTHING`,
+			username,
+		)
+		if err != nil {
+			return err
+		}
+
+		var now time.Time
+		err = tx.QueryRow(ctx, `
+            SELECT failures, last_failure_at, locked_until, NOW() FROM login_failures
+            WHERE username = $1 FOR UPDATE`,
+			username,
+		).Scan(&failures.Failures, &failures.LastFailureAt, &failures.LockedUntil, &now)
+		if err != nil {
+			return err
+		}
+
+		if allowed = throttle.allow(&failures, now); !allowed {
+			return nil
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE login_failures SET failures = $2, last_failure_at = $3 WHERE username = $1`,
+			username, failures.Failures, failures.LastFailureAt,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &failures, allowed, nil
+}
+
+func (r *postgresLoginAttemptRepository) Lock(ctx context.Context, event *models.LockoutEvent) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO login_failures (username, failures, locked_until)
+            VALUES ($1, 0, $2)
+            ON CONFLICT (username) DO UPDATE SET failures = 0, locked_until = $2`,
+			event.Username, event.LockedUntil,
+		)
+		if err != nil {
+			return err
+		}
+		return insertLockoutEvent(ctx, tx, event)
+	})
+}
+
+func (r *postgresLoginAttemptRepository) Clear(ctx context.Context, username string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM login_failures WHERE username = $1`, username)
+	return err
+}
+
+func (r *postgresLoginAttemptRepository) Unlock(ctx context.Context, event *models.LockoutEvent) (bool, error) {
+	var locked bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+            DELETE FROM login_failures WHERE username = $1
+            RETURNING COALESCE(locked_until > NOW(), FALSE)`,
+			event.Username,
+		).Scan(&locked)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil || !locked {
+			return err
+		}
+		return insertLockoutEvent(ctx, tx, event)
+	})
+	return locked, err
+}
+
+func (r *postgresLoginAttemptRepository) ListEvents(ctx context.Context, filter LockoutEventFilter) ([]models.LockoutEvent, int, error) {
+	var where whereBuilder
+	if filter.Username != "" {
+		where.add("username = $%d", filter.Username)
+	}
+
+	query := `SELECT ` + lockoutEventColumns + ` FROM lockout_events WHERE TRUE` + where.sql() + fmt.Sprintf(
+		" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", where.next(), where.next()+1,
+	)
+	args := append(append([]interface{}{}, where.args...), filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []models.LockoutEvent{}
+	for rows.Next() {
+		event, err := scanLockoutEvent(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM lockout_events WHERE TRUE` + where.sql()
+	if err := r.db.QueryRow(ctx, countQuery, where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func (r *postgresLoginAttemptRepository) PurgeStale(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM login_failures WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`,
+		before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
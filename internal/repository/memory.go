@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// memoryStore penyimpanan bersama untuk seluruh repository in-memory
+type memoryStore struct {
+	mu sync.RWMutex
+
+	nextID map[string]int
+
+	users            map[int]models.User
+	carousels        map[int]models.Carousel
+	products         map[int]models.Product
+	portfolioImages  map[int]models.PortfolioImage
+	portfolioReviews map[int]models.PortfolioReview
+	messages         map[int]models.Message
+	revokedTokens    map[string]time.Time // jti token yang dicabut -> waktu kedaluwarsa
+	sessions         map[int]models.Session
+	refreshTokens    map[string]memoryRefreshToken
+	invites          map[int]models.Invite
+	inviteCodes      map[string]int // hash kode undangan -> ID undangan
+	passwordResets   map[string]memoryPasswordReset
+	loginFailures    map[string]models.LoginFailures
+	lockoutEvents    map[int]models.LockoutEvent
+	totps            map[int]models.TOTP // ID user -> pendaftaran TOTP
+	recoveryCodes    map[int]memoryRecoveryCode
+	apiKeys          map[int]models.APIKey
+	apiKeyHashes     map[string]int              // hash API key -> ID key
+	oidcStates       map[string]models.OIDCState // hash state -> login SSO yang berjalan
+	userIdentities   map[int]models.UserIdentity
+	auditLog         map[int]models.AuditEntry
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		nextID:           make(map[string]int),
+		users:            make(map[int]models.User),
+		carousels:        make(map[int]models.Carousel),
+		products:         make(map[int]models.Product),
+		portfolioImages:  make(map[int]models.PortfolioImage),
+		portfolioReviews: make(map[int]models.PortfolioReview),
+		messages:         make(map[int]models.Message),
+		revokedTokens:    make(map[string]time.Time),
+		sessions:         make(map[int]models.Session),
+		refreshTokens:    make(map[string]memoryRefreshToken),
+		invites:          make(map[int]models.Invite),
+		inviteCodes:      make(map[string]int),
+		passwordResets:   make(map[string]memoryPasswordReset),
+		loginFailures:    make(map[string]models.LoginFailures),
+		lockoutEvents:    make(map[int]models.LockoutEvent),
+		totps:            make(map[int]models.TOTP),
+		recoveryCodes:    make(map[int]memoryRecoveryCode),
+		apiKeys:          make(map[int]models.APIKey),
+		apiKeyHashes:     make(map[string]int),
+		oidcStates:       make(map[string]models.OIDCState),
+		userIdentities:   make(map[int]models.UserIdentity),
+		auditLog:         make(map[int]models.AuditEntry),
+	}
+}
+
+// id menghasilkan ID berikutnya untuk tabel; pemanggil harus memegang lock tulis
+func (s *memoryStore) id(table string) int {
+	s.nextID[table]++
+	return s.nextID[table]
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// paginate memotong slice sesuai limit dan offset
+func paginate[T any](items []T, page Page) []T {
+	if page.Offset >= len(items) {
+		return []T{}
+	}
+	items = items[page.Offset:]
+	if page.Limit > 0 && page.Limit < len(items) {
+		items = items[:page.Limit]
+	}
+	return items
+}
+
+// sortedIDs mengembalikan key map secara terurut agar hasil list deterministik
+func sortedIDs[T any](m map[int]T) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// productInfo mengambil judul dan gambar produk untuk hasil join; pemanggil harus memegang lock
+func (s *memoryStore) productInfo(id *int) (name, image *string) {
+	if id == nil {
+		return nil, nil
+	}
+	p, ok := s.products[*id]
+	if !ok {
+		return nil, nil
+	}
+	return &p.Title, &p.Image
+}
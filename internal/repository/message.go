@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// MessageFilter filter untuk daftar pesan
+type MessageFilter struct {
+	ProductID int
+	Page
+}
+
+// MessageUpdate perubahan data pesan; pointer nil dan string kosong berarti tidak diubah
+type MessageUpdate struct {
+	Name         *string
+	Company      *string
+	ProductID    *int
+	Address      *string
+	Description  *string
+	DateSchedule *time.Time
+	Phone        string
+	EditedBy     int
+	// ImpersonatedBy admin yang memerankan EditedBy; nil untuk perubahan oleh user sendiri
+	ImpersonatedBy *int
+}
+
+// MessageRepository akses data tabel messages_user
+type MessageRepository interface {
+	// Create menyimpan pesan baru dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, message *models.Message) error
+	GetByID(ctx context.Context, id int) (*models.MessageWithProduct, error)
+	List(ctx context.Context, filter MessageFilter) ([]models.MessageWithProduct, int, error)
+	Update(ctx context.Context, id int, update MessageUpdate) (*models.Message, error)
+	SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"backend-go/internal/models"
+)
+
+type memoryMessageRepository struct {
+	s *memoryStore
+}
+
+// withProduct melengkapi pesan dengan data produk; pemanggil harus memegang lock
+func (r *memoryMessageRepository) withProduct(m models.Message) models.MessageWithProduct {
+	name, image := r.s.productInfo(m.ProductID)
+	return models.MessageWithProduct{
+		ID:           m.ID,
+		Name:         m.Name,
+		Company:      m.Company,
+		ProductID:    m.ProductID,
+		Address:      m.Address,
+		Description:  m.Description,
+		DateSchedule: m.DateSchedule,
+		Phone:        m.Phone,
+		CreatedAt:    m.CreatedAt,
+		CreatedBy:    m.CreatedBy,
+		EditedAt:     m.EditedAt,
+		ProductName:  name,
+		ProductImage: image,
+	}
+}
+
+func (r *memoryMessageRepository) Create(ctx context.Context, message *models.Message) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	message.ID = r.s.id("messages_user")
+	message.CreatedAt = now()
+	r.s.messages[message.ID] = *message
+	return nil
+}
+
+func (r *memoryMessageRepository) GetByID(ctx context.Context, id int) (*models.MessageWithProduct, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	m, ok := r.s.messages[id]
+	if !ok || m.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	result := r.withProduct(m)
+	return &result, nil
+}
+
+func (r *memoryMessageRepository) List(ctx context.Context, filter MessageFilter) ([]models.MessageWithProduct, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var messages []models.Message
+	for _, m := range r.s.messages {
+		if m.DeletedAt != nil {
+			continue
+		}
+		if filter.ProductID > 0 && (m.ProductID == nil || *m.ProductID != filter.ProductID) {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.After(messages[j].CreatedAt)
+	})
+
+	results := make([]models.MessageWithProduct, 0, len(messages))
+	for _, m := range messages {
+		results = append(results, r.withProduct(m))
+	}
+	return paginate(results, filter.Page), len(results), nil
+}
+
+func (r *memoryMessageRepository) Update(ctx context.Context, id int, update MessageUpdate) (*models.Message, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	m, ok := r.s.messages[id]
+	if !ok || m.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	if update.Name != nil && *update.Name != "" {
+		m.Name = *update.Name
+	}
+	if update.Company != nil {
+		m.Company = *update.Company
+	}
+	if update.ProductID != nil {
+		m.ProductID = update.ProductID
+	}
+	if update.Address != nil {
+		m.Address = *update.Address
+	}
+	if update.Description != nil && *update.Description != "" {
+		m.Description = *update.Description
+	}
+	if update.DateSchedule != nil {
+		m.DateSchedule = update.DateSchedule
+	}
+	if update.Phone != "" {
+		m.Phone = update.Phone
+	}
+	editedAt := now()
+	m.EditedAt = &editedAt
+	m.EditedBy = &update.EditedBy
+	m.ImpersonatedBy = update.ImpersonatedBy
+
+	r.s.messages[id] = m
+	return &m, nil
+}
+
+func (r *memoryMessageRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	m, ok := r.s.messages[id]
+	if !ok || m.DeletedAt != nil {
+		return ErrNotFound
+	}
+	deletedAt := now()
+	m.DeletedAt = &deletedAt
+	m.DeletedBy = &deletedBy
+	m.ImpersonatedBy = impersonatedBy
+
+	r.s.messages[id] = m
+	return nil
+}
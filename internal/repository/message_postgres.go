@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresMessageRepository struct {
+	db *pgxpool.Pool
+}
+
+const messageColumns = `id, name, company, id_product, address, description, date_schedule, phone,
+            created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by`
+
+const messageWithProductQuery = `
+        SELECT
+            m.id,
+            m.name,
+            m.company,
+            m.id_product,
+            m.address,
+            m.description,
+            m.date_schedule,
+            m.phone,
+            m.created_at,
+            m.created_by,
+            m.edited_at,
+            p.title as product_name,
+            p.image as product_image
+        FROM messages_user m
+        LEFT JOIN products p ON m.id_product = p.id
+        WHERE m.deleted_at IS NULL`
+
+func scanMessageWithProduct(row interface{ Scan(...interface{}) error }) (*models.MessageWithProduct, error) {
+	var msg models.MessageWithProduct
+	err := row.Scan(
+		&msg.ID,
+		&msg.Name,
+		&msg.Company,
+		&msg.ProductID,
+		&msg.Address,
+		&msg.Description,
+		&msg.DateSchedule,
+		&msg.Phone,
+		&msg.CreatedAt,
+		&msg.CreatedBy,
+		&msg.EditedAt,
+		&msg.ProductName,
+		&msg.ProductImage,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &msg, nil
+}
+
+func (r *postgresMessageRepository) Create(ctx context.Context, message *models.Message) error {
+	query := `
+        INSERT INTO messages_user (
+            name,
+            company,
+            id_product,
+            address,
+            description,
+            date_schedule,
+            phone,
+            created_by,
+            impersonated_by
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRow(ctx, query,
+		message.Name,
+		message.Company,
+		message.ProductID,
+		message.Address,
+		message.Description,
+		message.DateSchedule,
+		message.Phone,
+		message.CreatedBy,
+		message.ImpersonatedBy,
+	).Scan(&message.ID, &message.CreatedAt)
+
+	return translateError(err)
+}
+
+func (r *postgresMessageRepository) GetByID(ctx context.Context, id int) (*models.MessageWithProduct, error) {
+	query := messageWithProductQuery + ` AND m.id = $1`
+	return scanMessageWithProduct(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *postgresMessageRepository) List(ctx context.Context, filter MessageFilter) ([]models.MessageWithProduct, int, error) {
+	var where whereBuilder
+	if filter.ProductID > 0 {
+		where.add("m.id_product = $%d", filter.ProductID)
+	}
+
+	query := messageWithProductQuery + where.sql() + fmt.Sprintf(
+		" ORDER BY m.created_at DESC LIMIT $%d OFFSET $%d", where.next(), where.next()+1,
+	)
+	args := append(append([]interface{}{}, where.args...), filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages := []models.MessageWithProduct{}
+	for rows.Next() {
+		msg, err := scanMessageWithProduct(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, *msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM messages_user m WHERE m.deleted_at IS NULL` + where.sql()
+	if err := r.db.QueryRow(ctx, countQuery, where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *postgresMessageRepository) Update(ctx context.Context, id int, update MessageUpdate) (*models.Message, error) {
+	query := `
+        UPDATE messages_user SET
+            name = COALESCE(NULLIF($1, ''), name),
+            company = COALESCE($2, company),
+            id_product = COALESCE($3, id_product),
+            address = COALESCE($4, address),
+            description = COALESCE(NULLIF($5, ''), description),
+            date_schedule = COALESCE($6, date_schedule),
+            phone = COALESCE(NULLIF($7, ''), phone),
+            edited_by = $8,
+            impersonated_by = $9
+        WHERE id = $10 AND deleted_at IS NULL
+        RETURNING ` + messageColumns
+
+	var message models.Message
+	err := r.db.QueryRow(ctx, query,
+		update.Name,
+		update.Company,
+		update.ProductID,
+		update.Address,
+		update.Description,
+		update.DateSchedule,
+		update.Phone,
+		update.EditedBy,
+		update.ImpersonatedBy,
+		id,
+	).Scan(
+		&message.ID,
+		&message.Name,
+		&message.Company,
+		&message.ProductID,
+		&message.Address,
+		&message.Description,
+		&message.DateSchedule,
+		&message.Phone,
+		&message.CreatedAt,
+		&message.CreatedBy,
+		&message.EditedAt,
+		&message.EditedBy,
+		&message.DeletedAt,
+		&message.DeletedBy,
+		&message.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &message, nil
+}
+
+func (r *postgresMessageRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	query := `
+        UPDATE messages_user
+        SET
+            deleted_at = $1,
+            deleted_by = $2,
+            impersonated_by = $3
+        WHERE
+            id = $4
+            AND deleted_at IS NULL
+    `
+
+	result, err := r.db.Exec(ctx, query, time.Now().UTC(), deletedBy, impersonatedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
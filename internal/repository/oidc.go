@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// OIDCRepository akses data tabel oidc_states dan user_identities
+type OIDCRepository interface {
+	// CreateState menyimpan login SSO yang sedang berjalan berdasarkan hash state
+	CreateState(ctx context.Context, stateHash string, state models.OIDCState) error
+	// ConsumeState mengambil lalu menghapus state sehingga hanya bisa dipakai sekali;
+	// ErrNotFound bila tidak ada atau sudah kedaluwarsa
+	ConsumeState(ctx context.Context, stateHash string) (*models.OIDCState, error)
+	// PurgeStates menghapus state yang kedaluwarsa sebelum waktu before
+	PurgeStates(ctx context.Context, before time.Time) (int64, error)
+	// Login mencatat waktu login lewat akun provider dan mengembalikan ID user yang tertaut;
+	// ErrNotFound bila belum tertaut atau user-nya sudah dihapus
+	Login(ctx context.Context, issuer, subject string) (int, error)
+	// Link menautkan akun provider ke user yang sudah ada
+	Link(ctx context.Context, userID int, issuer, subject string) error
+	// Provision membuat user baru beserta tautan akun provider dalam satu transaksi dan
+	// mengisi ID serta CreatedAt user
+	Provision(ctx context.Context, user *models.User, issuer, subject string) error
+}
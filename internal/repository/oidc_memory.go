@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+type memoryOIDCRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryOIDCRepository) CreateState(ctx context.Context, stateHash string, state models.OIDCState) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.oidcStates[stateHash]; ok {
+		return ErrDuplicate
+	}
+	r.s.oidcStates[stateHash] = state
+	return nil
+}
+
+func (r *memoryOIDCRepository) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCState, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	state, ok := r.s.oidcStates[stateHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(r.s.oidcStates, stateHash)
+	if !now().Before(state.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &state, nil
+}
+
+func (r *memoryOIDCRepository) PurgeStates(ctx context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var n int64
+	for hash, state := range r.s.oidcStates {
+		if state.ExpiresAt.Before(before) {
+			delete(r.s.oidcStates, hash)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memoryOIDCRepository) Login(ctx context.Context, issuer, subject string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, ok := r.identity(issuer, subject)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	identity := r.s.userIdentities[id]
+	if u, ok := r.s.users[identity.UserID]; !ok || u.DeletedAt != nil {
+		return 0, ErrNotFound
+	}
+	identity.LastLoginAt = now()
+	r.s.userIdentities[id] = identity
+	return identity.UserID, nil
+}
+
+func (r *memoryOIDCRepository) Link(ctx context.Context, userID int, issuer, subject string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if u, ok := r.s.users[userID]; !ok || u.DeletedAt != nil {
+		return ErrNotFound
+	}
+	return r.insert(userID, issuer, subject)
+}
+
+func (r *memoryOIDCRepository) Provision(ctx context.Context, user *models.User, issuer, subject string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// Diperiksa sebelum user dibuat agar kegagalan tidak meninggalkan user tanpa tautan
+	if id, ok := r.identity(issuer, subject); ok && r.s.users[r.s.userIdentities[id].UserID].DeletedAt == nil {
+		return ErrDuplicate
+	}
+	if err := (&memoryUserRepository{s: r.s}).insert(user); err != nil {
+		return err
+	}
+	return r.insert(user.ID, issuer, subject)
+}
+
+// identity mencari ID tautan akun provider; pemanggil harus memegang lock
+func (r *memoryOIDCRepository) identity(issuer, subject string) (int, bool) {
+	for id, identity := range r.s.userIdentities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// insert menautkan akun provider ke user, melepas tautan lama milik user yang sudah dihapus;
+// pemanggil harus memegang lock tulis
+func (r *memoryOIDCRepository) insert(userID int, issuer, subject string) error {
+	if id, ok := r.identity(issuer, subject); ok {
+		if r.s.users[r.s.userIdentities[id].UserID].DeletedAt == nil {
+			return ErrDuplicate
+		}
+		delete(r.s.userIdentities, id)
+	}
+
+	at := now()
+	id := r.s.id("user_identities")
+	r.s.userIdentities[id] = models.UserIdentity{
+		ID:          id,
+		UserID:      userID,
+		Issuer:      issuer,
+		Subject:     subject,
+		CreatedAt:   at,
+		LastLoginAt: at,
+	}
+	return nil
+}
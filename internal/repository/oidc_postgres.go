@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresOIDCRepository struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresOIDCRepository) CreateState(ctx context.Context, stateHash string, state models.OIDCState) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO oidc_states (state_hash, nonce, code_verifier, expires_at) VALUES ($1, $2, $3, $4)`,
+		stateHash, state.Nonce, state.CodeVerifier, state.ExpiresAt,
+	)
+	return translateError(err)
+}
+
+func (r *postgresOIDCRepository) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCState, error) {
+	var state models.OIDCState
+	err := r.db.QueryRow(ctx, `
+        DELETE FROM oidc_states WHERE state_hash = $1
+        RETURNING nonce, code_verifier, expires_at`, stateHash,
+	).Scan(&state.Nonce, &state.CodeVerifier, &state.ExpiresAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if !time.Now().Before(state.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &state, nil
+}
+
+func (r *postgresOIDCRepository) PurgeStates(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM oidc_states WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *postgresOIDCRepository) Login(ctx context.Context, issuer, subject string) (int, error) {
+	var userID int
+	err := r.db.QueryRow(ctx, `
+        UPDATE user_identities ui SET last_login_at = NOW()
+        FROM users u
+        WHERE u.id = ui.user_id AND u.deleted_at IS NULL
+          AND ui.issuer = $1 AND ui.subject = $2
+        RETURNING ui.user_id`, issuer, subject,
+	).Scan(&userID)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return userID, nil
+}
+
+func (r *postgresOIDCRepository) Link(ctx context.Context, userID int, issuer, subject string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return insertIdentity(ctx, tx, userID, issuer, subject)
+	})
+}
+
+func (r *postgresOIDCRepository) Provision(ctx context.Context, user *models.User, issuer, subject string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := insertUser(ctx, tx, user); err != nil {
+			return err
+		}
+		return insertIdentity(ctx, tx, user.ID, issuer, subject)
+	})
+}
+
+// insertIdentity menautkan akun provider ke user. Tautan lama milik user yang sudah dihapus
+// dilepas dulu agar akun provider yang sama dapat dipakai lagi.
+func insertIdentity(ctx context.Context, tx pgx.Tx, userID int, issuer, subject string) error {
+	if _, err := tx.Exec(ctx, `
+        DELETE FROM user_identities ui USING users u
+        WHERE u.id = ui.user_id AND u.deleted_at IS NOT NULL
+          AND ui.issuer = $1 AND ui.subject = $2`, issuer, subject,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx,
+		`INSERT INTO user_identities (user_id, issuer, subject) VALUES ($1, $2, $3)`,
+		userID, issuer, subject,
+	)
+	return translateError(err)
+}
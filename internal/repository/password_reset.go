@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordResetRepository akses data tabel password_resets
+type PasswordResetRepository interface {
+	// Create menyimpan hash token reset untuk user sampai expiresAt
+	Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	// Lookup mengembalikan ID user pemilik token reset yang masih berlaku tanpa memakainya;
+	// token yang tidak berlaku menghasilkan ErrResetTokenInvalid
+	Lookup(ctx context.Context, tokenHash string) (int, error)
+	// Consume memakai token reset dan mengganti password user dalam satu transaksi, lalu
+	// membatalkan token reset lain milik user yang sama. Token yang tidak ada, kedaluwarsa,
+	// atau sudah dipakai menghasilkan ErrResetTokenInvalid. Mengembalikan ID user.
+	Consume(ctx context.Context, tokenHash, passwordHash string) (int, error)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// memoryPasswordReset baris tabel password_resets
+type memoryPasswordReset struct {
+	userID    int
+	expiresAt time.Time
+	used      bool
+}
+
+type memoryPasswordResetRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryPasswordResetRepository) Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.passwordResets[tokenHash]; ok {
+		return ErrDuplicate
+	}
+	r.s.passwordResets[tokenHash] = memoryPasswordReset{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *memoryPasswordResetRepository) Lookup(ctx context.Context, tokenHash string) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	reset, ok := r.s.passwordResets[tokenHash]
+	if !ok || reset.used || !now().Before(reset.expiresAt) {
+		return 0, ErrResetTokenInvalid
+	}
+	if user, ok := r.s.users[reset.userID]; !ok || user.DeletedAt != nil {
+		return 0, ErrResetTokenInvalid
+	}
+	return reset.userID, nil
+}
+
+func (r *memoryPasswordResetRepository) Consume(ctx context.Context, tokenHash, passwordHash string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	reset, ok := r.s.passwordResets[tokenHash]
+	if !ok || reset.used || !now().Before(reset.expiresAt) {
+		return 0, ErrResetTokenInvalid
+	}
+	user, ok := r.s.users[reset.userID]
+	if !ok || user.DeletedAt != nil {
+		return 0, ErrResetTokenInvalid
+	}
+
+	editedAt := now()
+	user.Password = passwordHash
+	user.EditedAt, user.EditedBy = &editedAt, &user.ID
+	user.ImpersonatedBy = nil
+	r.s.users[user.ID] = user
+
+	for hash, other := range r.s.passwordResets {
+		if other.userID == user.ID {
+			other.used = true
+			r.s.passwordResets[hash] = other
+		}
+	}
+	return user.ID, nil
+}
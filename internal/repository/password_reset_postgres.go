@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresPasswordResetRepository struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresPasswordResetRepository) Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		userID, tokenHash, expiresAt,
+	)
+	return translateError(err)
+}
+
+func (r *postgresPasswordResetRepository) Lookup(ctx context.Context, tokenHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRow(ctx, `
+        SELECT pr.user_id FROM password_resets pr
+        JOIN users u ON u.id = pr.user_id AND u.deleted_at IS NULL
+        WHERE pr.token_hash = $1 AND pr.used_at IS NULL AND pr.expires_at > NOW()`, tokenHash,
+	).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrResetTokenInvalid
+	}
+	return userID, err
+}
+
+func (r *postgresPasswordResetRepository) Consume(ctx context.Context, tokenHash, passwordHash string) (int, error) {
+	var userID int
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+            SELECT pr.user_id FROM password_resets pr
+            JOIN users u ON u.id = pr.user_id AND u.deleted_at IS NULL
+            WHERE pr.token_hash = $1 AND pr.used_at IS NULL AND pr.expires_at > NOW()
+            FOR UPDATE OF pr`, tokenHash,
+		).Scan(&userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrResetTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE users SET password = $2, edited_at = NOW(), edited_by = $1, impersonated_by = NULL WHERE id = $1`,
+			userID, passwordHash,
+		); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`,
+			userID,
+		)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
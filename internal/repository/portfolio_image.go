@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// PortfolioImageRepository akses data tabel portfolio_images
+type PortfolioImageRepository interface {
+	// Create menyimpan gambar portfolio baru dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, image *models.PortfolioImage) error
+	GetByID(ctx context.Context, id int) (*models.PortfolioImage, error)
+	List(ctx context.Context, page Page) ([]models.PortfolioImageResponse, int, error)
+	// UpdateImage mengganti path gambar dan mengembalikan data terbaru. impersonatedBy admin
+	// yang memerankan editedBy, nil untuk perubahan oleh user sendiri
+	UpdateImage(ctx context.Context, id int, image string, editedBy int, impersonatedBy *int) (*models.PortfolioImage, error)
+	SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error
+}
+
+func toPortfolioImageResponse(i models.PortfolioImage) models.PortfolioImageResponse {
+	return models.PortfolioImageResponse{
+		ID:        i.ID,
+		Image:     i.Image,
+		CreatedAt: i.CreatedAt,
+		CreatedBy: i.CreatedBy,
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"backend-go/internal/models"
+)
+
+type memoryPortfolioImageRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryPortfolioImageRepository) Create(ctx context.Context, image *models.PortfolioImage) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	image.ID = r.s.id("portfolio_images")
+	image.CreatedAt = now()
+	r.s.portfolioImages[image.ID] = *image
+	return nil
+}
+
+func (r *memoryPortfolioImageRepository) GetByID(ctx context.Context, id int) (*models.PortfolioImage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i, ok := r.s.portfolioImages[id]
+	if !ok || i.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &i, nil
+}
+
+func (r *memoryPortfolioImageRepository) List(ctx context.Context, page Page) ([]models.PortfolioImageResponse, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var images []models.PortfolioImage
+	for _, i := range r.s.portfolioImages {
+		if i.DeletedAt == nil {
+			images = append(images, i)
+		}
+	}
+	sort.Slice(images, func(a, b int) bool {
+		return images[a].CreatedAt.After(images[b].CreatedAt)
+	})
+
+	responses := make([]models.PortfolioImageResponse, 0, len(images))
+	for _, i := range images {
+		responses = append(responses, toPortfolioImageResponse(i))
+	}
+	return paginate(responses, page), len(responses), nil
+}
+
+func (r *memoryPortfolioImageRepository) UpdateImage(ctx context.Context, id int, image string, editedBy int, impersonatedBy *int) (*models.PortfolioImage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i, ok := r.s.portfolioImages[id]
+	if !ok || i.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	editedAt := now()
+	i.Image = image
+	i.EditedAt = &editedAt
+	i.EditedBy = &editedBy
+	i.ImpersonatedBy = impersonatedBy
+
+	r.s.portfolioImages[id] = i
+	return &i, nil
+}
+
+func (r *memoryPortfolioImageRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i, ok := r.s.portfolioImages[id]
+	if !ok || i.DeletedAt != nil {
+		return ErrNotFound
+	}
+	deletedAt := now()
+	i.DeletedAt = &deletedAt
+	i.DeletedBy = &deletedBy
+	i.ImpersonatedBy = impersonatedBy
+
+	r.s.portfolioImages[id] = i
+	return nil
+}
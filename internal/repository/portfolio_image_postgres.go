@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresPortfolioImageRepository struct {
+	db *pgxpool.Pool
+}
+
+const portfolioImageColumns = `id, image, created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by`
+
+func scanPortfolioImage(row interface{ Scan(...interface{}) error }) (*models.PortfolioImage, error) {
+	var image models.PortfolioImage
+	err := row.Scan(
+		&image.ID,
+		&image.Image,
+		&image.CreatedAt,
+		&image.CreatedBy,
+		&image.EditedAt,
+		&image.EditedBy,
+		&image.DeletedAt,
+		&image.DeletedBy,
+		&image.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &image, nil
+}
+
+func (r *postgresPortfolioImageRepository) Create(ctx context.Context, image *models.PortfolioImage) error {
+	query := `
+        INSERT INTO portfolio_images (image, created_by, impersonated_by)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRow(ctx, query, image.Image, image.CreatedBy, image.ImpersonatedBy).Scan(&image.ID, &image.CreatedAt)
+	return translateError(err)
+}
+
+func (r *postgresPortfolioImageRepository) GetByID(ctx context.Context, id int) (*models.PortfolioImage, error) {
+	query := `SELECT ` + portfolioImageColumns + ` FROM portfolio_images WHERE id = $1 AND deleted_at IS NULL`
+	return scanPortfolioImage(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *postgresPortfolioImageRepository) List(ctx context.Context, page Page) ([]models.PortfolioImageResponse, int, error) {
+	query := `SELECT
+                id, image, created_at, created_by
+              FROM portfolio_images
+              WHERE deleted_at IS NULL
+              ORDER BY created_at DESC
+              LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	images := []models.PortfolioImageResponse{}
+	for rows.Next() {
+		var img models.PortfolioImageResponse
+		if err := rows.Scan(&img.ID, &img.Image, &img.CreatedAt, &img.CreatedBy); err != nil {
+			return nil, 0, err
+		}
+		images = append(images, img)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.QueryRow(ctx, "SELECT COUNT(*) FROM portfolio_images WHERE deleted_at IS NULL").Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return images, total, nil
+}
+
+func (r *postgresPortfolioImageRepository) UpdateImage(ctx context.Context, id int, image string, editedBy int, impersonatedBy *int) (*models.PortfolioImage, error) {
+	query := `
+        UPDATE portfolio_images
+        SET
+            image = $1,
+            edited_by = $2,
+            impersonated_by = $3
+        WHERE id = $4 AND deleted_at IS NULL
+        RETURNING ` + portfolioImageColumns
+
+	return scanPortfolioImage(r.db.QueryRow(ctx, query, image, editedBy, impersonatedBy, id))
+}
+
+func (r *postgresPortfolioImageRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	query := `
+        UPDATE portfolio_images
+        SET
+            deleted_at = $1,
+            deleted_by = $2,
+            impersonated_by = $3
+        WHERE id = $4 AND deleted_at IS NULL
+    `
+
+	result, err := r.db.Exec(ctx, query, time.Now().UTC(), deletedBy, impersonatedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// PortfolioReviewUpdate perubahan data review; string kosong dan pointer nil berarti tidak diubah
+type PortfolioReviewUpdate struct {
+	ProductID   *int
+	Title       string
+	Description string
+	Image       string
+	Date        *time.Time
+	EditedBy    int
+	// ImpersonatedBy admin yang memerankan EditedBy; nil untuk perubahan oleh user sendiri
+	ImpersonatedBy *int
+}
+
+// PortfolioReviewRepository akses data tabel portfolio_review
+type PortfolioReviewRepository interface {
+	// Create menyimpan review baru dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, review *models.PortfolioReview) error
+	// Get mengembalikan review tanpa data produk, dipakai sebelum update
+	Get(ctx context.Context, id int) (*models.PortfolioReview, error)
+	GetByID(ctx context.Context, id int) (*models.PortfolioReviewWithProduct, error)
+	List(ctx context.Context, page Page) ([]models.PortfolioReviewWithProduct, int, error)
+	Update(ctx context.Context, id int, update PortfolioReviewUpdate) (*models.PortfolioReview, error)
+	SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error
+}
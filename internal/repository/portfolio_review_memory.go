@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"backend-go/internal/models"
+)
+
+type memoryPortfolioReviewRepository struct {
+	s *memoryStore
+}
+
+// withProduct melengkapi review dengan data produk; pemanggil harus memegang lock
+func (r *memoryPortfolioReviewRepository) withProduct(review models.PortfolioReview) models.PortfolioReviewWithProduct {
+	name, image := r.s.productInfo(review.ProductID)
+	return models.PortfolioReviewWithProduct{
+		PortfolioReview: review,
+		ProductName:     name,
+		ProductImage:    image,
+	}
+}
+
+func (r *memoryPortfolioReviewRepository) Create(ctx context.Context, review *models.PortfolioReview) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	review.ID = r.s.id("portfolio_review")
+	review.CreatedAt = now()
+	r.s.portfolioReviews[review.ID] = *review
+	return nil
+}
+
+func (r *memoryPortfolioReviewRepository) Get(ctx context.Context, id int) (*models.PortfolioReview, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	review, ok := r.s.portfolioReviews[id]
+	if !ok || review.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &review, nil
+}
+
+func (r *memoryPortfolioReviewRepository) GetByID(ctx context.Context, id int) (*models.PortfolioReviewWithProduct, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	review, ok := r.s.portfolioReviews[id]
+	if !ok || review.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	result := r.withProduct(review)
+	return &result, nil
+}
+
+func (r *memoryPortfolioReviewRepository) List(ctx context.Context, page Page) ([]models.PortfolioReviewWithProduct, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var reviews []models.PortfolioReview
+	for _, review := range r.s.portfolioReviews {
+		if review.DeletedAt == nil {
+			reviews = append(reviews, review)
+		}
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		return reviews[i].Date.After(reviews[j].Date)
+	})
+
+	results := make([]models.PortfolioReviewWithProduct, 0, len(reviews))
+	for _, review := range reviews {
+		results = append(results, r.withProduct(review))
+	}
+	return paginate(results, page), len(results), nil
+}
+
+func (r *memoryPortfolioReviewRepository) Update(ctx context.Context, id int, update PortfolioReviewUpdate) (*models.PortfolioReview, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	review, ok := r.s.portfolioReviews[id]
+	if !ok || review.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	if update.ProductID != nil {
+		review.ProductID = update.ProductID
+	}
+	if update.Title != "" {
+		review.Title = update.Title
+	}
+	if update.Description != "" {
+		review.Description = update.Description
+	}
+	if update.Image != "" {
+		review.Image = update.Image
+	}
+	if update.Date != nil {
+		review.Date = *update.Date
+	}
+	editedAt := now()
+	review.EditedAt = &editedAt
+	review.EditedBy = &update.EditedBy
+	review.ImpersonatedBy = update.ImpersonatedBy
+
+	r.s.portfolioReviews[id] = review
+	return &review, nil
+}
+
+func (r *memoryPortfolioReviewRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	review, ok := r.s.portfolioReviews[id]
+	if !ok || review.DeletedAt != nil {
+		return ErrNotFound
+	}
+	deletedAt := now()
+	review.DeletedAt = &deletedAt
+	review.DeletedBy = &deletedBy
+	review.ImpersonatedBy = impersonatedBy
+
+	r.s.portfolioReviews[id] = review
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresPortfolioReviewRepository struct {
+	db *pgxpool.Pool
+}
+
+const portfolioReviewColumns = `id, id_product, title, description, image, date,
+            created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by`
+
+const portfolioReviewWithProductQuery = `
+        SELECT
+            pr.id,
+            pr.id_product,
+            pr.title,
+            pr.description,
+            pr.image,
+            pr.date,
+            pr.created_at,
+            pr.created_by,
+            pr.edited_at,
+            pr.edited_by,
+            p.title as product_name,
+            p.image as product_image
+        FROM portfolio_review pr
+        LEFT JOIN products p ON pr.id_product = p.id
+        WHERE pr.deleted_at IS NULL`
+
+func scanPortfolioReview(row interface{ Scan(...interface{}) error }) (*models.PortfolioReview, error) {
+	var review models.PortfolioReview
+	err := row.Scan(
+		&review.ID,
+		&review.ProductID,
+		&review.Title,
+		&review.Description,
+		&review.Image,
+		&review.Date,
+		&review.CreatedAt,
+		&review.CreatedBy,
+		&review.EditedAt,
+		&review.EditedBy,
+		&review.DeletedAt,
+		&review.DeletedBy,
+		&review.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &review, nil
+}
+
+func scanPortfolioReviewWithProduct(row interface{ Scan(...interface{}) error }) (*models.PortfolioReviewWithProduct, error) {
+	var review models.PortfolioReviewWithProduct
+	err := row.Scan(
+		&review.ID,
+		&review.ProductID,
+		&review.Title,
+		&review.Description,
+		&review.Image,
+		&review.Date,
+		&review.CreatedAt,
+		&review.CreatedBy,
+		&review.EditedAt,
+		&review.EditedBy,
+		&review.ProductName,
+		&review.ProductImage,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &review, nil
+}
+
+func (r *postgresPortfolioReviewRepository) Create(ctx context.Context, review *models.PortfolioReview) error {
+	query := `
+        INSERT INTO portfolio_review (
+            id_product,
+            title,
+            description,
+            image,
+            date,
+            created_by,
+            impersonated_by
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRow(ctx, query,
+		review.ProductID,
+		review.Title,
+		review.Description,
+		review.Image,
+		review.Date,
+		review.CreatedBy,
+		review.ImpersonatedBy,
+	).Scan(&review.ID, &review.CreatedAt)
+
+	return translateError(err)
+}
+
+func (r *postgresPortfolioReviewRepository) Get(ctx context.Context, id int) (*models.PortfolioReview, error) {
+	query := `SELECT ` + portfolioReviewColumns + ` FROM portfolio_review WHERE id = $1 AND deleted_at IS NULL`
+	return scanPortfolioReview(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *postgresPortfolioReviewRepository) GetByID(ctx context.Context, id int) (*models.PortfolioReviewWithProduct, error) {
+	query := portfolioReviewWithProductQuery + ` AND pr.id = $1`
+	return scanPortfolioReviewWithProduct(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *postgresPortfolioReviewRepository) List(ctx context.Context, page Page) ([]models.PortfolioReviewWithProduct, int, error) {
+	query := portfolioReviewWithProductQuery + `
+        ORDER BY pr.date DESC
+        LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reviews := []models.PortfolioReviewWithProduct{}
+	for rows.Next() {
+		review, err := scanPortfolioReviewWithProduct(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.QueryRow(ctx, "SELECT COUNT(*) FROM portfolio_review WHERE deleted_at IS NULL").Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+func (r *postgresPortfolioReviewRepository) Update(ctx context.Context, id int, update PortfolioReviewUpdate) (*models.PortfolioReview, error) {
+	query := `UPDATE portfolio_review SET
+                id_product = COALESCE($1, id_product),
+                title = COALESCE(NULLIF($2, ''), title),
+                description = COALESCE(NULLIF($3, ''), description),
+                image = COALESCE(NULLIF($4, ''), image),
+                date = COALESCE($5, date),
+                edited_by = $6,
+                impersonated_by = $7
+              WHERE id = $8 AND deleted_at IS NULL
+              RETURNING ` + portfolioReviewColumns
+
+	return scanPortfolioReview(r.db.QueryRow(ctx, query,
+		update.ProductID,
+		update.Title,
+		update.Description,
+		update.Image,
+		update.Date,
+		update.EditedBy,
+		update.ImpersonatedBy,
+		id,
+	))
+}
+
+func (r *postgresPortfolioReviewRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	query := `
+        UPDATE portfolio_review
+        SET deleted_at = $1,
+            deleted_by = $2,
+            impersonated_by = $3
+        WHERE id = $4
+            AND deleted_at IS NULL
+    `
+
+	result, err := r.db.Exec(ctx, query, time.Now().UTC(), deletedBy, impersonatedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kode error PostgreSQL yang diterjemahkan menjadi error repository
+const pgUniqueViolation = "23505"
+
+// translateError mengubah error pgx menjadi error repository tanpa membuang error aslinya
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return fmt.Errorf("%w: %w", ErrDuplicate, err)
+	}
+	return err
+}
+
+// whereBuilder menyusun klausa WHERE dengan placeholder berurutan
+type whereBuilder struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (w *whereBuilder) add(format string, arg interface{}) {
+	w.args = append(w.args, arg)
+	w.clauses = append(w.clauses, fmt.Sprintf(format, len(w.args)))
+}
+
+func (w *whereBuilder) sql() string {
+	var s string
+	for _, clause := range w.clauses {
+		s += " AND " + clause
+	}
+	return s
+}
+
+// next mengembalikan nomor placeholder berikutnya
+func (w *whereBuilder) next() int {
+	return len(w.args) + 1
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProductFilter filter untuk daftar produk
+type ProductFilter struct {
+	Status   *bool
+	Type     models.ProductType
+	MinPrice *decimal.Decimal
+	MaxPrice *decimal.Decimal
+	Page
+}
+
+// ProductUpdate perubahan data produk; string kosong dan pointer nil berarti tidak diubah
+type ProductUpdate struct {
+	Image       string
+	Title       string
+	Description string
+	TypeProduct models.ProductType
+	Price       *decimal.Decimal
+	Status      *bool
+	EditedBy    int
+	// ImpersonatedBy admin yang memerankan EditedBy; nil untuk perubahan oleh user sendiri
+	ImpersonatedBy *int
+}
+
+// ProductRepository akses data tabel products
+type ProductRepository interface {
+	// Create menyimpan produk baru dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, product *models.Product) error
+	GetByID(ctx context.Context, id int) (*models.Product, error)
+	List(ctx context.Context, filter ProductFilter) ([]models.ProductResponse, int, error)
+	Update(ctx context.Context, id int, update ProductUpdate) (*models.Product, error)
+	SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error
+	// Exists memeriksa apakah produk dengan ID tersebut ada
+	Exists(ctx context.Context, id int) (bool, error)
+}
+
+func toProductResponse(p models.Product) models.ProductResponse {
+	return models.ProductResponse{
+		ID:          p.ID,
+		Image:       p.Image,
+		Title:       p.Title,
+		Description: p.Description,
+		TypeProduct: p.TypeProduct,
+		Price:       p.Price,
+		Status:      p.Status,
+		CreatedAt:   p.CreatedAt,
+	}
+}
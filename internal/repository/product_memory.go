@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"backend-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+type memoryProductRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryProductRepository) Create(ctx context.Context, product *models.Product) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	product.ID = r.s.id("products")
+	product.CreatedAt = now()
+	r.s.products[product.ID] = *product
+	return nil
+}
+
+func (r *memoryProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	p, ok := r.s.products[id]
+	if !ok || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryProductRepository) List(ctx context.Context, filter ProductFilter) ([]models.ProductResponse, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var products []models.Product
+	for _, p := range r.s.products {
+		if p.DeletedAt != nil {
+			continue
+		}
+		if filter.Status != nil && p.Status != *filter.Status {
+			continue
+		}
+		if filter.Type != "" && p.TypeProduct != filter.Type {
+			continue
+		}
+		price := decimal.NewFromFloat(p.Price)
+		if filter.MinPrice != nil && price.LessThan(*filter.MinPrice) {
+			continue
+		}
+		if filter.MaxPrice != nil && price.GreaterThan(*filter.MaxPrice) {
+			continue
+		}
+		products = append(products, p)
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].CreatedAt.After(products[j].CreatedAt)
+	})
+
+	responses := make([]models.ProductResponse, 0, len(products))
+	for _, p := range products {
+		responses = append(responses, toProductResponse(p))
+	}
+	return paginate(responses, filter.Page), len(responses), nil
+}
+
+func (r *memoryProductRepository) Update(ctx context.Context, id int, update ProductUpdate) (*models.Product, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p, ok := r.s.products[id]
+	if !ok || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	if update.Image != "" {
+		p.Image = update.Image
+	}
+	if update.Title != "" {
+		p.Title = update.Title
+	}
+	if update.Description != "" {
+		p.Description = update.Description
+	}
+	if update.TypeProduct != "" {
+		p.TypeProduct = update.TypeProduct
+	}
+	if update.Price != nil {
+		p.Price, _ = update.Price.Float64()
+	}
+	if update.Status != nil {
+		p.Status = *update.Status
+	}
+	editedAt := now()
+	p.EditedAt = &editedAt
+	p.EditedBy = &update.EditedBy
+	p.ImpersonatedBy = update.ImpersonatedBy
+
+	r.s.products[id] = p
+	return &p, nil
+}
+
+func (r *memoryProductRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p, ok := r.s.products[id]
+	if !ok || p.DeletedAt != nil {
+		return ErrNotFound
+	}
+	deletedAt := now()
+	p.DeletedAt = &deletedAt
+	p.DeletedBy = &deletedBy
+	p.ImpersonatedBy = impersonatedBy
+
+	r.s.products[id] = p
+	return nil
+}
+
+func (r *memoryProductRepository) Exists(ctx context.Context, id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	p, ok := r.s.products[id]
+	return ok && p.DeletedAt == nil, nil
+}
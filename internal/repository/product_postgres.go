@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type postgresProductRepository struct {
+	db *pgxpool.Pool
+}
+
+const productColumns = `id, image, title, description, type_product, price, status,
+            created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by`
+
+func scanProduct(row interface{ Scan(...interface{}) error }) (*models.Product, error) {
+	var (
+		product models.Product
+		price   decimal.Decimal
+	)
+	err := row.Scan(
+		&product.ID,
+		&product.Image,
+		&product.Title,
+		&product.Description,
+		&product.TypeProduct,
+		&price,
+		&product.Status,
+		&product.CreatedAt,
+		&product.CreatedBy,
+		&product.EditedAt,
+		&product.EditedBy,
+		&product.DeletedAt,
+		&product.DeletedBy,
+		&product.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// Konversi decimal ke float untuk response
+	product.Price, _ = price.Float64()
+	return &product, nil
+}
+
+func (r *postgresProductRepository) Create(ctx context.Context, product *models.Product) error {
+	query := `
+        INSERT INTO products (
+            image,
+            title,
+            description,
+            type_product,
+            price,
+            status,
+            created_by,
+            impersonated_by
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRow(ctx, query,
+		product.Image,
+		product.Title,
+		product.Description,
+		product.TypeProduct,
+		decimal.NewFromFloat(product.Price),
+		product.Status,
+		product.CreatedBy,
+		product.ImpersonatedBy,
+	).Scan(&product.ID, &product.CreatedAt)
+
+	return translateError(err)
+}
+
+func (r *postgresProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1 AND deleted_at IS NULL`
+	return scanProduct(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *postgresProductRepository) List(ctx context.Context, filter ProductFilter) ([]models.ProductResponse, int, error) {
+	var where whereBuilder
+	if filter.Status != nil {
+		where.add("status = $%d", *filter.Status)
+	}
+	if filter.Type != "" {
+		where.add("type_product = $%d", filter.Type)
+	}
+	if filter.MinPrice != nil {
+		where.add("price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		where.add("price <= $%d", *filter.MaxPrice)
+	}
+
+	query := fmt.Sprintf(`SELECT
+                id, image, title, description,
+                type_product, price, status, created_at
+              FROM products
+              WHERE deleted_at IS NULL%s
+              ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		where.sql(), where.next(), where.next()+1,
+	)
+	args := append(append([]interface{}{}, where.args...), filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := []models.ProductResponse{}
+	for rows.Next() {
+		var (
+			product models.ProductResponse
+			price   decimal.Decimal
+		)
+		err := rows.Scan(
+			&product.ID,
+			&product.Image,
+			&product.Title,
+			&product.Description,
+			&product.TypeProduct,
+			&price,
+			&product.Status,
+			&product.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		product.Price, _ = price.Float64()
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM products WHERE deleted_at IS NULL` + where.sql()
+	if err := r.db.QueryRow(ctx, countQuery, where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+func (r *postgresProductRepository) Update(ctx context.Context, id int, update ProductUpdate) (*models.Product, error) {
+	query := `UPDATE products SET
+				image = COALESCE(NULLIF($1, ''), image),
+				title = COALESCE(NULLIF($2, ''), title),
+				description = COALESCE(NULLIF($3, ''), description),
+				type_product = CASE
+					WHEN $4::text = '' THEN type_product
+					ELSE $4::product_type
+				END,
+				price = COALESCE($5, price),
+				status = COALESCE($6, status),
+				edited_by = $7,
+				impersonated_by = $8
+			WHERE id = $9 AND deleted_at IS NULL
+			RETURNING ` + productColumns
+
+	return scanProduct(r.db.QueryRow(ctx, query,
+		update.Image,
+		update.Title,
+		update.Description,
+		string(update.TypeProduct),
+		update.Price,
+		update.Status,
+		update.EditedBy,
+		update.ImpersonatedBy,
+		id,
+	))
+}
+
+func (r *postgresProductRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	query := `
+        UPDATE products
+        SET deleted_at = $1, deleted_by = $2, impersonated_by = $3
+        WHERE id = $4 AND deleted_at IS NULL
+    `
+
+	result, err := r.db.Exec(ctx, query, time.Now().UTC(), deletedBy, impersonatedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresProductRepository) Exists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)",
+		id,
+	).Scan(&exists)
+	return exists, err
+}
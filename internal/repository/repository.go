@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+
+	"backend-go/internal/repoerr"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrNotFound dikembalikan ketika data tidak ada atau sudah dihapus
+	ErrNotFound = repoerr.ErrNotFound
+	// ErrDuplicate dikembalikan ketika data melanggar unique constraint
+	ErrDuplicate = repoerr.ErrDuplicate
+	// ErrTokenReused dikembalikan ketika refresh token yang sudah dirotasi dipakai lagi
+	ErrTokenReused = errors.New("refresh token reused")
+	// ErrSessionInactive dikembalikan ketika session sudah dicabut atau kedaluwarsa
+	ErrSessionInactive = errors.New("session revoked or expired")
+	// ErrInviteInvalid dikembalikan ketika kode undangan tidak ada, kedaluwarsa, dibatalkan, atau sudah dipakai
+	ErrInviteInvalid = errors.New("invite invalid or already used")
+	// ErrResetTokenInvalid dikembalikan ketika token reset password tidak ada, kedaluwarsa, atau sudah dipakai
+	ErrResetTokenInvalid = errors.New("password reset token invalid or already used")
+)
+
+// Page parameter pagination untuk query list
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// Repositories kumpulan seluruh repository yang dipakai handler
+type Repositories struct {
+	Users            UserRepository
+	Carousels        CarouselRepository
+	Products         ProductRepository
+	PortfolioImages  PortfolioImageRepository
+	PortfolioReviews PortfolioReviewRepository
+	Messages         MessageRepository
+	Tokens           TokenRepository
+	Sessions         SessionRepository
+	Invites          InviteRepository
+	PasswordResets   PasswordResetRepository
+	LoginAttempts    LoginAttemptRepository
+	TOTP             TOTPRepository
+	APIKeys          APIKeyRepository
+	OIDC             OIDCRepository
+	Audit            AuditRepository
+}
+
+// NewPostgres membuat repository yang membaca dan menulis ke PostgreSQL
+func NewPostgres(db *pgxpool.Pool) *Repositories {
+	return &Repositories{
+		Users:            &postgresUserRepository{db: db},
+		Carousels:        &postgresCarouselRepository{db: db},
+		Products:         &postgresProductRepository{db: db},
+		PortfolioImages:  &postgresPortfolioImageRepository{db: db},
+		PortfolioReviews: &postgresPortfolioReviewRepository{db: db},
+		Messages:         &postgresMessageRepository{db: db},
+		Tokens:           &postgresTokenRepository{db: db},
+		Sessions:         &postgresSessionRepository{db: db},
+		Invites:          &postgresInviteRepository{db: db},
+		PasswordResets:   &postgresPasswordResetRepository{db: db},
+		LoginAttempts:    &postgresLoginAttemptRepository{db: db},
+		TOTP:             &postgresTOTPRepository{db: db},
+		APIKeys:          &postgresAPIKeyRepository{db: db},
+		OIDC:             &postgresOIDCRepository{db: db},
+		Audit:            &postgresAuditRepository{db: db},
+	}
+}
+
+// NewMemory membuat repository in-memory untuk pengujian handler tanpa database.
+// Semua repository berbagi satu penyimpanan sehingga relasi antar tabel tetap konsisten.
+func NewMemory() *Repositories {
+	s := newMemoryStore()
+	return &Repositories{
+		Users:            &memoryUserRepository{s: s},
+		Carousels:        &memoryCarouselRepository{s: s},
+		Products:         &memoryProductRepository{s: s},
+		PortfolioImages:  &memoryPortfolioImageRepository{s: s},
+		PortfolioReviews: &memoryPortfolioReviewRepository{s: s},
+		Messages:         &memoryMessageRepository{s: s},
+		Tokens:           &memoryTokenRepository{s: s},
+		Sessions:         &memorySessionRepository{s: s},
+		Invites:          &memoryInviteRepository{s: s},
+		PasswordResets:   &memoryPasswordResetRepository{s: s},
+		LoginAttempts:    &memoryLoginAttemptRepository{s: s},
+		TOTP:             &memoryTOTPRepository{s: s},
+		APIKeys:          &memoryAPIKeyRepository{s: s},
+		OIDC:             &memoryOIDCRepository{s: s},
+		Audit:            &memoryAuditRepository{s: s},
+	}
+}
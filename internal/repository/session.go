@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// SessionRepository akses data tabel sessions dan refresh_tokens
+type SessionRepository interface {
+	// Create menyimpan session baru (mengisi ID, CreatedAt, LastUsedAt) beserta hash refresh token pertamanya
+	Create(ctx context.Context, session *models.Session, tokenHash string) error
+	// Rotate menukar refresh token lama dengan yang baru secara atomik dan memperpanjang session
+	// sampai expiresAt. Token yang sudah pernah dirotasi dianggap bocor: seluruh session dicabut
+	// dan ErrTokenReused dikembalikan bersama session yang dicabut. Session yang dicabut atau kedaluwarsa menghasilkan ErrSessionInactive.
+	Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error)
+	// IsActive true bila session belum dicabut dan belum kedaluwarsa
+	IsActive(ctx context.Context, id int) (bool, error)
+	// Revoke mencabut session; session yang sudah dicabut tidak dianggap error
+	Revoke(ctx context.Context, id int, reason string) error
+	// RevokeAll mencabut seluruh session aktif milik user dan mengembalikan ID session yang dicabut
+	RevokeAll(ctx context.Context, userID int, reason string) ([]int, error)
+	// RevokeOthers seperti RevokeAll, tetapi session keepID tidak ikut dicabut
+	RevokeOthers(ctx context.Context, userID, keepID int, reason string) ([]int, error)
+	// Get mengembalikan session berdasarkan ID, termasuk yang sudah dicabut
+	Get(ctx context.Context, id int) (*models.Session, error)
+	// ListActive daftar session aktif milik user, yang terakhir dipakai lebih dulu
+	ListActive(ctx context.Context, userID int) ([]models.Session, error)
+	// Touch memperbarui last_used_at bila terakhir diperbarui sebelum staleBefore
+	Touch(ctx context.Context, id int, staleBefore time.Time) error
+}
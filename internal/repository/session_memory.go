@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// memoryRefreshToken baris tabel refresh_tokens
+type memoryRefreshToken struct {
+	sessionID int
+	expiresAt time.Time
+	rotatedAt *time.Time
+}
+
+type memorySessionRepository struct {
+	s *memoryStore
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.refreshTokens[tokenHash]; ok {
+		return ErrDuplicate
+	}
+
+	session.ID = r.s.id("sessions")
+	session.CreatedAt = now()
+	session.LastUsedAt = session.CreatedAt
+	r.s.sessions[session.ID] = *session
+	r.s.refreshTokens[tokenHash] = memoryRefreshToken{sessionID: session.ID, expiresAt: session.ExpiresAt}
+	return nil
+}
+
+func (r *memorySessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.refreshTokens[oldHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	session := r.s.sessions[token.sessionID]
+	t := now()
+
+	if token.rotatedAt != nil {
+		if session.RevokedAt == nil {
+			reason := models.SessionRevokedReuse
+			session.RevokedAt, session.RevokedReason = &t, &reason
+			r.s.sessions[session.ID] = session
+		}
+		return &session, ErrTokenReused
+	}
+	if !session.Active(t) || !t.Before(token.expiresAt) {
+		return nil, ErrSessionInactive
+	}
+	if _, ok := r.s.refreshTokens[newHash]; ok {
+		return nil, ErrDuplicate
+	}
+
+	token.rotatedAt = &t
+	r.s.refreshTokens[oldHash] = token
+	r.s.refreshTokens[newHash] = memoryRefreshToken{sessionID: session.ID, expiresAt: expiresAt}
+
+	session.LastUsedAt, session.ExpiresAt = t, expiresAt
+	r.s.sessions[session.ID] = session
+	return &session, nil
+}
+
+func (r *memorySessionRepository) IsActive(ctx context.Context, id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	session, ok := r.s.sessions[id]
+	return ok && session.Active(now()), nil
+}
+
+func (r *memorySessionRepository) Revoke(ctx context.Context, id int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	session, ok := r.s.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return nil
+	}
+	t := now()
+	session.RevokedAt, session.RevokedReason = &t, &reason
+	r.s.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) RevokeAll(ctx context.Context, userID int, reason string) ([]int, error) {
+	return r.RevokeOthers(ctx, userID, 0, reason)
+}
+
+func (r *memorySessionRepository) RevokeOthers(ctx context.Context, userID, keepID int, reason string) ([]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t := now()
+	var ids []int
+	for _, id := range sortedIDs(r.s.sessions) {
+		session := r.s.sessions[id]
+		if session.UserID != userID || session.RevokedAt != nil || id == keepID {
+			continue
+		}
+		session.RevokedAt, session.RevokedReason = &t, &reason
+		r.s.sessions[id] = session
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *memorySessionRepository) Get(ctx context.Context, id int) (*models.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	session, ok := r.s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessionRepository) ListActive(ctx context.Context, userID int) ([]models.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	t := now()
+	sessions := []models.Session{}
+	for _, session := range r.s.sessions {
+		if session.UserID == userID && session.Active(t) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastUsedAt.Equal(sessions[j].LastUsedAt) {
+			return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+	return sessions, nil
+}
+
+func (r *memorySessionRepository) Touch(ctx context.Context, id int, staleBefore time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	session, ok := r.s.sessions[id]
+	if ok && session.LastUsedAt.Before(staleBefore) {
+		session.LastUsedAt = now()
+		r.s.sessions[id] = session
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresSessionRepository struct {
+	db *pgxpool.Pool
+}
+
+const sessionColumns = `s.id, s.user_id, s.user_agent, s.ip_address, s.created_at,
+            s.last_used_at, s.expires_at, s.revoked_at, s.revoked_reason, s.amr, s.impersonator_id`
+
+func scanSession(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.Session, error) {
+	var session models.Session
+	dest := append([]interface{}{
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.RevokedReason,
+		&session.AMR,
+		&session.ImpersonatorID,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, translateError(err)
+	}
+	return &session, nil
+}
+
+func (r *postgresSessionRepository) Create(ctx context.Context, session *models.Session, tokenHash string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+            INSERT INTO sessions (user_id, user_agent, ip_address, expires_at, amr, impersonator_id)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING id, created_at, last_used_at`,
+			session.UserID,
+			session.UserAgent,
+			session.IPAddress,
+			session.ExpiresAt,
+			session.AMR,
+			session.ImpersonatorID,
+		).Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt)
+		if err != nil {
+			return translateError(err)
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+			session.ID, tokenHash, session.ExpiresAt,
+		)
+		return translateError(err)
+	})
+}
+
+func (r *postgresSessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	var (
+		session *models.Session
+		reused  bool
+	)
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var (
+			tokenID        int
+			tokenExpiresAt time.Time
+			rotatedAt      *time.Time
+			err            error
+		)
+		// FOR UPDATE mencegah dua refresh bersamaan dengan token yang sama sama-sama berhasil
+		session, err = scanSession(tx.QueryRow(ctx, `
+            SELECT `+sessionColumns+`, rt.id, rt.expires_at, rt.rotated_at
+            FROM refresh_tokens rt
+            JOIN sessions s ON s.id = rt.session_id
+            WHERE rt.token_hash = $1
+            FOR UPDATE`, oldHash),
+			&tokenID, &tokenExpiresAt, &rotatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if rotatedAt != nil {
+			// Pencabutan tetap di-commit walaupun request ini ditolak
+			reused = true
+			_, err := tx.Exec(ctx,
+				`UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1 AND revoked_at IS NULL`,
+				session.ID, models.SessionRevokedReuse,
+			)
+			return err
+		}
+
+		now := time.Now()
+		if !session.Active(now) || !now.Before(tokenExpiresAt) {
+			return ErrSessionInactive
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET rotated_at = NOW() WHERE id = $1`, tokenID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+			session.ID, newHash, expiresAt,
+		); err != nil {
+			return translateError(err)
+		}
+		return tx.QueryRow(ctx,
+			`UPDATE sessions SET last_used_at = NOW(), expires_at = $2 WHERE id = $1 RETURNING last_used_at, expires_at`,
+			session.ID, expiresAt,
+		).Scan(&session.LastUsedAt, &session.ExpiresAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return session, ErrTokenReused
+	}
+	return session, nil
+}
+
+func (r *postgresSessionRepository) IsActive(ctx context.Context, id int) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW())`,
+		id,
+	).Scan(&active)
+	return active, err
+}
+
+func (r *postgresSessionRepository) Revoke(ctx context.Context, id int, reason string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1 AND revoked_at IS NULL`,
+		id, reason,
+	)
+	return err
+}
+
+func (r *postgresSessionRepository) RevokeAll(ctx context.Context, userID int, reason string) ([]int, error) {
+	return r.RevokeOthers(ctx, userID, 0, reason)
+}
+
+func (r *postgresSessionRepository) RevokeOthers(ctx context.Context, userID, keepID int, reason string) ([]int, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+         WHERE user_id = $1 AND revoked_at IS NULL AND id <> $3
+         RETURNING id`,
+		userID, reason, keepID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+func (r *postgresSessionRepository) Get(ctx context.Context, id int) (*models.Session, error) {
+	return scanSession(r.db.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions s WHERE s.id = $1`, id))
+}
+
+func (r *postgresSessionRepository) ListActive(ctx context.Context, userID int) ([]models.Session, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+sessionColumns+`
+        FROM sessions s
+        WHERE s.user_id = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+        ORDER BY s.last_used_at DESC, s.id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *postgresSessionRepository) Touch(ctx context.Context, id int, staleBefore time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE sessions SET last_used_at = NOW() WHERE id = $1 AND last_used_at < $2`,
+		id, staleBefore,
+	)
+	return err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TokenRepository akses data tabel revoked_tokens (pencabutan access token berdasarkan jti)
+type TokenRepository interface {
+	// Revoke mencabut token dengan jti tersebut sampai waktu kedaluwarsanya
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	// Use mencabut token sekali pakai (mis. challenge 2FA) dalam satu langkah; false bila jti
+	// sudah dicabut sebelumnya, sehingga dua request paralel tidak dapat memakai token yang sama
+	Use(ctx context.Context, jti string, expiresAt time.Time) (bool, error)
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// PurgeExpired menghapus pencabutan token yang sudah kedaluwarsa sebelum waktu before
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type postgresTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, expires_at)
+         VALUES ($1, $2)
+         ON CONFLICT (jti) DO NOTHING`,
+		jti,
+		expiresAt,
+	)
+	return err
+}
+
+func (r *postgresTokenRepository) Use(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, expires_at)
+         VALUES ($1, $2)
+         ON CONFLICT (jti) DO NOTHING`,
+		jti,
+		expiresAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *postgresTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)",
+		jti,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, "DELETE FROM revoked_tokens WHERE expires_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+type memoryTokenRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.revokedTokens[jti]; !ok {
+		r.s.revokedTokens[jti] = expiresAt
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) Use(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.revokedTokens[jti]; ok {
+		return false, nil
+	}
+	r.s.revokedTokens[jti] = expiresAt
+	return true, nil
+}
+
+func (r *memoryTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.revokedTokens[jti]
+	return ok, nil
+}
+
+func (r *memoryTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var n int64
+	for jti, expiresAt := range r.s.revokedTokens {
+		if expiresAt.Before(before) {
+			delete(r.s.revokedTokens, jti)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// TOTPRepository akses data tabel user_totp dan recovery_codes
+type TOTPRepository interface {
+	// Get mengembalikan pendaftaran TOTP user, ErrNotFound bila belum pernah mendaftar
+	Get(ctx context.Context, userID int) (*models.TOTP, error)
+	// Begin menyimpan secret baru yang belum dikonfirmasi, menggantikan pendaftaran yang belum
+	// selesai. ErrDuplicate bila 2FA user sudah aktif.
+	Begin(ctx context.Context, userID int, secret string) error
+	// Confirm mengaktifkan 2FA dengan langkah kode pertama yang valid dan mengganti kode pemulihan.
+	// ErrNotFound bila tidak ada pendaftaran yang menunggu konfirmasi.
+	Confirm(ctx context.Context, userID int, step int64, codeHashes []string) error
+	// UseStep mencatat langkah kode yang dipakai; false bila langkah yang sama atau lebih baru
+	// sudah pernah dipakai (kode diputar ulang) atau 2FA tidak aktif
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	// UseRecoveryCode memakai satu kode pemulihan; false bila tidak ada atau sudah dipakai
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	// ReplaceRecoveryCodes membuang seluruh kode pemulihan user dan menyimpan yang baru
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// CountRecoveryCodes jumlah kode pemulihan yang belum dipakai
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+	// Delete menghapus pendaftaran TOTP beserta kode pemulihan; ErrNotFound bila tidak ada
+	Delete(ctx context.Context, userID int) error
+}
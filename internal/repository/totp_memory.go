@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend-go/internal/models"
+)
+
+// memoryRecoveryCode baris tabel recovery_codes
+type memoryRecoveryCode struct {
+	userID   int
+	codeHash string
+	usedAt   *time.Time
+}
+
+type memoryTOTPRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryTOTPRepository) Get(ctx context.Context, userID int) (*models.TOTP, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	totp, ok := r.s.totps[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &totp, nil
+}
+
+func (r *memoryTOTPRepository) Begin(ctx context.Context, userID int, secret string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if totp, ok := r.s.totps[userID]; ok && totp.ConfirmedAt != nil {
+		return ErrDuplicate
+	}
+	r.s.totps[userID] = models.TOTP{UserID: userID, Secret: secret, CreatedAt: now()}
+	return nil
+}
+
+func (r *memoryTOTPRepository) Confirm(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	totp, ok := r.s.totps[userID]
+	if !ok || totp.ConfirmedAt != nil {
+		return ErrNotFound
+	}
+	confirmedAt := now()
+	totp.ConfirmedAt, totp.LastUsedStep = &confirmedAt, step
+	r.s.totps[userID] = totp
+
+	r.replaceRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+func (r *memoryTOTPRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	totp, ok := r.s.totps[userID]
+	if !ok || totp.ConfirmedAt == nil || totp.LastUsedStep >= step {
+		return false, nil
+	}
+	totp.LastUsedStep = step
+	r.s.totps[userID] = totp
+	return true, nil
+}
+
+func (r *memoryTOTPRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, id := range sortedIDs(r.s.recoveryCodes) {
+		code := r.s.recoveryCodes[id]
+		if code.userID == userID && code.codeHash == codeHash && code.usedAt == nil {
+			usedAt := now()
+			code.usedAt = &usedAt
+			r.s.recoveryCodes[id] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryTOTPRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.replaceRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+// replaceRecoveryCodes pemanggil harus memegang lock tulis
+func (r *memoryTOTPRepository) replaceRecoveryCodes(userID int, codeHashes []string) {
+	r.deleteRecoveryCodes(userID)
+	for _, hash := range codeHashes {
+		r.s.recoveryCodes[r.s.id("recovery_codes")] = memoryRecoveryCode{userID: userID, codeHash: hash}
+	}
+}
+
+// deleteRecoveryCodes pemanggil harus memegang lock tulis
+func (r *memoryTOTPRepository) deleteRecoveryCodes(userID int) {
+	for id, code := range r.s.recoveryCodes {
+		if code.userID == userID {
+			delete(r.s.recoveryCodes, id)
+		}
+	}
+}
+
+func (r *memoryTOTPRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	n := 0
+	for _, code := range r.s.recoveryCodes {
+		if code.userID == userID && code.usedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memoryTOTPRepository) Delete(ctx context.Context, userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.totps[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.totps, userID)
+	r.deleteRecoveryCodes(userID)
+	return nil
+}
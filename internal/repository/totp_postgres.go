@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresTOTPRepository struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresTOTPRepository) Get(ctx context.Context, userID int) (*models.TOTP, error) {
+	totp := models.TOTP{UserID: userID}
+	err := r.db.QueryRow(ctx,
+		`SELECT secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id = $1`,
+		userID,
+	).Scan(&totp.Secret, &totp.ConfirmedAt, &totp.LastUsedStep, &totp.CreatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &totp, nil
+}
+
+func (r *postgresTOTPRepository) Begin(ctx context.Context, userID int, secret string) error {
+	tag, err := r.db.Exec(ctx, `
+        INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE SET secret = $2, last_used_step = 0, created_at = NOW()
+        WHERE user_totp.confirmed_at IS NULL`,
+		userID, secret,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+func (r *postgresTOTPRepository) Confirm(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE user_totp SET confirmed_at = NOW(), last_used_step = $2 WHERE user_id = $1 AND confirmed_at IS NULL`,
+			userID, step,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+func (r *postgresTOTPRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE user_totp SET last_used_step = $2
+         WHERE user_id = $1 AND confirmed_at IS NOT NULL AND last_used_step < $2`,
+		userID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *postgresTOTPRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	// Satu kode bisa saja ter-generate dua kali; cukup pakai salah satu barisnya
+	tag, err := r.db.Exec(ctx, `
+        UPDATE recovery_codes SET used_at = NOW()
+        WHERE id = (
+            SELECT id FROM recovery_codes
+            WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+            LIMIT 1 FOR UPDATE
+        )`,
+		userID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *postgresTOTPRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+// replaceRecoveryCodes dipakai bersama oleh Confirm dan ReplaceRecoveryCodes di dalam transaksi
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO recovery_codes (user_id, code_hash) SELECT $1, unnest($2::text[])`,
+		userID, codeHashes,
+	)
+	return err
+}
+
+func (r *postgresTOTPRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	).Scan(&n)
+	return n, err
+}
+
+func (r *postgresTOTPRepository) Delete(ctx context.Context, userID int) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+// UserFilter filter untuk daftar user
+type UserFilter struct {
+	Role   string
+	Status *bool
+	Page
+}
+
+// UserUpdate perubahan data user; string kosong dan pointer nil berarti tidak diubah
+type UserUpdate struct {
+	Name     string
+	Phone    string
+	Username string
+	Password string
+	Role     models.UserRole
+	Status   *bool
+	EditedBy int
+	// ImpersonatedBy admin yang memerankan EditedBy; nil untuk perubahan oleh user sendiri
+	ImpersonatedBy *int
+}
+
+// UserRepository akses data tabel users
+type UserRepository interface {
+	// Create menyimpan user baru dan mengisi ID serta CreatedAt
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	// GetByUsername mengembalikan user aktif (belum dihapus) beserta hash password
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	List(ctx context.Context, filter UserFilter) ([]models.UserResponse, int, error)
+	Update(ctx context.Context, id int, update UserUpdate) error
+	SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error
+	// UpgradePasswordHash mengganti hash password bila hash saat ini masih oldHash, sehingga
+	// perubahan password yang terjadi bersamaan tidak tertimpa; tidak mengubah edited_at maupun edited_by
+	UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error
+	// Conflicts mengembalikan field ("username", "phone") yang nilainya sudah dipakai user lain
+	// yang belum dihapus; nilai kosong tidak diperiksa
+	Conflicts(ctx context.Context, id int, username, phone string) ([]string, error)
+	// AuthState mengembalikan role dan status user; user yang dihapus dianggap tidak aktif
+	AuthState(ctx context.Context, id int) (models.UserAuthState, error)
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+
+	"backend-go/internal/models"
+)
+
+type memoryUserRepository struct {
+	s *memoryStore
+}
+
+// conflict memeriksa unique username/phone di antara user aktif; pemanggil harus memegang lock
+func (r *memoryUserRepository) conflict(id int, username, phone string) bool {
+	for _, u := range r.s.users {
+		if u.ID == id || u.DeletedAt != nil {
+			continue
+		}
+		if (username != "" && u.Username == username) || (phone != "" && u.Phone == phone) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.insert(user)
+}
+
+// insert menyimpan user baru; pemanggil harus memegang lock tulis
+func (r *memoryUserRepository) insert(user *models.User) error {
+	if r.conflict(0, user.Username, user.Phone) {
+		return ErrDuplicate
+	}
+
+	user.ID = r.s.id("users")
+	user.Status = true
+	user.CreatedAt = now()
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, u := range r.s.users {
+		if u.Username == username && u.DeletedAt == nil {
+			return &u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, filter UserFilter) ([]models.UserResponse, int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var users []models.UserResponse
+	for _, id := range sortedIDs(r.s.users) {
+		u := r.s.users[id]
+		if u.DeletedAt != nil {
+			continue
+		}
+		if filter.Role != "" && string(u.Role) != filter.Role {
+			continue
+		}
+		if filter.Status != nil && u.Status != *filter.Status {
+			continue
+		}
+		users = append(users, u.Response())
+	}
+
+	return paginate(users, filter.Page), len(users), nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, id int, update UserUpdate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok || u.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if r.conflict(id, update.Username, update.Phone) {
+		return ErrDuplicate
+	}
+
+	if update.Name != "" {
+		u.Name = update.Name
+	}
+	if update.Phone != "" {
+		u.Phone = update.Phone
+	}
+	if update.Username != "" {
+		u.Username = update.Username
+	}
+	if update.Password != "" {
+		u.Password = update.Password
+	}
+	if update.Role != "" {
+		u.Role = update.Role
+	}
+	if update.Status != nil {
+		u.Status = *update.Status
+	}
+	editedAt := now()
+	u.EditedAt = &editedAt
+	u.EditedBy = &update.EditedBy
+	u.ImpersonatedBy = update.ImpersonatedBy
+
+	r.s.users[id] = u
+	return nil
+}
+
+func (r *memoryUserRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok || u.DeletedAt != nil {
+		return ErrNotFound
+	}
+	deletedAt := now()
+	u.DeletedAt = &deletedAt
+	u.DeletedBy = &deletedBy
+	u.ImpersonatedBy = impersonatedBy
+
+	r.s.users[id] = u
+	return nil
+}
+
+func (r *memoryUserRepository) AuthState(ctx context.Context, id int) (models.UserAuthState, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return models.UserAuthState{}, ErrNotFound
+	}
+	return models.UserAuthState{Role: u.Role, Active: u.Status && u.DeletedAt == nil}, nil
+}
+
+func (r *memoryUserRepository) Conflicts(ctx context.Context, id int, username, phone string) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	fields := []string{}
+	if r.conflict(id, username, "") {
+		fields = append(fields, "username")
+	}
+	if r.conflict(id, "", phone) {
+		fields = append(fields, "phone")
+	}
+	return fields, nil
+}
+
+func (r *memoryUserRepository) UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if ok && u.DeletedAt == nil && u.Password == oldHash {
+		u.Password = newHash
+		r.s.users[id] = u
+	}
+	return nil
+}
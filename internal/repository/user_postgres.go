@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend-go/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresUserRepository struct {
+	db *pgxpool.Pool
+}
+
+const userColumns = `id, name, phone, username, password, role, status,
+            created_at, created_by, edited_at, edited_by, deleted_at, deleted_by, impersonated_by`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Phone,
+		&user.Username,
+		&user.Password,
+		&user.Role,
+		&user.Status,
+		&user.CreatedAt,
+		&user.CreatedBy,
+		&user.EditedAt,
+		&user.EditedBy,
+		&user.DeletedAt,
+		&user.DeletedBy,
+		&user.ImpersonatedBy,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *postgresUserRepository) Create(ctx context.Context, user *models.User) error {
+	return insertUser(ctx, r.db, user)
+}
+
+// insertUser dipakai juga di dalam transaksi (mis. redeem undangan)
+func insertUser(ctx context.Context, q interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}, user *models.User) error {
+	query := `
+        INSERT INTO users (
+            name,
+            phone,
+            username,
+            password,
+            role,
+            created_by,
+            impersonated_by
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, status, created_at
+    `
+
+	err := q.QueryRow(ctx, query,
+		user.Name,
+		user.Phone,
+		user.Username,
+		user.Password,
+		user.Role,
+		user.CreatedBy,
+		user.ImpersonatedBy,
+	).Scan(&user.ID, &user.Status, &user.CreatedAt)
+
+	return translateError(err)
+}
+
+func (r *postgresUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+	return scanUser(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *postgresUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1 AND deleted_at IS NULL`
+	return scanUser(r.db.QueryRow(ctx, query, username))
+}
+
+func (r *postgresUserRepository) List(ctx context.Context, filter UserFilter) ([]models.UserResponse, int, error) {
+	var where whereBuilder
+	if filter.Role != "" {
+		where.add("role = $%d", filter.Role)
+	}
+	if filter.Status != nil {
+		where.add("status = $%d", *filter.Status)
+	}
+
+	query := fmt.Sprintf(`SELECT
+                id, name, phone, username, role, status,
+                created_at, created_by, edited_at, edited_by, impersonated_by
+              FROM users
+              WHERE deleted_at IS NULL%s
+              ORDER BY id LIMIT $%d OFFSET $%d`,
+		where.sql(), where.next(), where.next()+1,
+	)
+	args := append(append([]interface{}{}, where.args...), filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.UserResponse{}
+	for rows.Next() {
+		var user models.UserResponse
+		err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Phone,
+			&user.Username,
+			&user.Role,
+			&user.Status,
+			&user.CreatedAt,
+			&user.CreatedBy,
+			&user.EditedAt,
+			&user.EditedBy,
+			&user.ImpersonatedBy,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL` + where.sql()
+	if err := r.db.QueryRow(ctx, countQuery, where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *postgresUserRepository) Update(ctx context.Context, id int, update UserUpdate) error {
+	var (
+		sets []string
+		args []interface{}
+	)
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if update.Name != "" {
+		set("name", update.Name)
+	}
+	if update.Phone != "" {
+		set("phone", update.Phone)
+	}
+	if update.Username != "" {
+		set("username", update.Username)
+	}
+	if update.Password != "" {
+		set("password", update.Password)
+	}
+	if update.Role != "" {
+		set("role", update.Role)
+	}
+	if update.Status != nil {
+		set("status", *update.Status)
+	}
+	set("edited_by", update.EditedBy)
+	set("impersonated_by", update.ImpersonatedBy)
+	// Diisi eksplisit karena trigger edited_at dilewati bila hanya password yang berubah
+	sets = append(sets, "edited_at = NOW()")
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d AND deleted_at IS NULL",
+		strings.Join(sets, ", "), len(args),
+	)
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresUserRepository) SoftDelete(ctx context.Context, id, deletedBy int, impersonatedBy *int) error {
+	query := `
+        UPDATE users
+        SET deleted_at = $1, deleted_by = $2, impersonated_by = $3
+        WHERE id = $4 AND deleted_at IS NULL
+    `
+
+	result, err := r.db.Exec(ctx, query, time.Now().UTC(), deletedBy, impersonatedBy, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresUserRepository) AuthState(ctx context.Context, id int) (models.UserAuthState, error) {
+	var state models.UserAuthState
+	err := r.db.QueryRow(ctx,
+		`SELECT role, status AND deleted_at IS NULL FROM users WHERE id = $1`,
+		id,
+	).Scan(&state.Role, &state.Active)
+	if err != nil {
+		return models.UserAuthState{}, translateError(err)
+	}
+	return state, nil
+}
+
+func (r *postgresUserRepository) Conflicts(ctx context.Context, id int, username, phone string) ([]string, error) {
+	var usernameTaken, phoneTaken bool
+	err := r.db.QueryRow(ctx, `
+        SELECT
+            COALESCE(BOOL_OR(username = $2), FALSE),
+            COALESCE(BOOL_OR(phone = $3), FALSE)
+        FROM users
+        WHERE id <> $1 AND deleted_at IS NULL AND (username = $2 OR phone = $3)`,
+		id, username, phone,
+	).Scan(&usernameTaken, &phoneTaken)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []string{}
+	if usernameTaken && username != "" {
+		fields = append(fields, "username")
+	}
+	if phoneTaken && phone != "" {
+		fields = append(fields, "phone")
+	}
+	return fields, nil
+}
+
+// UpgradePasswordHash hanya mengubah kolom password, sehingga trigger users_set_edited_at
+// (migrasi 0018) tidak berjalan dan edited_at tetap menunjukkan perubahan terakhir oleh user
+func (r *postgresUserRepository) UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE users SET password = $3 WHERE id = $1 AND password = $2 AND deleted_at IS NULL`,
+		id, oldHash, newHash,
+	)
+	return err
+}
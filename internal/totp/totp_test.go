@@ -0,0 +1,106 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret kunci SHA1 dari RFC 6238 Appendix B ("12345678901234567890") dalam base32
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238(t *testing.T) {
+	// Kode 8 digit dari Appendix B; kode 6 digit adalah enam digit terakhirnya
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; got != want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestCodeSecretCase(t *testing.T) {
+	upper, err := Code(rfcSecret, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lower, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil || lower != upper {
+		t.Errorf("lowercase secret = %q, %v, want %q", lower, err, upper)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestVerifySkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{name: "current step", offset: 0, ok: true},
+		{name: "previous step", offset: -Skew, ok: true},
+		{name: "next step", offset: Skew, ok: true},
+		{name: "too old", offset: -Skew - 1},
+		{name: "too new", offset: Skew + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, current+tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			step, ok := Verify(rfcSecret, code, now)
+			if ok != tt.ok {
+				t.Fatalf("Verify = %v, want %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsMalformed(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Verify(rfcSecret, " "+code+" ", now); !ok {
+		t.Error("code with surrounding spaces rejected")
+	}
+	for _, bad := range []string{"", code[:5], code + "0", "abcdef"} {
+		if _, ok := Verify(rfcSecret, bad, now); ok {
+			t.Errorf("Verify(%q) accepted", bad)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Backend Compro", "alice", rfcSecret)
+	for _, part := range []string{"otpauth://totp/Backend%20Compro:alice?", "secret=" + rfcSecret, "digits=6", "period=30", "algorithm=SHA1"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("URI %s does not contain %s", uri, part)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"backend-go/internal/apperror"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Lang bahasa pesan validasi
+type Lang string
+
+const (
+	English    Lang = "en"
+	Indonesian Lang = "id"
+)
+
+// messages template pesan per aturan; %[1]s nama field, %[2]s parameter aturan.
+// Kunci dengan akhiran ".number" dipakai saat field berupa angka.
+var messages = map[Lang]map[string]string{
+	English: {
+		"summary":    "Validation failed",
+		"required":   "%[1]s is required",
+		"min":        "%[1]s must be at least %[2]s characters",
+		"min.number": "%[1]s must be at least %[2]s",
+		"max":        "%[1]s must be at most %[2]s characters",
+		"max.number": "%[1]s must be at most %[2]s",
+		"len":        "%[1]s must be exactly %[2]s characters",
+		"len.number": "%[1]s must equal %[2]s",
+		"oneof":      "%[1]s must be one of: %[2]s",
+		"e164":       "%[1]s must be a phone number in international format, e.g. +6281234567890",
+		"notblank":   "%[1]s must not be blank",
+		"alphanum":   "%[1]s may only contain letters and numbers",
+		"number":     "%[1]s must contain digits only",
+		"decimal":    "%[1]s must be a non-negative number with at most %[2]s decimal places",
+		"datetime":   "%[1]s must be a date in the format %[2]s",
+		"url":        "%[1]s must be a valid URL",
+		"password":   "%[1]s must contain at least one uppercase letter, one lowercase letter, and one digit",
+		"filetype":   "%[1]s must be a file of type: %[2]s",
+		"unique":     "%[1]s is already taken",
+
+		"password_upper":    "%[1]s must contain at least one uppercase letter",
+		"password_lower":    "%[1]s must contain at least one lowercase letter",
+		"password_digit":    "%[1]s must contain at least one digit",
+		"password_symbol":   "%[1]s must contain at least one symbol",
+		"password_personal": "%[1]s must not contain your username or phone number",
+		"password_common":   "%[1]s is too common, choose one that is harder to guess",
+	},
+	Indonesian: {
+		"summary":    "Validasi gagal",
+		"required":   "%[1]s wajib diisi",
+		"min":        "%[1]s minimal %[2]s karakter",
+		"min.number": "%[1]s minimal %[2]s",
+		"max":        "%[1]s maksimal %[2]s karakter",
+		"max.number": "%[1]s maksimal %[2]s",
+		"len":        "%[1]s harus tepat %[2]s karakter",
+		"len.number": "%[1]s harus bernilai %[2]s",
+		"oneof":      "%[1]s harus salah satu dari: %[2]s",
+		"e164":       "%[1]s harus berupa nomor telepon format internasional, contoh +6281234567890",
+		"notblank":   "%[1]s tidak boleh kosong",
+		"alphanum":   "%[1]s hanya boleh mengandung huruf dan angka",
+		"number":     "%[1]s harus berupa angka",
+		"decimal":    "%[1]s harus berupa angka tidak negatif dengan maksimal %[2]s angka desimal",
+		"datetime":   "%[1]s harus berupa tanggal dengan format %[2]s",
+		"url":        "%[1]s harus berupa URL yang valid",
+		"password":   "%[1]s harus mengandung minimal 1 huruf besar, 1 huruf kecil, dan 1 angka",
+		"filetype":   "%[1]s harus berupa file dengan tipe: %[2]s",
+		"unique":     "%[1]s sudah dipakai",
+
+		"password_upper":    "%[1]s harus mengandung minimal 1 huruf besar",
+		"password_lower":    "%[1]s harus mengandung minimal 1 huruf kecil",
+		"password_digit":    "%[1]s harus mengandung minimal 1 angka",
+		"password_symbol":   "%[1]s harus mengandung minimal 1 simbol",
+		"password_personal": "%[1]s tidak boleh mengandung username atau nomor telepon",
+		"password_common":   "%[1]s terlalu umum, pilih yang lebih sulit ditebak",
+	},
+}
+
+// Message merender pesan error dalam bahasa yang diminta
+func (e FieldError) Message(lang Lang) string {
+	catalog, ok := messages[lang]
+	if !ok {
+		catalog = messages[English]
+	}
+
+	key := e.Rule
+	if e.numeric {
+		if _, ok := catalog[key+".number"]; ok {
+			key += ".number"
+		}
+	}
+	template, ok := catalog[key]
+	if !ok {
+		return fmt.Sprintf("%s is invalid", e.Field)
+	}
+	return fmt.Sprintf(template, e.Field, displayParam(e.Rule, e.Param))
+}
+
+// displayParam mengubah parameter teknis menjadi bentuk yang mudah dibaca
+func displayParam(rule, param string) string {
+	switch rule {
+	case "oneof", "filetype":
+		return strings.Join(strings.Fields(param), ", ")
+	case "datetime":
+		r := strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD", "15", "HH", "04", "mm", "05", "ss")
+		return r.Replace(param)
+	}
+	return param
+}
+
+// LanguageFromRequest memilih bahasa dari header Accept-Language, default English
+func LanguageFromRequest(c *fiber.Ctx) Lang {
+	if c.AcceptsLanguages(string(English), string(Indonesian)) == string(Indonesian) {
+		return Indonesian
+	}
+	return English
+}
+
+// Summary pesan ringkas untuk envelope error validasi
+func Summary(lang Lang) string {
+	if catalog, ok := messages[lang]; ok {
+		return catalog["summary"]
+	}
+	return messages[English]["summary"]
+}
+
+// Validate memvalidasi struct request dan mengembalikan error validasi
+// dengan detail per field dalam bahasa sesuai Accept-Language. Tag yang tidak valid
+// menjadi error 500, bukan panic.
+func Validate(c *fiber.Ctx, s any) error {
+	errs, err := Struct(s)
+	if err != nil {
+		return apperror.Wrap(err, "Invalid validation rules")
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	lang := LanguageFromRequest(c)
+	details := make([]apperror.FieldError, 0, len(errs))
+	for _, e := range errs {
+		details = append(details, apperror.FieldError{Field: e.Field, Message: e.Message(lang)})
+	}
+	return apperror.Validation(Summary(lang), details...)
+}
+
+// Field membuat error validasi untuk satu field yang dicek di luar tag, misalnya file upload
+func Field(c *fiber.Ctx, field, rule, param string) error {
+	lang := LanguageFromRequest(c)
+	e := FieldError{Field: field, Rule: rule, Param: param}
+	return apperror.Validation(Summary(lang), apperror.FieldError{Field: field, Message: e.Message(lang)})
+}
+
+// Conflict membuat error 409 untuk field yang nilainya sudah dipakai data lain
+func Conflict(c *fiber.Ctx, message string, fields ...string) error {
+	lang := LanguageFromRequest(c)
+	details := make([]apperror.FieldError, 0, len(fields))
+	for _, field := range fields {
+		e := FieldError{Field: field, Rule: "unique"}
+		details = append(details, apperror.FieldError{Field: field, Message: e.Message(lang)})
+	}
+	return apperror.Conflict(message).WithDetails(details...)
+}
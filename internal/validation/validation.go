@@ -0,0 +1,217 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+)
+
+var decimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// FieldError satu aturan yang gagal pada satu field
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+	// numeric true bila field berupa angka, sehingga min/max dibaca sebagai nilai, bukan panjang
+	numeric bool
+}
+
+// rules aturan yang boleh dipakai di tag `validate`. Fungsi nil berarti aturan bawaan
+// go-playground/validator; aturan bawaan lain ditolak Check karena belum punya template pesan.
+var rules = map[string]validator.Func{
+	"required": nil,
+	"min":      nil,
+	"max":      nil,
+	"len":      nil,
+	"oneof":    nil,
+	"e164":     nil,
+	"alphanum": nil,
+	"number":   nil,
+	"datetime": nil,
+	"url":      nil,
+	// notblank melengkapi required, yang menerima string berisi spasi saja
+	"notblank": validators.NotBlank,
+	"decimal": func(fl validator.FieldLevel) bool {
+		s := fl.Field().String()
+		if !decimalPattern.MatchString(s) {
+			return false
+		}
+		places, err := strconv.Atoi(fl.Param())
+		if err != nil {
+			return true
+		}
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			return len(s)-i-1 <= places
+		}
+		return true
+	},
+	// password: minimal satu huruf besar, satu huruf kecil, dan satu angka
+	"password": func(fl validator.FieldLevel) bool {
+		if fl.Field().Kind() != reflect.String {
+			return true
+		}
+		var upper, lower, digit bool
+		for _, r := range fl.Field().String() {
+			switch {
+			case unicode.IsUpper(r):
+				upper = true
+			case unicode.IsLower(r):
+				lower = true
+			case unicode.IsDigit(r):
+				digit = true
+			}
+		}
+		return upper && lower && digit
+	},
+}
+
+// validate instance bersama; aman dipakai bersamaan setelah aturan tambahan didaftarkan
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	for rule, fn := range rules {
+		if fn == nil {
+			continue
+		}
+		if err := v.RegisterValidation(rule, fn); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// checked hasil Check per tipe struct, karena tag tidak berubah selama program berjalan
+var checked sync.Map
+
+// CheckTag memeriksa satu tag `validate` tanpa menjalankannya: setiap aturan harus dikenal
+// dan parameternya dapat dipakai
+func CheckTag(tag string) error {
+	for _, part := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if rule == "omitempty" {
+			continue
+		}
+		if _, ok := rules[rule]; !ok {
+			return fmt.Errorf("unknown rule %q", rule)
+		}
+		switch rule {
+		case "min", "max", "len":
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				return fmt.Errorf("rule %s: invalid numeric parameter %q", rule, param)
+			}
+		case "decimal":
+			if _, err := strconv.Atoi(param); err != nil {
+				return fmt.Errorf("rule %s: invalid number of decimal places %q", rule, param)
+			}
+		case "oneof", "datetime":
+			if param == "" {
+				return fmt.Errorf("rule %s requires a parameter", rule)
+			}
+		}
+	}
+	return nil
+}
+
+// Check memeriksa tag `validate` pada seluruh field struct s (termasuk struct embedded)
+func Check(s any) error {
+	t := reflect.TypeOf(s)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if result, ok := checked.Load(t); ok {
+		err, _ := result.(error)
+		return err
+	}
+	err := checkStruct(t)
+	checked.Store(t, err)
+	return err
+}
+
+func checkStruct(t reflect.Type) error {
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := checkStruct(field.Type); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+		if err := CheckTag(tag); err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Struct mengevaluasi tag `validate` pada seluruh field struct (termasuk struct embedded)
+// dan mengembalikan error per field sesuai urutan deklarasi, satu per field pada aturan
+// pertama yang gagal. Tag yang tidak lolos Check dikembalikan sebagai error tanpa dijalankan,
+// karena validator panic pada aturan yang tidak dikenal.
+func Struct(s any) ([]FieldError, error) {
+	if err := Check(s); err != nil {
+		return nil, err
+	}
+
+	err := validate.Struct(s)
+	var invalid *validator.InvalidValidationError
+	if err == nil || errors.As(err, &invalid) {
+		// nil atau bukan struct: tidak ada yang divalidasi
+		return nil, nil
+	}
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, err
+	}
+
+	errs := make([]FieldError, 0, len(fieldErrs))
+	for _, e := range fieldErrs {
+		errs = append(errs, FieldError{Field: e.Field(), Rule: e.Tag(), Param: e.Param(), numeric: isNumber(e.Kind())})
+	}
+	return errs, nil
+}
+
+// fieldName memakai nama dari tag json atau form agar sama dengan yang dikirim client
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return strings.ToLower(field.Name)
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	type request struct {
+		Name     string   `json:"name" validate:"required,notblank,min=3,max=5"`
+		Code     string   `json:"code" validate:"omitempty,len=6,number"`
+		Quantity int      `json:"quantity" validate:"omitempty,min=1,max=10"`
+		Rating   *int     `json:"rating" validate:"omitempty,len=5"`
+		Role     string   `json:"role" validate:"omitempty,oneof=admin staff user"`
+		Phone    string   `json:"phone" validate:"omitempty,e164"`
+		Slug     string   `json:"slug" validate:"omitempty,alphanum"`
+		Price    string   `json:"price" validate:"omitempty,decimal=2"`
+		Date     string   `json:"date" validate:"omitempty,datetime=2006-01-02"`
+		Link     string   `json:"link" validate:"omitempty,url"`
+		Password string   `json:"password" validate:"omitempty,password"`
+		Tags     []string `json:"tags" validate:"omitempty,max=2"`
+	}
+	valid := func() request { return request{Name: "abcd"} }
+	five, six := 5, 6
+
+	tests := []struct {
+		name   string
+		modify func(r *request)
+		rule   string
+		en     string
+		id     string
+	}{
+		{name: "valid", modify: func(r *request) {
+			r.Code, r.Quantity, r.Rating, r.Role = "123456", 10, &five, "staff"
+			r.Phone, r.Slug = "+6281234567890", "abc123"
+			r.Price, r.Date, r.Link = "10.50", "2024-02-29", "https://example.com/a"
+			r.Password, r.Tags = "Secret123", []string{"a", "b"}
+		}},
+		{name: "required", modify: func(r *request) { r.Name = "" }, rule: "required",
+			en: "name is required", id: "name wajib diisi"},
+		{name: "notblank", modify: func(r *request) { r.Name = "   " }, rule: "notblank",
+			en: "name must not be blank", id: "name tidak boleh kosong"},
+		{name: "min", modify: func(r *request) { r.Name = "ab" }, rule: "min",
+			en: "name must be at least 3 characters", id: "name minimal 3 karakter"},
+		{name: "min counts runes", modify: func(r *request) { r.Name = "été" }},
+		{name: "max", modify: func(r *request) { r.Name = "abcdef" }, rule: "max",
+			en: "name must be at most 5 characters", id: "name maksimal 5 karakter"},
+		{name: "len", modify: func(r *request) { r.Code = "12345" }, rule: "len",
+			en: "code must be exactly 6 characters", id: "code harus tepat 6 karakter"},
+		{name: "number", modify: func(r *request) { r.Code = "12345a" }, rule: "number",
+			en: "code must contain digits only", id: "code harus berupa angka"},
+		{name: "number rejects signs", modify: func(r *request) { r.Code = "+12345" }, rule: "number",
+			en: "code must contain digits only", id: "code harus berupa angka"},
+		{name: "min number", modify: func(r *request) { r.Quantity = -1 }, rule: "min",
+			en: "quantity must be at least 1", id: "quantity minimal 1"},
+		{name: "max number", modify: func(r *request) { r.Quantity = 11 }, rule: "max",
+			en: "quantity must be at most 10", id: "quantity maksimal 10"},
+		{name: "len number through pointer", modify: func(r *request) { r.Rating = &six }, rule: "len",
+			en: "rating must equal 5", id: "rating harus bernilai 5"},
+		{name: "oneof", modify: func(r *request) { r.Role = "root" }, rule: "oneof",
+			en: "role must be one of: admin, staff, user", id: "role harus salah satu dari: admin, staff, user"},
+		{name: "e164", modify: func(r *request) { r.Phone = "081234567890" }, rule: "e164",
+			en: "phone must be a phone number in international format, e.g. +6281234567890",
+			id: "phone harus berupa nomor telepon format internasional, contoh +6281234567890"},
+		{name: "alphanum", modify: func(r *request) { r.Slug = "abc-123" }, rule: "alphanum",
+			en: "slug may only contain letters and numbers", id: "slug hanya boleh mengandung huruf dan angka"},
+		{name: "decimal places", modify: func(r *request) { r.Price = "10.505" }, rule: "decimal",
+			en: "price must be a non-negative number with at most 2 decimal places",
+			id: "price harus berupa angka tidak negatif dengan maksimal 2 angka desimal"},
+		{name: "decimal negative", modify: func(r *request) { r.Price = "-1" }, rule: "decimal",
+			en: "price must be a non-negative number with at most 2 decimal places",
+			id: "price harus berupa angka tidak negatif dengan maksimal 2 angka desimal"},
+		{name: "datetime", modify: func(r *request) { r.Date = "2023-02-29" }, rule: "datetime",
+			en: "date must be a date in the format YYYY-MM-DD", id: "date harus berupa tanggal dengan format YYYY-MM-DD"},
+		{name: "url", modify: func(r *request) { r.Link = "example.com" }, rule: "url",
+			en: "link must be a valid URL", id: "link harus berupa URL yang valid"},
+		{name: "password", modify: func(r *request) { r.Password = "secret123" }, rule: "password",
+			en: "password must contain at least one uppercase letter, one lowercase letter, and one digit",
+			id: "password harus mengandung minimal 1 huruf besar, 1 huruf kecil, dan 1 angka"},
+		{name: "max slice", modify: func(r *request) { r.Tags = []string{"a", "b", "c"} }, rule: "max",
+			en: "tags must be at most 2 characters", id: "tags maksimal 2 karakter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+
+			errs, err := Struct(&req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.rule == "" {
+				if len(errs) != 0 {
+					t.Fatalf("Struct() = %+v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("Struct() = %+v, want one error", errs)
+			}
+			if errs[0].Rule != tt.rule {
+				t.Errorf("rule = %q, want %q", errs[0].Rule, tt.rule)
+			}
+			if got := errs[0].Message(English); got != tt.en {
+				t.Errorf("Message(English) = %q, want %q", got, tt.en)
+			}
+			if got := errs[0].Message(Indonesian); got != tt.id {
+				t.Errorf("Message(Indonesian) = %q, want %q", got, tt.id)
+			}
+		})
+	}
+}
+
+func TestStructStopsAtFirstFailedRule(t *testing.T) {
+	type Base struct {
+		Email string `json:"email" validate:"required"`
+	}
+	type request struct {
+		Base
+		Code string `form:"code" validate:"required,len=6,number"`
+	}
+
+	errs, err := Struct(&request{Code: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Struct() = %+v, want two errors", errs)
+	}
+	if errs[0].Field != "email" || errs[0].Rule != "required" {
+		t.Errorf("errs[0] = %+v, want email required", errs[0])
+	}
+	if errs[1].Field != "code" || errs[1].Rule != "len" {
+		t.Errorf("errs[1] = %+v, want code len", errs[1])
+	}
+}
+
+func TestMessageFallback(t *testing.T) {
+	e := FieldError{Field: "name", Rule: "required"}
+	if got := e.Message(Lang("fr")); got != "name is required" {
+		t.Errorf("Message(fr) = %q, want the English message", got)
+	}
+	e = FieldError{Field: "name", Rule: "custom"}
+	if got := e.Message(Indonesian); got != "name is invalid" {
+		t.Errorf("Message() for rule without template = %q", got)
+	}
+}
+
+func TestCheckTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "required,min=3,max=100"},
+		{tag: "omitempty, oneof=a b, len=6"},
+		{tag: "required,emial", want: `unknown rule "emial"`},
+		{tag: "max=ten", want: `invalid numeric parameter "ten"`},
+		{tag: "len", want: `invalid numeric parameter ""`},
+		{tag: "decimal=2.5", want: "invalid number of decimal places"},
+		{tag: "oneof", want: "requires a parameter"},
+		{tag: "datetime=", want: "requires a parameter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			err := CheckTag(tt.tag)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("CheckTag() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("CheckTag() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	type Base struct {
+		Role string `validate:"oneof"`
+	}
+	type request struct {
+		Base
+		Name  string `validate:"required,mx=5"`
+		Email string `validate:"required"`
+	}
+
+	err := Check(&request{})
+	if err == nil {
+		t.Fatal("Check() = nil, want error")
+	}
+	for _, want := range []string{"Base.Role", "request.Name"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Check() = %v, want it to mention %s", err, want)
+		}
+	}
+	// Hasil di-cache per tipe
+	if again := Check(request{}); again == nil || again.Error() != err.Error() {
+		t.Errorf("second Check() = %v, want %v", again, err)
+	}
+
+	// Struct tidak panic pada aturan yang tidak dikenal, tetapi mengembalikan hasil Check
+	if errs, err := Struct(&request{Name: "x", Email: "a@b.c"}); err == nil || len(errs) != 0 {
+		t.Errorf("Struct() = %+v, %v, want the Check error", errs, err)
+	}
+}
@@ -0,0 +1,45 @@
+package version
+
+import "runtime/debug"
+
+// Nilai diisi saat build, contoh:
+// go build -ldflags "-X backend-go/internal/version.Version=v1.2.0 -X backend-go/internal/version.Commit=abc123"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info informasi build yang dilaporkan endpoint health
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get mengembalikan informasi build, memakai metadata VCS dari toolchain jika ldflags tidak diisi
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// withInviteTTL masa berlaku bawaan undangan, yang tidak diisi di config test
+func withInviteTTL(cfg *config.Config) { cfg.Auth.InviteTTL = 72 * time.Hour }
+
+// register mendaftarkan akun baru lewat /register dengan kode undangan opsional
+func (a *testApp) register(t *testing.T, username, inviteCode string) response {
+	t.Helper()
+	req := models.RegisterRequest{
+		Name:       "User " + username,
+		Phone:      fmt.Sprintf("+62813%08d", phones.Add(1)),
+		Username:   username,
+		Password:   "Secret123",
+		InviteCode: inviteCode,
+	}
+	return a.json(t, http.MethodPost, "/api/v1/register", req, "")
+}
+
+func (a *testApp) userRole(t *testing.T, id int) models.UserRole {
+	t.Helper()
+	user, err := a.repos.Users.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.Role
+}
+
+func TestInviteSingleUse(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	r := a.json(t, http.MethodPost, "/api/v1/invites", models.CreateInviteRequest{Role: models.RoleStaff, ExpiresInHours: 1}, token)
+	code := r.string("code")
+	if r.status != http.StatusCreated || code == "" {
+		t.Fatalf("create invite = %d %v", r.status, r.body)
+	}
+
+	// Username yang sudah dipakai tidak menghabiskan undangan
+	if r := a.register(t, "admin", code); r.status != http.StatusConflict {
+		t.Fatalf("register duplicate = %d %v, want 409", r.status, r.body)
+	}
+
+	r = a.register(t, "newstaff", code)
+	if r.status != http.StatusCreated {
+		t.Fatalf("register = %d %v", r.status, r.body)
+	}
+	if role := a.userRole(t, r.id()); role != models.RoleStaff {
+		t.Errorf("role = %s, want the invite's role staff", role)
+	}
+	if r := a.register(t, "another", code); r.status != http.StatusBadRequest {
+		t.Errorf("reused invite = %d %v, want 400", r.status, r.body)
+	}
+
+	list := a.json(t, http.MethodGet, "/api/v1/invites", nil, token)
+	data, _ := list.body["data"].([]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["used_by"] != float64(r.id()) {
+		t.Errorf("invites = %v, want one invite used by %d", list.body, r.id())
+	}
+}
+
+func TestInviteExpiryAndRevocation(t *testing.T) {
+	a := newTestApp(t, withInviteTTL)
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	// Undangan kedaluwarsa disimpan langsung karena masa berlaku minimal lewat API adalah satu jam
+	sum := sha256.Sum256([]byte("expired-code"))
+	expired := &models.Invite{Role: models.RoleStaff, CreatedBy: admin.ID, ExpiresAt: time.Now().Add(-time.Second)}
+	if err := a.repos.Invites.Create(context.Background(), expired, hex.EncodeToString(sum[:])); err != nil {
+		t.Fatal(err)
+	}
+	if r := a.register(t, "late", "expired-code"); r.status != http.StatusBadRequest {
+		t.Errorf("expired invite = %d %v, want 400", r.status, r.body)
+	}
+
+	r := a.json(t, http.MethodPost, "/api/v1/invites", models.CreateInviteRequest{Role: models.RoleStaff}, token)
+	if r.status != http.StatusCreated {
+		t.Fatalf("create invite = %d %v", r.status, r.body)
+	}
+	if at, err := time.Parse(time.RFC3339, r.string("expires_at")); err != nil || time.Until(at) < 71*time.Hour {
+		t.Errorf("expires_at = %q, want the default TTL of 72h", r.string("expires_at"))
+	}
+	code := r.string("code")
+	if r := a.json(t, http.MethodDelete, fmt.Sprintf("/api/v1/invites/%d", r.id()), nil, token); r.status != http.StatusNoContent {
+		t.Fatalf("revoke invite = %d %v", r.status, r.body)
+	}
+	if r := a.register(t, "revoked", code); r.status != http.StatusBadRequest {
+		t.Errorf("revoked invite = %d %v, want 400", r.status, r.body)
+	}
+	if r := a.register(t, "unknown", "no-such-code"); r.status != http.StatusBadRequest {
+		t.Errorf("unknown invite = %d %v, want 400", r.status, r.body)
+	}
+}
+
+func TestPublicRegistration(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		a := newTestApp(t, nil)
+		if r := a.register(t, "mallory", ""); r.status != http.StatusForbidden {
+			t.Errorf("register without invite = %d %v, want 403", r.status, r.body)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.PublicRegistration = true })
+		// Field role yang tidak dikenal RegisterRequest diabaikan; akun publik selalu ber-role user
+		body := map[string]string{"name": "Mallory", "phone": "+6281300000099", "username": "mallory", "password": "Secret123", "role": "admin"}
+		r := a.json(t, http.MethodPost, "/api/v1/register", body, "")
+		if r.status != http.StatusCreated {
+			t.Fatalf("register = %d %v", r.status, r.body)
+		}
+		if role := a.userRole(t, r.id()); role != models.RoleUser {
+			t.Errorf("role = %s, want user", role)
+		}
+	})
+}
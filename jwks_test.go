@@ -0,0 +1,178 @@
+package main
+
+import (
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/jwtkeys"
+	"backend-go/internal/models"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ed25519Key membuat kunci Ed25519 baru lewat PEM PKCS#8, seperti yang dibaca dari JWT_SIGNING_KEY_FILE
+func ed25519Key(t *testing.T) *jwtkeys.Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwtkeys.ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// withKeys menandatangani token dengan signing dan menerima kunci verification
+func withKeys(t *testing.T, signing *jwtkeys.Key, verification ...*jwtkeys.Key) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		keys, err := jwtkeys.NewSet(signing, verification...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.JWT.Keys = keys
+	}
+}
+
+func (a *testApp) jwks(t *testing.T) jwtkeys.JWKS {
+	t.Helper()
+	resp, err := a.app.Test(httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Cache-Control") == "" {
+		t.Fatalf("jwks = %d Cache-Control %q", resp.StatusCode, resp.Header.Get("Cache-Control"))
+	}
+	var jwks jwtkeys.JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		t.Fatal(err)
+	}
+	return jwks
+}
+
+func TestJWKS(t *testing.T) {
+	signing := ed25519Key(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, err := jwtkeys.ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestApp(t, withKeys(t, signing, previous, jwtkeys.FromSecret("legacy-secret-legacy-secret-0000")))
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+
+	// Kunci penandatangan lebih dulu, secret HS256 tidak pernah dipublikasikan
+	jwks := a.jwks(t)
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("jwks = %+v, want the signing key and the previous key", jwks)
+	}
+	if k := jwks.Keys[0]; k.Kid != signing.ID || k.Kty != "OKP" || k.Crv != "Ed25519" || k.Alg != "EdDSA" || k.Use != "sig" {
+		t.Errorf("keys[0] = %+v, want the Ed25519 signing key", k)
+	}
+	if k := jwks.Keys[1]; k.Kid != previous.ID || k.Kty != "RSA" || k.Alg != "RS256" || k.E != "AQAB" {
+		t.Errorf("keys[1] = %+v, want the RSA verification key", k)
+	}
+
+	// Layanan lain memverifikasi access token hanya dengan JWKS
+	token := a.login(t, "alice", "Secret123")
+	if header := tokenHeader(t, token); header["kid"] != signing.ID || header["alg"] != "EdDSA" {
+		t.Fatalf("token header = %v, want kid %s and EdDSA", header, signing.ID)
+	}
+	verifier, err := jwtkeys.FromJWK(jwks.Keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(token, &models.Claims{}); err != nil {
+		t.Errorf("verify with the published key: %v", err)
+	}
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	// Contoh RFC 7638 bagian 3.1
+	key, err := jwtkeys.FromJWK(jwtkeys.JWK{
+		Kty: "RSA",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECP" +
+			"ebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY" +
+			"368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0f" +
+			"M4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E: "AQAB",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; key.ID != want {
+		t.Errorf("kid = %s, want %s", key.ID, want)
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	oldKey, newKey := ed25519Key(t), ed25519Key(t)
+	a := newTestApp(t, withKeys(t, oldKey))
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token := a.login(t, "alice", "Secret123")
+
+	// rotated menjalankan aplikasi kedua di atas data yang sama dengan kunci lain, seperti setelah deploy
+	rotated := func(signing *jwtkeys.Key, verification ...*jwtkeys.Key) *testApp {
+		cfg := *a.cfg
+		withKeys(t, signing, verification...)(&cfg)
+		tasks := background.New()
+		app, _ := newApp(&cfg, nil, a.repos, tasks)
+		return &testApp{app: app, repos: a.repos, cfg: &cfg, tasks: tasks}
+	}
+
+	// Tahap rotasi: kunci baru menandatangani, kunci lama masih diterima
+	b := rotated(newKey, oldKey)
+	if r := b.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusOK {
+		t.Errorf("old token during rotation = %d %v, want 200", r.status, r.body)
+	}
+	newToken := b.login(t, "alice", "Secret123")
+	if kid, _ := tokenHeader(t, newToken)["kid"].(string); kid != newKey.ID {
+		t.Errorf("new token kid = %q, want %s", kid, newKey.ID)
+	}
+	if jwks := b.jwks(t); len(jwks.Keys) != 2 || jwks.Keys[0].Kid != newKey.ID || jwks.Keys[1].Kid != oldKey.ID {
+		t.Errorf("jwks during rotation = %+v, want the new key then the old key", jwks)
+	}
+	// Instance yang belum dirotasi tidak mengenal kunci baru
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, newToken); r.status != http.StatusUnauthorized {
+		t.Errorf("new token on an instance without the new key = %d %v, want 401", r.status, r.body)
+	}
+
+	// Setelah kunci lama dilepas, token lama ditolak
+	c := rotated(newKey)
+	if r := c.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusUnauthorized {
+		t.Errorf("old token after rotation = %d %v, want 401", r.status, r.body)
+	}
+	if r := c.json(t, http.MethodGet, "/api/v1/me", nil, newToken); r.status != http.StatusOK {
+		t.Errorf("new token after rotation = %d %v, want 200", r.status, r.body)
+	}
+}
+
+func tokenHeader(t *testing.T, token string) map[string]interface{} {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &models.Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Header
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLoginThrottleWait(t *testing.T) {
+	throttle := repository.LoginThrottle{Delay: time.Second, MaxDelay: time.Minute}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 1, want: 0},
+		{failures: 2, want: time.Second},
+		{failures: 3, want: 2 * time.Second},
+		{failures: 4, want: 4 * time.Second},
+		{failures: 7, want: 32 * time.Second},
+		{failures: 8, want: time.Minute},
+		{failures: 100, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := throttle.Wait(tt.failures); got != tt.want {
+			t.Errorf("Wait(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func (a *testApp) tryLogin(t *testing.T, username, password string) response {
+	t.Helper()
+	return a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: username, Password: password}, "")
+}
+
+func TestLoginLockout(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.LoginMaxFailures = 3 })
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	alice := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	adminToken := a.login(t, "admin", "Secret123")
+
+	for _, username := range []string{"alice", "ghost"} {
+		for i := range a.cfg.Auth.LoginMaxFailures {
+			if r := a.tryLogin(t, username, "wrong"); r.status != http.StatusUnauthorized {
+				t.Fatalf("%s failure %d = %d %v, want 401", username, i+1, r.status, r.body)
+			}
+		}
+		// Username yang tidak ada dikunci dengan cara yang sama agar tidak bisa dibedakan
+		r := a.tryLogin(t, username, "Secret123")
+		if r.status != http.StatusTooManyRequests {
+			t.Fatalf("%s after %d failures = %d %v, want 429", username, a.cfg.Auth.LoginMaxFailures, r.status, r.body)
+		}
+		if got, want := r.header.Get("Retry-After"), fmt.Sprint(int(a.cfg.Auth.LoginLockout.Seconds())); got != want {
+			t.Errorf("%s Retry-After = %q, want %s", username, got, want)
+		}
+	}
+
+	r := a.json(t, http.MethodGet, "/api/v1/lockout-events?username=alice", nil, adminToken)
+	events, _ := r.body["data"].([]interface{})
+	if len(events) != 1 {
+		t.Fatalf("lockout events = %d %v, want one", r.status, r.body)
+	}
+	event := events[0].(map[string]interface{})
+	if event["event"] != models.LockoutEventLocked || event["failures"] != float64(3) || event["user_id"] != float64(alice.ID) {
+		t.Errorf("event = %v, want alice locked after 3 failures", event)
+	}
+
+	r = a.json(t, http.MethodPost, fmt.Sprintf("/api/v1/users/%d/unlock", alice.ID), nil, adminToken)
+	if r.status != http.StatusOK || r.body["unlocked"] != true {
+		t.Fatalf("unlock = %d %v", r.status, r.body)
+	}
+	a.login(t, "alice", "Secret123")
+	r = a.json(t, http.MethodGet, "/api/v1/lockout-events?username=alice", nil, adminToken)
+	if events, _ := r.body["data"].([]interface{}); len(events) != 2 || events[0].(map[string]interface{})["event"] != models.LockoutEventUnlocked {
+		t.Errorf("lockout events after unlock = %v, want the unlock first", r.body)
+	}
+}
+
+func TestLoginProgressiveDelay(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.LoginDelay = 20 * time.Second })
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+
+	// Kegagalan pertama tanpa jeda; setelah kegagalan kedua percobaan berikutnya menunggu LoginDelay
+	for i := range 2 {
+		if r := a.tryLogin(t, "alice", "wrong"); r.status != http.StatusUnauthorized {
+			t.Fatalf("failure %d = %d %v, want 401", i+1, r.status, r.body)
+		}
+	}
+	r := a.tryLogin(t, "alice", "Secret123")
+	if r.status != http.StatusTooManyRequests || r.header.Get("Retry-After") != "20" {
+		t.Fatalf("login during delay = %d Retry-After %q %v, want 429 after 20s", r.status, r.header.Get("Retry-After"), r.body)
+	}
+
+	// Jeda berlaku per username
+	a.createUser(t, "bob", "Secret123", models.RoleUser)
+	a.login(t, "bob", "Secret123")
+}
+
+func TestLoginRateLimitPerIP(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.LoginRateLimit = 3 })
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+
+	for i := range 3 {
+		if r := a.tryLogin(t, fmt.Sprintf("user%d", i), "wrong"); r.status != http.StatusUnauthorized {
+			t.Fatalf("request %d = %d %v, want 401", i+1, r.status, r.body)
+		}
+	}
+	// Batas per IP berlaku untuk username apa pun, termasuk yang belum pernah gagal
+	r := a.tryLogin(t, "alice", "Secret123")
+	if r.status != http.StatusTooManyRequests || r.header.Get("Retry-After") == "" {
+		t.Errorf("request over the limit = %d Retry-After %q %v, want 429", r.status, r.header.Get("Retry-After"), r.body)
+	}
+}
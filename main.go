@@ -1,126 +1,167 @@
 package main
 
 import (
+	"backend-go/internal/background"
+	"backend-go/internal/config"
 	"backend-go/internal/database"
-	"backend-go/internal/handlers"
-	"backend-go/internal/middleware"
-	"fmt"
+	"backend-go/internal/repository"
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/joho/godotenv"
 )
 
+// @title        Backend Compro API
+// @version      1.0
+// @description  REST API for the company profile backend: users, carousel, products, portfolio, and messages.
+// @BasePath     /api/v1
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT token or API key in the format "Bearer <token>"; API keys may also be sent as X-API-Key
 func main() {
-	// Load environment variables
-	err := godotenv.Load()
+	// Subcommand openapi-check tidak butuh konfigurasi maupun database, sehingga bisa dijalankan di CI
+	if len(os.Args) > 1 && os.Args[1] == "openapi-check" {
+		if err := runOpenAPICheck(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Subcommand migrate: jalankan migrasi lalu keluar tanpa menyalakan server. Hanya konfigurasi
+	// database yang dimuat sehingga CI atau init container tidak butuh secret JWT.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		dbConfig, err := config.LoadDB()
+		if err != nil {
+			log.Fatal("Failed to load configuration: ", err)
+		}
+		if err := database.InitDB(dbConfig); err != nil {
+			log.Fatal("Failed to connect to database:", err)
+		}
+		err = runMigrate(database.DB, os.Args[2:])
+		database.CloseDB()
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	}
+
+	// Load dan validasi konfigurasi, server menolak start jika tidak valid
+	cfg, err := config.Load()
 	if err != nil {
-		log.Printf("Warning: .env file not found or error loading, using system environment variables")
+		log.Fatal("Failed to load configuration: ", err)
 	}
 
 	// Setup database connection
-	dbConnString := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_SSLMODE"),
-	)
-
-	err = database.InitDB(dbConnString)
+	err = database.InitDB(cfg.DB)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
-	defer database.CloseDB()
-
-	// Inisialisasi Fiber
-	app := fiber.New()
-
-	// Middleware CORS
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "*",
-		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "*",
-		AllowCredentials: false,
-	}))
-
-	// Middleware Logger
-	app.Use(logger.New())
-
-	// Serve static files (Fiber way)
-	app.Static("/uploads", "./uploads")
-
-	// Initialize handlers
-	userHandler := handlers.NewUserHandler(database.DB)
-	authHandler := handlers.NewAuthHandler(database.DB)
-	carouselHandler := handlers.NewCarouselHandler(database.DB)
-	productHandler := handlers.NewProductHandler(database.DB)
-	portfolioImagesHandler := handlers.NewPortfolioHandler(database.DB)
-	portfolioReviewsHandler := handlers.NewPortfolioHandler(database.DB)
-	messagesHandler := handlers.NewMessagesHandler(database.DB)
-
-	// Routes
-	app.Post("/register", userHandler.RegisterUser)
-	app.Post("/login", authHandler.Login)
-
-	// Protected routes
-	protected := app.Group("", middleware.AuthMiddleware)
-	{
-		// Users
-		protected.Post("/logout", authHandler.Logout)
-		protected.Get("/users", userHandler.GetUsers)
-		protected.Get("/users/:id", userHandler.GetUserByID)
-		protected.Post("/users", userHandler.CreateUser)
-		protected.Put("/users/:id", userHandler.UpdateUser)
-		protected.Delete("/users/:id", userHandler.DeleteUser)
-
-		// Carousels
-		protected.Post("/carousel", carouselHandler.CreateCarousel)
-		protected.Put("/carousel/:id", carouselHandler.UpdateCarousel)
-		protected.Delete("/carousel/:id", carouselHandler.DeleteCarousel)
-		protected.Get("/carousel", carouselHandler.GetCarousels)
-		protected.Get("/carousel/:id", carouselHandler.GetCarouselByID)
-
-		// Products
-		protected.Post("/products", productHandler.CreateProduct)
-		protected.Put("/products/:id", productHandler.UpdateProduct)
-		protected.Delete("/products/:id", productHandler.DeleteProduct)
-		protected.Get("/products", productHandler.GetProducts)
-		protected.Get("/products/:id", productHandler.GetProductByID)
-
-		// Portfolio Images
-		protected.Post("/portfolio/images", portfolioImagesHandler.CreatePortfolioImage)
-		protected.Put("/portfolio/images/:id", portfolioImagesHandler.UpdatePortfolioImage)
-		protected.Delete("/portfolio/images/:id", portfolioImagesHandler.DeletePortfolioImage)
-		protected.Get("/portfolio/images", portfolioImagesHandler.GetPortfolioImages)
-		protected.Get("/portfolio/images/:id", portfolioImagesHandler.GetPortfolioImageByID)
-
-		// Portfolio Reviews
-		protected.Post("/portfolio/reviews", portfolioReviewsHandler.CreatePortfolioReview)
-		protected.Put("/portfolio/reviews/:id", portfolioReviewsHandler.UpdatePortfolioReview)
-		protected.Delete("/portfolio/reviews/:id", portfolioReviewsHandler.DeletePortfolioReview)
-		protected.Get("/portfolio/reviews", portfolioReviewsHandler.GetPortfolioReviews)
-		protected.Get("/portfolio/reviews/:id", portfolioReviewsHandler.GetPortfolioReviewByID)
-
-		// Messages
-		protected.Post("/messages", messagesHandler.CreateMessage)
-		protected.Put("/messages/:id", messagesHandler.UpdateMessage)
-		protected.Delete("/messages/:id", messagesHandler.DeleteMessage)
-		protected.Get("/messages", messagesHandler.GetMessages)
-		protected.Get("/messages/:id", messagesHandler.GetMessageByID)
-	}
+
+	// Initialize repositories
+	repos := repository.NewPostgres(database.DB)
+
+	// Goroutine latar belakang (hapus file lama) ditunggu saat shutdown
+	tasks := background.New()
+
+	app, healthHandler := newApp(cfg, database.DB, repos, tasks)
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000" // Default port
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Pencabutan token kedaluwarsa, catatan gagal login lama dan state SSO yang tidak
+	// diselesaikan dihapus berkala sampai shutdown
+	tasks.Go(func() {
+		background.Every(ctx, cfg.JWT.PurgeInterval, func(ctx context.Context) {
+			purgeRevokedTokens(ctx, repos.Tokens)
+			purgeLoginFailures(ctx, repos.LoginAttempts, cfg.Auth.LoginLockout)
+			purgeOIDCStates(ctx, repos.OIDC)
+		})
+	})
+
+	listenErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server running on port %s", cfg.Server.Port)
+		listenErr <- app.Listen(":" + cfg.Server.Port)
+	}()
+
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			log.Printf("Server stopped: %v", err)
+		}
+	case <-ctx.Done():
+		log.Printf("Shutdown signal received, draining requests (timeout %s)", cfg.Server.ShutdownTimeout)
+
+		// Readiness gagal lebih dulu supaya load balancer sempat mengalihkan traffic
+		healthHandler.SetShuttingDown()
+		time.Sleep(cfg.Server.ShutdownGracePeriod)
+	}
+	stop()
+
+	shutdown(app, tasks, cfg.Server.ShutdownTimeout)
+}
+
+// purgeRevokedTokens menghapus pencabutan token yang masa berlakunya sudah lewat
+func purgeRevokedTokens(ctx context.Context, tokens repository.TokenRepository) {
+	n, err := tokens.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to purge revoked tokens: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d expired revoked tokens", n)
+	}
+}
+
+// purgeLoginFailures menghapus hitungan gagal login yang sudah tidak dihitung lagi dan kunci yang sudah lewat
+func purgeLoginFailures(ctx context.Context, attempts repository.LoginAttemptRepository, lockout time.Duration) {
+	n, err := attempts.PurgeStale(ctx, time.Now().Add(-lockout))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to purge login failures: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d stale login failure records", n)
+	}
+}
+
+// purgeOIDCStates menghapus login SSO yang tidak diselesaikan sebelum kedaluwarsa
+func purgeOIDCStates(ctx context.Context, oidc repository.OIDCRepository) {
+	n, err := oidc.PurgeStates(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to purge single sign-on states: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d expired single sign-on states", n)
+	}
+}
+
+// shutdown berhenti menerima koneksi, menunggu request dan task latar belakang, lalu menutup pool database
+func shutdown(app *fiber.App, tasks *background.Group, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.Printf("Failed to drain HTTP requests: %v", err)
+	}
+
+	if err := tasks.Wait(ctx); err != nil {
+		log.Printf("Background tasks did not finish before deadline: %v", err)
 	}
 
-	log.Printf("Server running on port %s", port)
-	log.Fatal(app.Listen(":" + port))
+	database.CloseDB()
+	log.Println("Server stopped gracefully")
 }
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/oidc/oidcmock"
+	"backend-go/internal/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newOIDCTestApp aplikasi dengan SSO ke provider tiruan yang berisi users
+func newOIDCTestApp(t *testing.T, users []oidcmock.User, configure func(cfg *config.Config)) *testApp {
+	t.Helper()
+	mock, err := oidcmock.New(oidcmock.Config{ClientID: "backend", ClientSecret: "secret", Users: users})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+
+	return newTestApp(t, func(cfg *config.Config) {
+		cfg.OIDC = config.OIDCConfig{
+			Issuer:        srv.URL,
+			ClientID:      "backend",
+			ClientSecret:  "secret",
+			RedirectURL:   "http://app.test/sso/callback",
+			Scopes:        []string{"openid", "profile", "email"},
+			GroupsClaim:   "groups",
+			UsernameClaim: "preferred_username",
+			RoleMapping:   map[string]models.UserRole{"admins": models.RoleAdmin, "editors": models.RoleStaff},
+			DefaultRole:   models.RoleUser,
+			StateTTL:      time.Minute,
+		}
+		if configure != nil {
+			configure(cfg)
+		}
+	})
+}
+
+// ssoLogin menjalankan alur SSO lengkap sebagai user provider dan mengembalikan response callback
+func (a *testApp) ssoLogin(t *testing.T, username string) response {
+	t.Helper()
+	r := a.json(t, http.MethodPost, "/api/v1/auth/oidc/authorize", nil, "")
+	if r.status != http.StatusOK {
+		t.Fatalf("authorize = %d %v", r.status, r.body)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(r.string("authorization_url") + "&login_hint=" + url.QueryEscape(username))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || location.Query().Get("code") == "" {
+		t.Fatalf("provider redirect = %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	return a.json(t, http.MethodPost, "/api/v1/auth/oidc/callback", models.OIDCCallbackRequest{
+		Code:  location.Query().Get("code"),
+		State: r.string("state"),
+	}, "")
+}
+
+func TestOIDCProvisioning(t *testing.T) {
+	a := newOIDCTestApp(t, []oidcmock.User{
+		{Username: "alice", Name: "Alice Admin", Phone: "+6281111111111", Groups: []string{"editors", "admins"}},
+		{Username: "bob", Name: "Bob", Groups: []string{"editors"}},
+		{Username: "carol.smith", Name: "Carol"},
+	}, nil)
+
+	tests := []struct {
+		username string
+		local    string
+		role     models.UserRole
+		phone    string
+	}{
+		{username: "alice", local: "alice", role: models.RoleAdmin, phone: "+6281111111111"},
+		{username: "bob", local: "bob", role: models.RoleStaff},
+		{username: "carol.smith", local: "carolsmith", role: models.RoleUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			r := a.ssoLogin(t, tt.username)
+			if r.status != http.StatusOK || r.string("token") == "" {
+				t.Fatalf("callback = %d %v", r.status, r.body)
+			}
+			user, err := a.repos.Users.GetByUsername(context.Background(), tt.local)
+			if err != nil {
+				t.Fatalf("user %s not provisioned: %v", tt.local, err)
+			}
+			if user.Role != tt.role || user.Phone != tt.phone || user.Password != "" {
+				t.Errorf("user = role %s phone %q password %q, want role %s phone %q and no password",
+					user.Role, user.Phone, user.Password, tt.role, tt.phone)
+			}
+
+			// Login berikutnya memakai user yang sama
+			again := a.ssoLogin(t, tt.username)
+			got, _ := again.body["user"].(map[string]interface{})
+			if again.status != http.StatusOK || int(got["id"].(float64)) != user.ID {
+				t.Errorf("second login = %d %v, want user %d", again.status, again.body, user.ID)
+			}
+		})
+	}
+}
+
+func TestOIDCRoleMapping(t *testing.T) {
+	users := []oidcmock.User{{Username: "alice", Groups: []string{"admins"}}, {Username: "dave"}}
+
+	t.Run("role follows provider on every login", func(t *testing.T) {
+		a := newOIDCTestApp(t, users, nil)
+		if r := a.ssoLogin(t, "alice"); r.status != http.StatusOK {
+			t.Fatalf("callback = %d %v", r.status, r.body)
+		}
+		user, _ := a.repos.Users.GetByUsername(context.Background(), "alice")
+		if err := a.repos.Users.Update(context.Background(), user.ID, repository.UserUpdate{Role: models.RoleUser, EditedBy: user.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		r := a.ssoLogin(t, "alice")
+		got, _ := r.body["user"].(map[string]interface{})
+		if r.status != http.StatusOK || got["role"] != string(models.RoleAdmin) {
+			t.Fatalf("callback = %d %v, want role admin", r.status, r.body)
+		}
+	})
+
+	t.Run("no mapped group and no default role", func(t *testing.T) {
+		a := newOIDCTestApp(t, users, func(cfg *config.Config) { cfg.OIDC.DefaultRole = "" })
+		if r := a.ssoLogin(t, "dave"); r.status != http.StatusForbidden {
+			t.Fatalf("callback = %d %v, want 403", r.status, r.body)
+		}
+		if _, err := a.repos.Users.GetByUsername(context.Background(), "dave"); err == nil {
+			t.Error("user without a mapped group was provisioned")
+		}
+	})
+
+	t.Run("inactive account", func(t *testing.T) {
+		a := newOIDCTestApp(t, users, nil)
+		if r := a.ssoLogin(t, "alice"); r.status != http.StatusOK {
+			t.Fatalf("callback = %d %v", r.status, r.body)
+		}
+		user, _ := a.repos.Users.GetByUsername(context.Background(), "alice")
+		status := false
+		if err := a.repos.Users.Update(context.Background(), user.ID, repository.UserUpdate{Status: &status, EditedBy: user.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if r := a.ssoLogin(t, "alice"); r.status != http.StatusForbidden {
+			t.Fatalf("callback = %d %v, want 403", r.status, r.body)
+		}
+	})
+}
+
+func TestOIDCLocalTOTP(t *testing.T) {
+	users := []oidcmock.User{
+		{Username: "bob", Groups: []string{"editors"}},
+		{Username: "carol", Groups: []string{"editors"}, AMR: []string{"pwd", "mfa"}},
+	}
+	a := newOIDCTestApp(t, users, func(cfg *config.Config) {
+		cfg.Auth.TOTPRequiredRoles = []models.UserRole{models.RoleStaff}
+		cfg.Auth.MFAChallengeTTL = time.Minute
+	})
+
+	t.Run("provider without mfa", func(t *testing.T) {
+		if r := a.ssoLogin(t, "bob"); r.status != http.StatusOK {
+			t.Fatalf("first callback = %d %v", r.status, r.body)
+		}
+		user, _ := a.repos.Users.GetByUsername(context.Background(), "bob")
+		secret := a.enrollTOTP(t, user.ID)
+
+		r := a.ssoLogin(t, "bob")
+		if r.status != http.StatusAccepted || r.string("challenge_token") == "" {
+			t.Fatalf("callback = %d %v, want 202 with a challenge", r.status, r.body)
+		}
+		r = a.json(t, http.MethodPost, "/api/v1/auth/2fa/verify", models.MFAVerifyRequest{
+			ChallengeToken: r.string("challenge_token"),
+			Code:           totpCode(t, secret),
+		}, "")
+		if r.status != http.StatusOK {
+			t.Fatalf("verify = %d %v", r.status, r.body)
+		}
+		// Role yang wajib 2FA tidak lagi dibatasi pada route enrollment
+		if got := a.json(t, http.MethodGet, "/api/v1/products", nil, r.string("token")); got.status != http.StatusOK {
+			t.Errorf("GET /products = %d %v, want 200", got.status, got.body)
+		}
+	})
+
+	t.Run("provider reports mfa", func(t *testing.T) {
+		if r := a.ssoLogin(t, "carol"); r.status != http.StatusOK {
+			t.Fatalf("first callback = %d %v", r.status, r.body)
+		}
+		user, _ := a.repos.Users.GetByUsername(context.Background(), "carol")
+		a.enrollTOTP(t, user.ID)
+
+		r := a.ssoLogin(t, "carol")
+		if r.status != http.StatusOK {
+			t.Fatalf("callback = %d %v, want 200 without a local challenge", r.status, r.body)
+		}
+		if got := a.json(t, http.MethodGet, "/api/v1/products", nil, r.string("token")); got.status != http.StatusOK {
+			t.Errorf("GET /products = %d %v, want 200", got.status, got.body)
+		}
+	})
+}
+
+func TestOIDCLinkExisting(t *testing.T) {
+	verified := []string{"preferred_username"}
+	tests := []struct {
+		name     string
+		local    string
+		role     models.UserRole
+		provider oidcmock.User
+		link     bool
+		want     int
+	}{
+		{name: "exact verified match", local: "alice", role: models.RoleStaff,
+			provider: oidcmock.User{Username: "alice", Verified: verified}, link: true, want: http.StatusOK},
+		{name: "linking disabled", local: "alice", role: models.RoleStaff,
+			provider: oidcmock.User{Username: "alice", Verified: verified}, want: http.StatusConflict},
+		{name: "normalized match", local: "admin", role: models.RoleStaff,
+			provider: oidcmock.User{Username: "ad.min", Verified: verified}, link: true, want: http.StatusConflict},
+		{name: "email style match", local: "bob", role: models.RoleUser,
+			provider: oidcmock.User{Username: "bob@other.test", Verified: verified}, link: true, want: http.StatusConflict},
+		{name: "unverified claim", local: "alice", role: models.RoleStaff,
+			provider: oidcmock.User{Username: "alice", Verified: []string{"email"}}, link: true, want: http.StatusConflict},
+		{name: "administrator", local: "root", role: models.RoleAdmin,
+			provider: oidcmock.User{Username: "root", Verified: verified}, link: true, want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newOIDCTestApp(t, []oidcmock.User{tt.provider}, func(cfg *config.Config) {
+				cfg.OIDC.LinkExisting = tt.link
+				cfg.OIDC.RoleMapping = nil
+			})
+			local := a.createUser(t, tt.local, "Secret123", tt.role)
+
+			r := a.ssoLogin(t, tt.provider.Username)
+			if r.status != tt.want {
+				t.Fatalf("callback = %d %v, want %d", r.status, r.body, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			got, _ := r.body["user"].(map[string]interface{})
+			if int(got["id"].(float64)) != local.ID || got["role"] != string(tt.role) {
+				t.Errorf("user = %v, want local user %d with role %s", got, local.ID, tt.role)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackErrors(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		a := newTestApp(t, nil)
+		if r := a.json(t, http.MethodPost, "/api/v1/auth/oidc/authorize", nil, ""); r.status != http.StatusNotFound {
+			t.Fatalf("authorize = %d %v, want 404", r.status, r.body)
+		}
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		a := newOIDCTestApp(t, nil, nil)
+		r := a.json(t, http.MethodPost, "/api/v1/auth/oidc/callback", models.OIDCCallbackRequest{Code: "code", State: "state"}, "")
+		if r.status != http.StatusBadRequest {
+			t.Fatalf("callback = %d %v, want 400", r.status, r.body)
+		}
+	})
+
+	t.Run("state used twice", func(t *testing.T) {
+		a := newOIDCTestApp(t, nil, nil)
+		r := a.json(t, http.MethodPost, "/api/v1/auth/oidc/authorize", nil, "")
+		req := models.OIDCCallbackRequest{Code: "code", State: r.string("state")}
+		if r := a.json(t, http.MethodPost, "/api/v1/auth/oidc/callback", req, ""); r.status != http.StatusUnauthorized {
+			t.Fatalf("callback with bad code = %d %v, want 401", r.status, r.body)
+		}
+		if r := a.json(t, http.MethodPost, "/api/v1/auth/oidc/callback", req, ""); r.status != http.StatusBadRequest {
+			t.Fatalf("second callback = %d %v, want 400", r.status, r.body)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/password"
+	"context"
+	"net/http"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// withPasswordPolicy memasang kebijakan password lengkap beserta blocklist per deployment
+func withPasswordPolicy(cfg *config.Config) {
+	cfg.Auth.PublicRegistration = true
+	cfg.Auth.Password = password.Policy{
+		MinLength:        10,
+		RequireUpper:     true,
+		RequireLower:     true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		DisallowPersonal: true,
+		CheckCommon:      true,
+		Blocklist:        map[string]struct{}{"acmecorp#2024": {}},
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	a := newTestApp(t, withPasswordPolicy)
+	alice := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token := a.login(t, "alice", "Secret123")
+
+	tests := []struct {
+		name     string
+		password string
+		message  string
+	}{
+		{name: "too short", password: "Ab1!xyz", message: "new_password must be at least 10 characters"},
+		{name: "no uppercase", password: "abcdefgh1!", message: "new_password must contain at least one uppercase letter"},
+		{name: "no lowercase", password: "ABCDEFGH1!", message: "new_password must contain at least one lowercase letter"},
+		{name: "no digit", password: "Abcdefghi!", message: "new_password must contain at least one digit"},
+		{name: "no symbol", password: "Abcdefghi1", message: "new_password must contain at least one symbol"},
+		{name: "username", password: "xAlice#2024", message: "new_password must not contain your username or phone number"},
+		{name: "phone number", password: "Tel#" + alice.Phone[len(alice.Phone)-8:], message: "new_password must not contain your username or phone number"},
+		{name: "bundled list", password: "P@ssword123", message: "new_password is too common, choose one that is harder to guess"},
+		{name: "deployment blocklist", password: "AcmeCorp#2024", message: "new_password is too common, choose one that is harder to guess"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.ChangePasswordRequest{CurrentPassword: "Secret123", NewPassword: tt.password}
+			r := a.json(t, http.MethodPost, "/api/v1/me/password", req, token)
+			details, _ := r.body["details"].([]interface{})
+			if r.status != http.StatusBadRequest || len(details) != 1 {
+				t.Fatalf("change to %q = %d %v, want one validation error", tt.password, r.status, r.body)
+			}
+			if got := details[0].(map[string]interface{})["message"]; got != tt.message {
+				t.Errorf("message = %q, want %q", got, tt.message)
+			}
+		})
+	}
+
+	t.Run("register", func(t *testing.T) {
+		// Data pribadi diambil dari request karena akunnya belum ada
+		req := models.RegisterRequest{Name: "Bob Builder", Phone: "+6281377778888", Username: "bobbuilder", Password: "Bobbuilder#1"}
+		if r := a.json(t, http.MethodPost, "/api/v1/register", req, ""); r.status != http.StatusBadRequest {
+			t.Errorf("register with the username in the password = %d %v, want 400", r.status, r.body)
+		}
+		req.Password = "Correct#Horse7"
+		if r := a.json(t, http.MethodPost, "/api/v1/register", req, ""); r.status != http.StatusCreated {
+			t.Errorf("register = %d %v, want 201", r.status, r.body)
+		}
+	})
+
+	req := models.ChangePasswordRequest{CurrentPassword: "Secret123", NewPassword: "Correct#Horse7"}
+	if r := a.json(t, http.MethodPost, "/api/v1/me/password", req, token); r.status != http.StatusOK {
+		t.Fatalf("change to a valid password = %d %v", r.status, r.body)
+	}
+	a.login(t, "alice", "Correct#Horse7")
+}
+
+func TestRehashOnLogin(t *testing.T) {
+	cost := bcrypt.MinCost + 1
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.BcryptCost = cost })
+	// createUser menyimpan hash dengan bcrypt.MinCost, lebih rendah dari cost yang dikonfigurasi
+	alice := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	hashCost := func() int {
+		t.Helper()
+		user, err := a.repos.Users.GetByID(context.Background(), alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := bcrypt.Cost([]byte(user.Password))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if r := a.tryLogin(t, "alice", "wrong"); r.status != http.StatusUnauthorized {
+		t.Fatalf("failed login = %d %v", r.status, r.body)
+	}
+	if got := hashCost(); got != bcrypt.MinCost {
+		t.Fatalf("cost after failed login = %d, want %d", got, bcrypt.MinCost)
+	}
+
+	a.login(t, "alice", "Secret123")
+	if got := hashCost(); got != cost {
+		t.Fatalf("cost after login = %d, want %d", got, cost)
+	}
+	// Rehash bukan perubahan oleh user: edited_at dan edited_by tidak tersentuh
+	if user, err := a.repos.Users.GetByID(context.Background(), alice.ID); err != nil {
+		t.Fatal(err)
+	} else if user.EditedAt != nil || user.EditedBy != nil {
+		t.Errorf("edited_at = %v, edited_by = %v after rehash, want both unset", user.EditedAt, user.EditedBy)
+	}
+	a.login(t, "alice", "Secret123")
+
+	// Hash dengan cost lebih tinggi dari konfigurasi tidak diturunkan
+	if password.NeedsRehash(mustHash(t, "Secret123", cost), bcrypt.MinCost) {
+		t.Error("NeedsRehash() = true for a hash above the configured cost")
+	}
+}
+
+func mustHash(t *testing.T, plain string, cost int) string {
+	t.Helper()
+	hash, err := password.Hash(plain, cost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
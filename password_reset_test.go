@@ -0,0 +1,130 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/notify"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// resetCode kode reset di isi pesan yang dikirim /auth/forgot
+var resetCode = regexp.MustCompile(`reset your password: ([A-Za-z0-9_-]+)\.`)
+
+// withNotifyFile mengirim notifikasi ke file di direktori sementara test
+func withNotifyFile(t *testing.T) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Auth.ResetTTL = 30 * time.Minute
+		cfg.Notify = config.NotifyConfig{Driver: notify.DriverFile, File: filepath.Join(t.TempDir(), "notify.jsonl")}
+	}
+}
+
+// sentMessages menunggu pengiriman di latar belakang selesai dan membaca pesan yang terkirim ke to
+func (a *testApp) sentMessages(t *testing.T, to string) []notify.Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.tasks.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(a.cfg.Notify.File)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var messages []notify.Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg notify.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.To == to {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func (a *testApp) resetPassword(t *testing.T, token, password string) response {
+	t.Helper()
+	return a.json(t, http.MethodPost, "/api/v1/auth/reset", models.ResetPasswordRequest{Token: token, Password: password}, "")
+}
+
+func TestPasswordReset(t *testing.T) {
+	a := newTestApp(t, withNotifyFile(t))
+	user := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token, refresh := a.loginTokens(t, "alice", "Secret123")
+
+	forgot := func(username string) {
+		t.Helper()
+		r := a.json(t, http.MethodPost, "/api/v1/auth/forgot", models.ForgotPasswordRequest{Username: username}, "")
+		if r.status != http.StatusAccepted {
+			t.Fatalf("forgot %s = %d %v, want 202", username, r.status, r.body)
+		}
+	}
+	forgot("nobody")
+	forgot("alice")
+
+	messages := a.sentMessages(t, user.Phone)
+	if len(messages) != 1 {
+		t.Fatalf("messages to %s = %v, want one", user.Phone, messages)
+	}
+	match := resetCode.FindStringSubmatch(messages[0].Body)
+	if match == nil {
+		t.Fatalf("message = %q, want a reset code", messages[0].Body)
+	}
+	code := match[1]
+
+	if r := a.resetPassword(t, code, "NewSecret456"); r.status != http.StatusOK {
+		t.Fatalf("reset = %d %v", r.status, r.body)
+	}
+
+	// Semua session lama dicabut
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusUnauthorized {
+		t.Errorf("access token after reset = %d %v, want 401", r.status, r.body)
+	}
+	if r := a.refresh(t, refresh); r.status != http.StatusUnauthorized {
+		t.Errorf("refresh after reset = %d %v, want 401", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: "alice", Password: "Secret123"}, ""); r.status != http.StatusUnauthorized {
+		t.Errorf("login with old password = %d %v, want 401", r.status, r.body)
+	}
+	a.login(t, "alice", "NewSecret456")
+
+	// Token hanya dapat dipakai sekali
+	if r := a.resetPassword(t, code, "Another789x"); r.status != http.StatusBadRequest {
+		t.Errorf("reused token = %d %v, want 400", r.status, r.body)
+	}
+	a.login(t, "alice", "NewSecret456")
+}
+
+func TestPasswordResetTokenRejected(t *testing.T) {
+	a := newTestApp(t, withNotifyFile(t))
+	user := a.createUser(t, "alice", "Secret123", models.RoleUser)
+
+	sum := sha256.Sum256([]byte("expired-token"))
+	if err := a.repos.PasswordResets.Create(context.Background(), user.ID, hex.EncodeToString(sum[:]), time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{"expired-token", "unknown-token"} {
+		if r := a.resetPassword(t, token, "NewSecret456"); r.status != http.StatusBadRequest {
+			t.Errorf("reset with %s = %d %v, want 400", token, r.status, r.body)
+		}
+	}
+	a.login(t, "alice", "Secret123")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// fakePostgres server minimal yang cukup untuk koneksi pgx dan Ping ("-- ping"), agar readiness
+// bisa diuji tanpa database sungguhan
+type fakePostgres struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func startFakePostgres(t *testing.T) *fakePostgres {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg := &fakePostgres{listener: listener}
+	go pg.serve()
+	t.Cleanup(pg.stop)
+	return pg
+}
+
+func (pg *fakePostgres) serve() {
+	for {
+		conn, err := pg.listener.Accept()
+		if err != nil {
+			return
+		}
+		pg.mu.Lock()
+		pg.conns = append(pg.conns, conn)
+		pg.mu.Unlock()
+		go handleFakeConn(conn)
+	}
+}
+
+func handleFakeConn(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg.(type) {
+		case *pgproto3.Query:
+			backend.Send(&pgproto3.EmptyQueryResponse{})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+// stop menutup listener dan semua koneksi, seperti database yang mati
+func (pg *fakePostgres) stop() {
+	pg.listener.Close()
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	for _, conn := range pg.conns {
+		conn.Close()
+	}
+	pg.conns = nil
+}
+
+func (pg *fakePostgres) pool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	addr := pg.listener.Addr().(*net.TCPAddr)
+	pool, err := pgxpool.New(context.Background(), fmt.Sprintf("postgres://app@127.0.0.1:%d/compro?sslmode=disable&connect_timeout=1", addr.Port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestReadiness(t *testing.T) {
+	pg := startFakePostgres(t)
+	a := newTestApp(t, nil)
+	app, health := newApp(a.cfg, pg.pool(t), a.repos, a.tasks)
+	a.app = app
+
+	checks := func(r response) map[string]interface{} {
+		checks, _ := r.body["checks"].(map[string]interface{})
+		return checks
+	}
+	status := func(r response, check string) string {
+		c, _ := checks(r)[check].(map[string]interface{})
+		s, _ := c["status"].(string)
+		return s
+	}
+
+	r := a.json(t, http.MethodGet, "/readyz", nil, "")
+	if r.status != http.StatusOK || r.string("status") != "ready" {
+		t.Fatalf("readyz = %d %v, want 200 ready", r.status, r.body)
+	}
+	if status(r, "database") != "ok" || status(r, "uploads") != "ok" {
+		t.Errorf("checks = %v, want database and uploads ok", checks(r))
+	}
+	if _, ok := checks(r)["shutdown"]; ok {
+		t.Errorf("checks = %v, want no shutdown check before shutdown", checks(r))
+	}
+
+	t.Run("shutting down", func(t *testing.T) {
+		health.SetShuttingDown()
+		r := a.json(t, http.MethodGet, "/readyz", nil, "")
+		if r.status != http.StatusServiceUnavailable || r.string("status") != "not_ready" {
+			t.Fatalf("readyz = %d %v, want 503 not_ready", r.status, r.body)
+		}
+		// Database masih sehat; hanya shutdown yang membuat aplikasi tidak siap
+		if status(r, "shutdown") != "error" || status(r, "database") != "ok" {
+			t.Errorf("checks = %v, want shutdown error and database ok", checks(r))
+		}
+	})
+
+	t.Run("database unreachable", func(t *testing.T) {
+		// Aplikasi baru tanpa status shutdown di atas database yang sudah mati
+		app, _ := newApp(a.cfg, pg.pool(t), a.repos, a.tasks)
+		b := &testApp{app: app, repos: a.repos, cfg: a.cfg, tasks: a.tasks}
+		pg.stop()
+
+		r := b.json(t, http.MethodGet, "/readyz", nil, "")
+		if r.status != http.StatusServiceUnavailable || r.string("status") != "not_ready" {
+			t.Fatalf("readyz = %d %v, want 503 not_ready", r.status, r.body)
+		}
+		if status(r, "database") != "error" || status(r, "uploads") != "ok" {
+			t.Errorf("checks = %v, want database error and uploads ok", checks(r))
+		}
+		if _, ok := checks(r)["shutdown"]; ok {
+			t.Errorf("checks = %v, want no shutdown check", checks(r))
+		}
+
+		// Liveness tidak bergantung pada database
+		if r := b.json(t, http.MethodGet, "/healthz", nil, ""); r.status != http.StatusOK {
+			t.Errorf("healthz = %d %v, want 200", r.status, r.body)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"backend-go/internal/models"
+	"net/http"
+	"testing"
+)
+
+// loginTokens login dengan password dan mengembalikan access token beserta refresh token
+func (a *testApp) loginTokens(t *testing.T, username, password string) (token, refresh string) {
+	t.Helper()
+	r := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: username, Password: password}, "")
+	if r.status != http.StatusOK || r.string("token") == "" || r.string("refresh_token") == "" {
+		t.Fatalf("login %s: %d %v", username, r.status, r.body)
+	}
+	return r.string("token"), r.string("refresh_token")
+}
+
+func (a *testApp) refresh(t *testing.T, refreshToken string) response {
+	t.Helper()
+	return a.json(t, http.MethodPost, "/api/v1/auth/refresh", models.RefreshRequest{RefreshToken: refreshToken}, "")
+}
+
+func TestRefreshRotation(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token, first := a.loginTokens(t, "alice", "Secret123")
+
+	r := a.refresh(t, first)
+	second := r.string("refresh_token")
+	if r.status != http.StatusOK || r.string("token") == "" || second == "" || second == first {
+		t.Fatalf("refresh = %d %v, want a new token pair", r.status, r.body)
+	}
+	if me := a.json(t, http.MethodGet, "/api/v1/me", nil, r.string("token")); me.status != http.StatusOK {
+		t.Errorf("new access token = %d %v, want 200", me.status, me.body)
+	}
+	// Rotasi tidak mengakhiri session, sehingga access token lama tetap berlaku sampai kedaluwarsa
+	if me := a.json(t, http.MethodGet, "/api/v1/me", nil, token); me.status != http.StatusOK {
+		t.Errorf("previous access token = %d %v, want 200", me.status, me.body)
+	}
+
+	r = a.refresh(t, second)
+	if r.status != http.StatusOK || r.string("refresh_token") == second {
+		t.Fatalf("second refresh = %d %v", r.status, r.body)
+	}
+	if r := a.refresh(t, "not-a-refresh-token"); r.status != http.StatusUnauthorized {
+		t.Errorf("unknown refresh token = %d %v, want 401", r.status, r.body)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+	_, first := a.loginTokens(t, "alice", "Secret123")
+	otherToken, otherRefresh := a.loginTokens(t, "alice", "Secret123")
+
+	r := a.refresh(t, first)
+	if r.status != http.StatusOK {
+		t.Fatalf("refresh = %d %v", r.status, r.body)
+	}
+	token, latest := r.string("token"), r.string("refresh_token")
+
+	// Refresh token yang sudah dirotasi dipakai lagi: seluruh session dicabut
+	if r := a.refresh(t, first); r.status != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token = %d %v, want 401", r.status, r.body)
+	}
+	if r := a.refresh(t, latest); r.status != http.StatusUnauthorized {
+		t.Errorf("latest refresh token after reuse = %d %v, want 401", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusUnauthorized {
+		t.Errorf("access token after reuse = %d %v, want 401", r.status, r.body)
+	}
+
+	// Session lain milik user yang sama tidak terpengaruh
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, otherToken); r.status != http.StatusOK {
+		t.Errorf("other session = %d %v, want 200", r.status, r.body)
+	}
+	if r := a.refresh(t, otherRefresh); r.status != http.StatusOK {
+		t.Errorf("other session refresh = %d %v, want 200", r.status, r.body)
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token, refresh := a.loginTokens(t, "alice", "Secret123")
+
+	if r := a.json(t, http.MethodPost, "/api/v1/logout", nil, token); r.status != http.StatusOK {
+		t.Fatalf("logout = %d %v", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusUnauthorized {
+		t.Errorf("access token after logout = %d %v, want 401", r.status, r.body)
+	}
+	if r := a.refresh(t, refresh); r.status != http.StatusUnauthorized {
+		t.Errorf("refresh after logout = %d %v, want 401", r.status, r.body)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/jwtkeys"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// withRevocationCache memasang cache pencabutan dengan TTL panjang, sehingga hanya invalidasi
+// eksplisit yang membuat perubahan terlihat
+func withRevocationCache(cfg *config.Config) {
+	cfg.JWT.RevocationCacheSize = 100
+	cfg.JWT.RevocationCacheTTL = time.Hour
+}
+
+// tokenClaims membaca claims access token tanpa memverifikasi tanda tangannya
+func tokenClaims(t *testing.T, token string) *models.Claims {
+	t.Helper()
+	claims := &models.Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestRevokedJTIRejected(t *testing.T) {
+	a := newTestApp(t, withRevocationCache)
+	user := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token := a.login(t, "alice", "Secret123")
+	other := a.login(t, "alice", "Secret123")
+
+	claims := tokenClaims(t, token)
+	if claims.ID == "" || claims.ID == tokenClaims(t, other).ID {
+		t.Fatalf("jti = %q, want a unique jti per token", claims.ID)
+	}
+	// Dicabut sebelum request pertama, sehingga cache belum berisi jti ini
+	if err := a.repos.Tokens.Revoke(context.Background(), claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatal(err)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusUnauthorized {
+		t.Errorf("revoked jti = %d %v, want 401", r.status, r.body)
+	}
+	// Pencabutan per jti tidak mengenai token lain dari user yang sama
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, other); r.status != http.StatusOK {
+		t.Errorf("other token = %d %v, want 200", r.status, r.body)
+	}
+
+	keys, err := jwtkeys.NewSet(jwtkeys.FromSecret(a.cfg.JWT.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := keys.Sign(models.Claims{
+		UserID:           user.ID,
+		Role:             user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, legacy); r.status != http.StatusUnauthorized {
+		t.Errorf("token without jti = %d %v, want 401", r.status, r.body)
+	}
+}
+
+func TestPurgeExpiredRevocations(t *testing.T) {
+	tokens := repository.NewMemory().Tokens
+	ctx := context.Background()
+	now := time.Now()
+	if err := tokens.Revoke(ctx, "expired", now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tokens.Revoke(ctx, "live", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := tokens.PurgeExpired(ctx, now); err != nil || n != 1 {
+		t.Fatalf("PurgeExpired() = %d, %v, want 1", n, err)
+	}
+	if revoked, _ := tokens.IsRevoked(ctx, "live"); !revoked {
+		t.Error("live revocation purged")
+	}
+}
+
+func TestRevocationCacheInvalidation(t *testing.T) {
+	a := newTestApp(t, withRevocationCache)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	adminToken := a.login(t, "admin", "Secret123")
+	ctx := context.Background()
+
+	t.Run("cache is consulted", func(t *testing.T) {
+		user := a.createUser(t, "bob", "Secret123", models.RoleUser)
+		token := a.login(t, "bob", "Secret123")
+		if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusOK {
+			t.Fatalf("me = %d %v", r.status, r.body)
+		}
+		// Perubahan yang tidak melewati repository ber-cache (instance lain) baru terlihat setelah TTL
+		inactive := false
+		if err := a.repos.Users.Update(ctx, user.ID, repository.UserUpdate{Status: &inactive}); err != nil {
+			t.Fatal(err)
+		}
+		if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusOK {
+			t.Errorf("me after uncached update = %d %v, want the cached 200", r.status, r.body)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		user := a.createUser(t, "carol", "Secret123", models.RoleUser)
+		token := a.login(t, "carol", "Secret123")
+		if r := a.json(t, http.MethodGet, "/api/v1/me", nil, token); r.status != http.StatusOK {
+			t.Fatalf("me = %d %v", r.status, r.body)
+		}
+		path := fmt.Sprintf("/api/v1/users/%d", user.ID)
+		if r := a.json(t, http.MethodPost, "/api/v1/products", nil, token); r.status != http.StatusForbidden {
+			t.Fatalf("user creating product = %d %v, want 403", r.status, r.body)
+		}
+		if r := a.json(t, http.MethodPut, path, models.UpdateRequest{Role: models.RoleStaff}, adminToken); r.status != http.StatusOK {
+			t.Fatalf("promote = %d %v", r.status, r.body)
+		}
+		// Role baru berlaku di request berikutnya walaupun state lama masih di cache
+		if r := a.json(t, http.MethodPost, "/api/v1/products", nil, token); r.status == http.StatusForbidden {
+			t.Errorf("staff creating product = %d %v, want past the permission check", r.status, r.body)
+		}
+	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		cached := repository.NewMemory().WithRevocationCache(100, time.Hour)
+		user := &models.User{Name: "erin", Phone: "+6281299999999", Username: "erin", Role: models.RoleUser, Status: true}
+		if err := cached.Users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if state, err := cached.Users.AuthState(ctx, user.ID); err != nil || !state.Active {
+			t.Fatalf("AuthState() = %+v, %v, want active", state, err)
+		}
+		if err := cached.Users.SoftDelete(ctx, user.ID, user.ID, nil); err != nil {
+			t.Fatal(err)
+		}
+		if state, err := cached.Users.AuthState(ctx, user.ID); err == nil && state.Active {
+			t.Errorf("AuthState() after delete = %+v, want inactive", state)
+		}
+	})
+}
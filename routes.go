@@ -0,0 +1,241 @@
+package main
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/docs"
+	"backend-go/internal/handlers"
+	"backend-go/internal/jwtkeys"
+	"backend-go/internal/middleware"
+	"backend-go/internal/notify"
+	"backend-go/internal/repository"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newApp menyusun aplikasi Fiber beserta middleware dan seluruh route.
+// Health handler dikembalikan agar main dapat menandai readiness saat shutdown.
+func newApp(cfg *config.Config, db *pgxpool.Pool, repos *repository.Repositories, tasks *background.Group) (*fiber.App, *handlers.HealthHandler) {
+	// Inisialisasi Fiber
+	// IP client dari ProxyHeader hanya dipercaya bila koneksi datang dari TrustedProxies
+	app := fiber.New(fiber.Config{
+		BodyLimit:               cfg.Server.BodyLimit,
+		ErrorHandler:            apperror.Handler,
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.Server.ProxyHeader != "",
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+	})
+
+	// Request ID dipakai di envelope error dan log, recover mengubah panic menjadi error 500
+	app.Use(requestid.New())
+	app.Use(recover.New())
+
+	// Middleware CORS
+	app.Use(cors.New(cors.Config{
+		AllowOrigins:     strings.Join(cfg.CORS.AllowOrigins, ","),
+		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
+		AllowHeaders:     "*",
+		ExposeHeaders:    "Deprecation,Sunset,Link,Retry-After,X-Impersonated-By",
+		AllowCredentials: false,
+	}))
+
+	// Health check didaftarkan sebelum logger dan tanpa auth agar probe load balancer tidak membanjiri log
+	healthHandler := handlers.NewHealthHandler(db, cfg.Upload)
+	app.Get("/healthz", healthHandler.Liveness)
+	app.Get("/readyz", healthHandler.Readiness)
+
+	// Middleware Logger
+	app.Use(logger.New(logger.Config{
+		Format: "${time} | ${locals:requestid} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
+	}))
+
+	// Dokumen OpenAPI dan halaman dokumentasi, tanpa auth
+	docs.Register(app)
+
+	// Kunci JWT; config kosong (mis. openapi-check) memakai HS256 dengan secret apa adanya
+	jwtConfig := cfg.JWT
+	if jwtConfig.Keys == nil {
+		jwtConfig.Keys, _ = jwtkeys.NewSet(jwtkeys.FromSecret(jwtConfig.Secret))
+	}
+
+	// Kunci publik untuk layanan lain yang memverifikasi access token, tanpa auth
+	app.Get("/.well-known/jwks.json", handlers.NewJWKSHandler(jwtConfig.Keys).GetJWKS)
+
+	// Context request dengan deadline default; grup di ROUTE_TIMEOUTS dan route upload
+	// memakai deadline sendiri
+	app.Use(middleware.Timeout(cfg.Server.RequestTimeout))
+	uploadTimeout := middleware.Timeout(cfg.Server.UploadTimeout)
+
+	// Serve static files (Fiber way)
+	app.Static("/uploads", cfg.Upload.Root)
+
+	// Status pencabutan token dan session di-cache agar auth tidak ke database di setiap request
+	repos = repos.WithRevocationCache(cfg.JWT.RevocationCacheSize, cfg.JWT.RevocationCacheTTL)
+
+	// Matriks permission per deployment; config kosong (mis. openapi-check) memakai matriks bawaan
+	policy := cfg.Authz.Policy
+	if policy == nil {
+		policy = authz.Default()
+	}
+
+	// Initialize handlers
+	h := apiHandlers{
+		users:     handlers.NewUserHandler(repos.Users, repos.Invites, repos.Sessions, repos.APIKeys, policy, cfg.Auth),
+		auth:      handlers.NewAuthHandler(repos.Users, repos.Tokens, repos.Sessions, repos.LoginAttempts, repos.TOTP, jwtConfig, cfg.Auth),
+		carousel:  handlers.NewCarouselHandler(repos.Carousels, cfg.Upload, tasks),
+		products:  handlers.NewProductHandler(repos.Products, cfg.Upload, tasks),
+		portfolio: handlers.NewPortfolioHandler(repos.PortfolioImages, repos.PortfolioReviews, repos.Products, cfg.Upload, tasks),
+		messages:  handlers.NewMessagesHandler(repos.Messages, repos.Products),
+		invites:   handlers.NewInviteHandler(repos.Invites, policy, cfg.Auth),
+		passwords: handlers.NewPasswordHandler(repos.Users, repos.PasswordResets, repos.Sessions, notify.New(cfg.Notify), tasks, cfg.Auth),
+		lockouts:  handlers.NewLockoutHandler(repos.Users, repos.LoginAttempts, policy),
+		apiKeys:   handlers.NewAPIKeyHandler(repos.APIKeys, policy),
+		sessions:  handlers.NewSessionHandler(repos.Users, repos.Sessions, policy),
+		profile:   handlers.NewProfileHandler(repos.Users, repos.Sessions, cfg.Auth),
+	}
+	h.totp = handlers.NewTOTPHandler(repos.Users, repos.TOTP, repos.Sessions, h.auth, policy, cfg.Auth)
+	h.oidc = handlers.NewOIDCHandler(repos.OIDC, repos.Users, h.auth, cfg.OIDC)
+	h.impersonation = handlers.NewImpersonationHandler(repos.Users, repos.Sessions, repos.Audit, h.auth, policy, cfg.Auth)
+	auth := middleware.NewAuthMiddleware(jwtConfig, repos.Users, repos.Tokens, repos.Sessions, repos.APIKeys, repos.Audit)
+	mfa := middleware.RequireTOTP(cfg.Auth.TOTPRequiredRoles)
+
+	// Route API diberi prefix versi. Versi baru dipasang berdampingan sebagai routeSet sendiri,
+	// misalnya v2Routes(h).mount(api.Group("/v2"), auth, mfa, policy)
+	api := app.Group("/api")
+	// Throttling per IP untuk endpoint auth publik, dipakai bersama oleh route v1 dan legacy
+	authLimit := middleware.RateLimit(cfg.Auth.LoginRateLimit, cfg.Auth.LoginRateWindow)
+
+	v1, unknown := v1Routes(h, uploadTimeout, authLimit).withTimeouts(cfg.Server.RouteTimeouts)
+	for _, group := range unknown {
+		log.Printf("ROUTE_TIMEOUTS: no routes in group %q, timeout ignored", group)
+	}
+	v1.mount(api.Group("/v1"), auth, mfa, policy)
+
+	// Path lama tanpa prefix tetap dilayani selama masa transisi, dengan header Deprecation/Sunset
+	if cfg.API.LegacyRoutes {
+		v1.mount(app, auth, mfa, policy, middleware.Deprecation(cfg.API, "/api/v1"))
+	}
+
+	return app, healthHandler
+}
+
+// apiHandlers kumpulan handler yang dipakai routeSet
+type apiHandlers struct {
+	users         *handlers.UserHandler
+	auth          *handlers.AuthHandler
+	carousel      *handlers.CarouselHandler
+	products      *handlers.ProductHandler
+	portfolio     *handlers.PortfolioHandler
+	messages      *handlers.MessageHandler
+	invites       *handlers.InviteHandler
+	passwords     *handlers.PasswordHandler
+	lockouts      *handlers.LockoutHandler
+	totp          *handlers.TOTPHandler
+	apiKeys       *handlers.APIKeyHandler
+	sessions      *handlers.SessionHandler
+	profile       *handlers.ProfileHandler
+	oidc          *handlers.OIDCHandler
+	impersonation *handlers.ImpersonationHandler
+}
+
+// v1Routes daftar endpoint API versi 1
+func v1Routes(h apiHandlers, uploadTimeout, authLimit fiber.Handler) routeSet {
+	return routeSet{
+		// Auth
+		{method: fiber.MethodPost, path: "/register", public: true, handlers: []fiber.Handler{h.users.RegisterUser}},
+		{method: fiber.MethodPost, path: "/login", public: true, handlers: []fiber.Handler{authLimit, h.auth.Login}},
+		{method: fiber.MethodPost, path: "/auth/refresh", public: true, handlers: []fiber.Handler{h.auth.Refresh}},
+		{method: fiber.MethodPost, path: "/auth/2fa/verify", public: true, handlers: []fiber.Handler{authLimit, h.auth.VerifyMFA}},
+		{method: fiber.MethodPost, path: "/auth/forgot", public: true, handlers: []fiber.Handler{authLimit, h.passwords.ForgotPassword}},
+		{method: fiber.MethodPost, path: "/auth/reset", public: true, handlers: []fiber.Handler{authLimit, h.passwords.ResetPassword}},
+		{method: fiber.MethodPost, path: "/auth/oidc/authorize", public: true, handlers: []fiber.Handler{authLimit, h.oidc.Authorize}},
+		{method: fiber.MethodPost, path: "/auth/oidc/callback", public: true, handlers: []fiber.Handler{authLimit, h.oidc.Callback}},
+		{method: fiber.MethodPost, path: "/logout", enrollment: true, handlers: []fiber.Handler{h.auth.Logout}},
+
+		// Profile
+		{method: fiber.MethodGet, path: "/me", handlers: []fiber.Handler{h.profile.GetMe}},
+		{method: fiber.MethodPatch, path: "/me", handlers: []fiber.Handler{h.profile.UpdateMe}},
+		{method: fiber.MethodPost, path: "/me/password", handlers: []fiber.Handler{middleware.RejectImpersonation, authLimit, h.profile.ChangePassword}},
+
+		// Two-factor, dapat diakses sebelum 2FA terdaftar agar role yang wajib 2FA bisa mendaftar
+		{method: fiber.MethodGet, path: "/me/2fa", enrollment: true, handlers: []fiber.Handler{h.totp.GetTOTPStatus}},
+		{method: fiber.MethodPost, path: "/me/2fa/setup", enrollment: true, handlers: []fiber.Handler{middleware.RejectImpersonation, h.totp.SetupTOTP}},
+		{method: fiber.MethodPost, path: "/me/2fa/confirm", enrollment: true, handlers: []fiber.Handler{middleware.RejectImpersonation, h.totp.ConfirmTOTP}},
+		{method: fiber.MethodPost, path: "/me/2fa/recovery-codes", handlers: []fiber.Handler{middleware.RejectImpersonation, authLimit, h.totp.RegenerateRecoveryCodes}},
+		{method: fiber.MethodDelete, path: "/me/2fa", handlers: []fiber.Handler{middleware.RejectImpersonation, authLimit, h.totp.DisableTOTP}},
+
+		// Sessions
+		{method: fiber.MethodGet, path: "/me/sessions", handlers: []fiber.Handler{h.sessions.GetMySessions}},
+		{method: fiber.MethodDelete, path: "/me/sessions", handlers: []fiber.Handler{middleware.RejectImpersonation, h.sessions.RevokeOtherSessions}},
+		{method: fiber.MethodDelete, path: "/me/sessions/:id", handlers: []fiber.Handler{middleware.RejectImpersonation, h.sessions.RevokeMySession}},
+
+		// Invites
+		{method: fiber.MethodPost, path: "/invites", permission: authz.InvitesCreate, handlers: []fiber.Handler{h.invites.CreateInvite}},
+		{method: fiber.MethodGet, path: "/invites", permission: authz.InvitesRead, handlers: []fiber.Handler{h.invites.GetInvites}},
+		{method: fiber.MethodDelete, path: "/invites/:id", permission: authz.InvitesDelete, handlers: []fiber.Handler{h.invites.RevokeInvite}},
+
+		// API keys
+		{method: fiber.MethodPost, path: "/api-keys", permission: authz.APIKeysCreate, handlers: []fiber.Handler{middleware.RejectImpersonation, h.apiKeys.CreateAPIKey}},
+		{method: fiber.MethodGet, path: "/api-keys", permission: authz.APIKeysRead, handlers: []fiber.Handler{h.apiKeys.GetAPIKeys}},
+		{method: fiber.MethodDelete, path: "/api-keys/:id", permission: authz.APIKeysDelete, handlers: []fiber.Handler{middleware.RejectImpersonation, h.apiKeys.RevokeAPIKey}},
+
+		// Users
+		{method: fiber.MethodGet, path: "/users", permission: authz.UsersRead, handlers: []fiber.Handler{h.users.GetUsers}},
+		{method: fiber.MethodGet, path: "/users/:id", handlers: []fiber.Handler{h.users.GetUserByID}},
+		{method: fiber.MethodPost, path: "/users", permission: authz.UsersCreate, handlers: []fiber.Handler{h.users.CreateUser}},
+		{method: fiber.MethodPut, path: "/users/:id", handlers: []fiber.Handler{h.users.UpdateUser}},
+		{method: fiber.MethodDelete, path: "/users/:id", permission: authz.UsersDelete, handlers: []fiber.Handler{h.users.DeleteUser}},
+		{method: fiber.MethodPost, path: "/users/:id/unlock", permission: authz.UsersUpdate, handlers: []fiber.Handler{h.lockouts.UnlockUser}},
+		{method: fiber.MethodDelete, path: "/users/:id/2fa", permission: authz.UsersUpdate, handlers: []fiber.Handler{h.totp.ResetUserTOTP}},
+		{method: fiber.MethodGet, path: "/users/:id/sessions", permission: authz.UsersRead, handlers: []fiber.Handler{h.sessions.GetUserSessions}},
+		{method: fiber.MethodPost, path: "/users/:id/logout", permission: authz.UsersUpdate, handlers: []fiber.Handler{h.sessions.ForceLogout}},
+		{method: fiber.MethodPost, path: "/users/:id/impersonate", permission: authz.UsersImpersonate, handlers: []fiber.Handler{middleware.RejectAPIKey, middleware.RejectImpersonation, h.impersonation.Impersonate}},
+		{method: fiber.MethodGet, path: "/lockout-events", permission: authz.UsersRead, handlers: []fiber.Handler{h.lockouts.GetLockoutEvents}},
+		{method: fiber.MethodGet, path: "/audit-log", permission: authz.AuditRead, handlers: []fiber.Handler{h.impersonation.GetAuditLog}},
+
+		// Carousels
+		{method: fiber.MethodPost, path: "/carousel", permission: authz.CarouselCreate, handlers: []fiber.Handler{uploadTimeout, h.carousel.CreateCarousel}},
+		{method: fiber.MethodPut, path: "/carousel/:id", permission: authz.CarouselUpdate, handlers: []fiber.Handler{uploadTimeout, h.carousel.UpdateCarousel}},
+		{method: fiber.MethodDelete, path: "/carousel/:id", permission: authz.CarouselDelete, handlers: []fiber.Handler{h.carousel.DeleteCarousel}},
+		{method: fiber.MethodGet, path: "/carousel", permission: authz.CarouselRead, handlers: []fiber.Handler{h.carousel.GetCarousels}},
+		{method: fiber.MethodGet, path: "/carousel/:id", permission: authz.CarouselRead, handlers: []fiber.Handler{h.carousel.GetCarouselByID}},
+
+		// Products
+		{method: fiber.MethodPost, path: "/products", permission: authz.ProductsCreate, handlers: []fiber.Handler{uploadTimeout, h.products.CreateProduct}},
+		{method: fiber.MethodPut, path: "/products/:id", permission: authz.ProductsUpdate, handlers: []fiber.Handler{uploadTimeout, h.products.UpdateProduct}},
+		{method: fiber.MethodDelete, path: "/products/:id", permission: authz.ProductsDelete, handlers: []fiber.Handler{h.products.DeleteProduct}},
+		{method: fiber.MethodGet, path: "/products", permission: authz.ProductsRead, handlers: []fiber.Handler{h.products.GetProducts}},
+		{method: fiber.MethodGet, path: "/products/:id", permission: authz.ProductsRead, handlers: []fiber.Handler{h.products.GetProductByID}},
+
+		// Portfolio Images
+		{method: fiber.MethodPost, path: "/portfolio/images", permission: authz.PortfolioCreate, handlers: []fiber.Handler{uploadTimeout, h.portfolio.CreatePortfolioImage}},
+		{method: fiber.MethodPut, path: "/portfolio/images/:id", permission: authz.PortfolioUpdate, handlers: []fiber.Handler{uploadTimeout, h.portfolio.UpdatePortfolioImage}},
+		{method: fiber.MethodDelete, path: "/portfolio/images/:id", permission: authz.PortfolioDelete, handlers: []fiber.Handler{h.portfolio.DeletePortfolioImage}},
+		{method: fiber.MethodGet, path: "/portfolio/images", permission: authz.PortfolioRead, handlers: []fiber.Handler{h.portfolio.GetPortfolioImages}},
+		{method: fiber.MethodGet, path: "/portfolio/images/:id", permission: authz.PortfolioRead, handlers: []fiber.Handler{h.portfolio.GetPortfolioImageByID}},
+
+		// Portfolio Reviews
+		{method: fiber.MethodPost, path: "/portfolio/reviews", permission: authz.PortfolioCreate, handlers: []fiber.Handler{uploadTimeout, h.portfolio.CreatePortfolioReview}},
+		{method: fiber.MethodPut, path: "/portfolio/reviews/:id", permission: authz.PortfolioUpdate, handlers: []fiber.Handler{uploadTimeout, h.portfolio.UpdatePortfolioReview}},
+		{method: fiber.MethodDelete, path: "/portfolio/reviews/:id", permission: authz.PortfolioDelete, handlers: []fiber.Handler{h.portfolio.DeletePortfolioReview}},
+		{method: fiber.MethodGet, path: "/portfolio/reviews", permission: authz.PortfolioRead, handlers: []fiber.Handler{h.portfolio.GetPortfolioReviews}},
+		{method: fiber.MethodGet, path: "/portfolio/reviews/:id", permission: authz.PortfolioRead, handlers: []fiber.Handler{h.portfolio.GetPortfolioReviewByID}},
+
+		// Messages
+		{method: fiber.MethodPost, path: "/messages", permission: authz.MessagesCreate, handlers: []fiber.Handler{h.messages.CreateMessage}},
+		{method: fiber.MethodPut, path: "/messages/:id", permission: authz.MessagesUpdate, handlers: []fiber.Handler{h.messages.UpdateMessage}},
+		{method: fiber.MethodDelete, path: "/messages/:id", permission: authz.MessagesDelete, handlers: []fiber.Handler{h.messages.DeleteMessage}},
+		{method: fiber.MethodGet, path: "/messages", permission: authz.MessagesRead, handlers: []fiber.Handler{h.messages.GetMessages}},
+		{method: fiber.MethodGet, path: "/messages/:id", permission: authz.MessagesRead, handlers: []fiber.Handler{h.messages.GetMessageByID}},
+	}
+}
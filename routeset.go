@@ -0,0 +1,86 @@
+package main
+
+import (
+	"backend-go/internal/authz"
+	"backend-go/internal/middleware"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// route satu endpoint API. handlers berisi middleware khusus route (mis. timeout upload)
+// diikuti handler utama; route non-public otomatis diberi middleware auth saat dipasang,
+// lalu kewajiban 2FA per role (kecuali route enrollment) dan pemeriksaan permission bila
+// permission di-set. Route non-public tanpa permission tidak dapat diakses dengan API key.
+type route struct {
+	method     string
+	path       string
+	public     bool
+	enrollment bool
+	permission authz.Permission
+	handlers   []fiber.Handler
+	// timeout mengganti deadline default untuk seluruh chain route, termasuk auth
+	timeout fiber.Handler
+}
+
+// group segmen pertama path, mis. "products" untuk /products/:id; kunci ROUTE_TIMEOUTS
+func (r route) group() string {
+	group, _, _ := strings.Cut(strings.TrimPrefix(r.path, "/"), "/")
+	return group
+}
+
+// routeSet daftar endpoint satu versi API yang dapat dipasang di beberapa prefix
+type routeSet []route
+
+// withTimeouts memasang deadline per grup route. Grup yang tidak ada di routeSet dikembalikan
+// agar salah ketik di konfigurasi dapat dilaporkan.
+func (s routeSet) withTimeouts(timeouts map[string]time.Duration) (routeSet, []string) {
+	handlers := make(map[string]fiber.Handler, len(timeouts))
+	for group, d := range timeouts {
+		handlers[group] = middleware.Timeout(d)
+	}
+
+	used := make(map[string]bool)
+	routes := make(routeSet, len(s))
+	for i, r := range s {
+		if timeout, ok := handlers[r.group()]; ok {
+			r.timeout = timeout
+			used[r.group()] = true
+		}
+		routes[i] = r
+	}
+
+	var unknown []string
+	for group := range timeouts {
+		if !used[group] {
+			unknown = append(unknown, group)
+		}
+	}
+	return routes, unknown
+}
+
+// mount mendaftarkan seluruh route ke router. Middleware pada before dijalankan paling awal
+// untuk setiap route, lalu auth dan mfa untuk route non-public dan permission sesuai policy.
+func (s routeSet) mount(router fiber.Router, auth, mfa fiber.Handler, policy *authz.Policy, before ...fiber.Handler) {
+	for _, r := range s {
+		chain := append([]fiber.Handler{}, before...)
+		if r.timeout != nil {
+			chain = append(chain, r.timeout)
+		}
+		if !r.public {
+			chain = append(chain, auth)
+			if !r.enrollment {
+				chain = append(chain, mfa)
+			}
+			if r.permission == "" {
+				chain = append(chain, middleware.RejectAPIKey)
+			}
+		}
+		if r.permission != "" {
+			chain = append(chain, middleware.Require(policy, r.permission))
+		}
+		chain = append(chain, r.handlers...)
+		router.Add(r.method, r.path, chain...)
+	}
+}
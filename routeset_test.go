@@ -0,0 +1,102 @@
+package main
+
+import (
+	"backend-go/internal/apperror"
+	"backend-go/internal/authz"
+	"backend-go/internal/middleware"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRouteTimeouts(t *testing.T) {
+	// deadline mengembalikan sisa waktu context request, dibulatkan ke detik
+	deadline := func(c *fiber.Ctx) error {
+		d, ok := c.UserContext().Deadline()
+		if !ok {
+			return c.SendString("none")
+		}
+		return c.SendString(time.Until(d).Round(time.Second).String())
+	}
+	routes, unknown := routeSet{
+		{method: fiber.MethodGet, path: "/products", public: true, handlers: []fiber.Handler{deadline}},
+		{method: fiber.MethodGet, path: "/products/:id", public: true, handlers: []fiber.Handler{deadline}},
+		{method: fiber.MethodPost, path: "/products", public: true, handlers: []fiber.Handler{middleware.Timeout(time.Minute), deadline}},
+		{method: fiber.MethodGet, path: "/messages", public: true, handlers: []fiber.Handler{deadline}},
+	}.withTimeouts(map[string]time.Duration{"products": 30 * time.Second, "prodcuts": time.Second})
+
+	if !reflect.DeepEqual(unknown, []string{"prodcuts"}) {
+		t.Errorf("unknown groups = %v, want [prodcuts]", unknown)
+	}
+
+	app := fiber.New()
+	app.Use(middleware.Timeout(10 * time.Second))
+	routes.mount(app, nil, nil, authz.Default())
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: fiber.MethodGet, path: "/products", want: "30s"},
+		{method: fiber.MethodGet, path: "/products/1", want: "30s"},
+		{method: fiber.MethodPost, path: "/products", want: "1m0s"},
+		{method: fiber.MethodGet, path: "/messages", want: "10s"},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != tt.want {
+			t.Errorf("%s %s deadline = %s, want %s", tt.method, tt.path, body, tt.want)
+		}
+	}
+}
+
+func TestContextErrorStatus(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(middleware.Timeout(10 * time.Millisecond))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return fmt.Errorf("query: %w", c.UserContext().Err())
+	})
+	app.Get("/canceled", func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancel(c.UserContext())
+		cancel()
+		return fmt.Errorf("query: %w", ctx.Err())
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		code   apperror.Code
+	}{
+		{path: "/slow", status: fiber.StatusServiceUnavailable, code: apperror.CodeTimeout},
+		{path: "/canceled", status: apperror.StatusClientClosedRequest, code: apperror.CodeCanceled},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body apperror.Response
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status || body.Code != tt.code {
+			t.Errorf("GET %s = %d %s, want %d %s", tt.path, resp.StatusCode, body.Code, tt.status, tt.code)
+		}
+	}
+}
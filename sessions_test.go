@@ -0,0 +1,130 @@
+package main
+
+import (
+	"backend-go/internal/models"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSessionsRejectImpersonation(t *testing.T) {
+	a := newTestApp(t, withImpersonation)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	staffToken := a.login(t, "staff", "Secret123")
+	token := a.impersonate(t, a.login(t, "admin", "Secret123"), staff.ID)
+
+	r := a.json(t, http.MethodGet, "/api/v1/me/sessions", nil, token)
+	if r.status != http.StatusOK || len(r.list) == 0 {
+		t.Fatalf("list = %d %v", r.status, r.list)
+	}
+	first, _ := r.list[0].(map[string]interface{})
+
+	for _, path := range []string{"/api/v1/me/sessions", fmt.Sprintf("/api/v1/me/sessions/%v", first["id"])} {
+		if r := a.json(t, http.MethodDelete, path, nil, token); r.status != http.StatusForbidden {
+			t.Errorf("DELETE %s while impersonating = %d %v, want 403", path, r.status, r.body)
+		}
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, staffToken); r.status != http.StatusOK {
+		t.Errorf("user's own session = %d %v, want 200", r.status, r.body)
+	}
+}
+
+// sessionID session yang terikat pada access token
+func sessionID(t *testing.T, token string) int {
+	t.Helper()
+	return tokenClaims(t, token).SessionID
+}
+
+func TestSessionListing(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	alice := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	a.createUser(t, "bob", "Secret123", models.RoleUser)
+	tokens := []string{a.login(t, "alice", "Secret123"), a.login(t, "alice", "Secret123"), a.login(t, "alice", "Secret123")}
+	a.login(t, "bob", "Secret123")
+
+	r := a.json(t, http.MethodGet, "/api/v1/me/sessions", nil, tokens[1])
+	if r.status != http.StatusOK || len(r.list) != 3 {
+		t.Fatalf("list = %d %v, want alice's three sessions", r.status, r.list)
+	}
+	var current []float64
+	for _, item := range r.list {
+		s := item.(map[string]interface{})
+		if s["current"] == true {
+			current = append(current, s["id"].(float64))
+		}
+		if amr, _ := s["amr"].([]interface{}); len(amr) != 1 || amr[0] != models.AMRPassword {
+			t.Errorf("session = %v, want amr [%s]", s, models.AMRPassword)
+		}
+	}
+	if len(current) != 1 || current[0] != float64(sessionID(t, tokens[1])) {
+		t.Errorf("current sessions = %v, want only %d", current, sessionID(t, tokens[1]))
+	}
+
+	path := fmt.Sprintf("/api/v1/users/%d/sessions", alice.ID)
+	r = a.json(t, http.MethodGet, path, nil, a.login(t, "admin", "Secret123"))
+	if r.status != http.StatusOK || len(r.list) != 3 {
+		t.Fatalf("admin list = %d %v, want three sessions", r.status, r.list)
+	}
+	for _, item := range r.list {
+		if s := item.(map[string]interface{}); s["current"] == true {
+			t.Errorf("admin list marks %v current", s)
+		}
+	}
+	if r := a.json(t, http.MethodGet, path, nil, tokens[0]); r.status != http.StatusForbidden {
+		t.Errorf("user listing another user's sessions = %d %v, want 403", r.status, r.body)
+	}
+}
+
+func TestSessionRevocation(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	alice := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	a.createUser(t, "bob", "Secret123", models.RoleUser)
+	first, _ := a.loginTokens(t, "alice", "Secret123")
+	current, refresh := a.loginTokens(t, "alice", "Secret123")
+	third := a.login(t, "alice", "Secret123")
+	bob := a.login(t, "bob", "Secret123")
+
+	me := func(token string) int {
+		return a.json(t, http.MethodGet, "/api/v1/me", nil, token).status
+	}
+
+	path := fmt.Sprintf("/api/v1/me/sessions/%d", sessionID(t, first))
+	if r := a.json(t, http.MethodDelete, path, nil, current); r.status != http.StatusOK {
+		t.Fatalf("revoke = %d %v", r.status, r.body)
+	}
+	if me(first) != http.StatusUnauthorized || me(current) != http.StatusOK {
+		t.Errorf("after revoking one session: first = %d, current = %d, want 401 and 200", me(first), me(current))
+	}
+	if r := a.json(t, http.MethodDelete, path, nil, current); r.status != http.StatusNotFound {
+		t.Errorf("revoke again = %d %v, want 404", r.status, r.body)
+	}
+	// Session user lain tidak dapat dicabut dan tidak dibedakan dari yang tidak ada
+	if r := a.json(t, http.MethodDelete, fmt.Sprintf("/api/v1/me/sessions/%d", sessionID(t, bob)), nil, current); r.status != http.StatusNotFound {
+		t.Errorf("revoke bob's session = %d %v, want 404", r.status, r.body)
+	}
+	if me(bob) != http.StatusOK {
+		t.Errorf("bob after alice's attempt = %d, want 200", me(bob))
+	}
+
+	r := a.json(t, http.MethodDelete, "/api/v1/me/sessions", nil, current)
+	if r.status != http.StatusOK || r.body["revoked"] != float64(1) {
+		t.Fatalf("revoke others = %d %v, want one revoked", r.status, r.body)
+	}
+	if me(third) != http.StatusUnauthorized || me(current) != http.StatusOK {
+		t.Errorf("after revoking others: third = %d, current = %d, want 401 and 200", me(third), me(current))
+	}
+
+	r = a.json(t, http.MethodPost, fmt.Sprintf("/api/v1/users/%d/logout", alice.ID), nil, a.login(t, "admin", "Secret123"))
+	if r.status != http.StatusOK || r.body["revoked"] != float64(1) {
+		t.Fatalf("force logout = %d %v, want one revoked", r.status, r.body)
+	}
+	if me(current) != http.StatusUnauthorized {
+		t.Errorf("current after force logout = %d, want 401", me(current))
+	}
+	if r := a.refresh(t, refresh); r.status != http.StatusUnauthorized {
+		t.Errorf("refresh after force logout = %d %v, want 401", r.status, r.body)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"backend-go/internal/background"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"backend-go/internal/repository"
+	"backend-go/internal/totp"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// challenge login dengan password dan mengembalikan token tantangan 2FA
+func (a *testApp) challenge(t *testing.T, username, password string) string {
+	t.Helper()
+	r := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: username, Password: password}, "")
+	if r.status != http.StatusAccepted || r.string("challenge_token") == "" {
+		t.Fatalf("login %s = %d %v, want 202 with a challenge", username, r.status, r.body)
+	}
+	return r.string("challenge_token")
+}
+
+func TestTOTPEnrollAndVerify(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.MFAChallengeTTL = time.Minute })
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token := a.login(t, "alice", "Secret123")
+
+	r := a.json(t, http.MethodPost, "/api/v1/me/2fa/setup", nil, token)
+	secret := r.string("secret")
+	if r.status != http.StatusOK || secret == "" {
+		t.Fatalf("setup = %d %v", r.status, r.body)
+	}
+	if r = a.json(t, http.MethodPost, "/api/v1/me/2fa/confirm", models.TOTPCodeRequest{Code: "000000"}, token); r.status != http.StatusBadRequest {
+		t.Fatalf("confirm with wrong code = %d %v, want 400", r.status, r.body)
+	}
+	// Kode langkah sebelumnya masih diterima, sehingga kode saat ini tetap bisa dipakai untuk login
+	previous, err := totp.Code(secret, totp.Step(time.Now())-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = a.json(t, http.MethodPost, "/api/v1/me/2fa/confirm", models.TOTPCodeRequest{Code: previous}, token)
+	codes, _ := r.body["recovery_codes"].([]interface{})
+	if r.status != http.StatusOK || len(codes) != 10 {
+		t.Fatalf("confirm = %d %v, want 10 recovery codes", r.status, r.body)
+	}
+
+	verify := func(req models.MFAVerifyRequest) response {
+		req.ChallengeToken = a.challenge(t, "alice", "Secret123")
+		return a.json(t, http.MethodPost, "/api/v1/auth/2fa/verify", req, "")
+	}
+
+	t.Run("code used once", func(t *testing.T) {
+		code := totpCode(t, secret)
+		r := verify(models.MFAVerifyRequest{Code: code})
+		if r.status != http.StatusOK {
+			t.Fatalf("verify = %d %v", r.status, r.body)
+		}
+		if got := a.json(t, http.MethodGet, "/api/v1/me/2fa", nil, r.string("token")); got.body["enabled"] != true {
+			t.Errorf("status = %v, want enabled", got.body)
+		}
+		if r := verify(models.MFAVerifyRequest{Code: code}); r.status != http.StatusUnauthorized {
+			t.Errorf("replayed code = %d %v, want 401", r.status, r.body)
+		}
+		if r := verify(models.MFAVerifyRequest{Code: previous}); r.status != http.StatusUnauthorized {
+			t.Errorf("older step = %d %v, want 401", r.status, r.body)
+		}
+	})
+
+	t.Run("recovery code used once", func(t *testing.T) {
+		code := strings.ToUpper(codes[0].(string))
+		if r := verify(models.MFAVerifyRequest{RecoveryCode: code}); r.status != http.StatusOK {
+			t.Fatalf("verify = %d %v", r.status, r.body)
+		}
+		if r := verify(models.MFAVerifyRequest{RecoveryCode: code}); r.status != http.StatusUnauthorized {
+			t.Errorf("reused recovery code = %d %v, want 401", r.status, r.body)
+		}
+		if r := verify(models.MFAVerifyRequest{RecoveryCode: codes[1].(string)}); r.status != http.StatusOK {
+			t.Errorf("second recovery code = %d %v, want 200", r.status, r.body)
+		}
+		if got := a.json(t, http.MethodGet, "/api/v1/me/2fa", nil, token); got.body["recovery_codes_remaining"] != float64(8) {
+			t.Errorf("status = %v, want 8 recovery codes remaining", got.body)
+		}
+	})
+
+	t.Run("challenge used once", func(t *testing.T) {
+		challenge := a.challenge(t, "alice", "Secret123")
+		req := models.MFAVerifyRequest{ChallengeToken: challenge, RecoveryCode: codes[2].(string)}
+		if r := a.json(t, http.MethodPost, "/api/v1/auth/2fa/verify", req, ""); r.status != http.StatusOK {
+			t.Fatalf("verify = %d %v", r.status, r.body)
+		}
+		req.RecoveryCode = codes[3].(string)
+		if r := a.json(t, http.MethodPost, "/api/v1/auth/2fa/verify", req, ""); r.status != http.StatusUnauthorized {
+			t.Errorf("reused challenge = %d %v, want 401", r.status, r.body)
+		}
+	})
+}
+
+func TestTOTPCodeLockout(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "disable", method: http.MethodDelete, path: "/api/v1/me/2fa"},
+		{name: "recovery codes", method: http.MethodPost, path: "/api/v1/me/2fa/recovery-codes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, nil)
+			user := a.createUser(t, "alice", "Secret123", models.RoleUser)
+			token := a.login(t, "alice", "Secret123")
+			secret := a.enrollTOTP(t, user.ID)
+
+			// Kode yang hampir pasti salah: kode saat ini dengan digit terakhir diubah
+			code := totpCode(t, secret)
+			wrong := code[:5] + string('0'+(code[5]-'0'+1)%10)
+			for i := range a.cfg.Auth.LoginMaxFailures {
+				if r := a.json(t, tt.method, tt.path, models.TOTPCodeRequest{Code: wrong}, token); r.status != http.StatusBadRequest {
+					t.Fatalf("wrong code %d = %d %v, want 400", i+1, r.status, r.body)
+				}
+			}
+
+			r := a.json(t, tt.method, tt.path, models.TOTPCodeRequest{Code: code}, token)
+			if r.status != http.StatusTooManyRequests {
+				t.Fatalf("correct code after lockout = %d %v, want 429", r.status, r.body)
+			}
+			if r := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: "alice", Password: "Secret123"}, ""); r.status != http.StatusTooManyRequests {
+				t.Errorf("login after lockout = %d %v, want 429", r.status, r.body)
+			}
+		})
+	}
+}
+
+// barrierTOTP menahan setiap pemakaian kode sampai semua request paralel sampai di titik yang sama,
+// sehingga semuanya sudah lolos pemeriksaan awal challenge sebelum ada yang mencabutnya
+type barrierTOTP struct {
+	repository.TOTPRepository
+	arrived sync.WaitGroup
+}
+
+func (r *barrierTOTP) wait() {
+	r.arrived.Done()
+	done := make(chan struct{})
+	go func() {
+		r.arrived.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func (r *barrierTOTP) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	r.wait()
+	return r.TOTPRepository.UseStep(ctx, userID, step)
+}
+
+func (r *barrierTOTP) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	r.wait()
+	return r.TOTPRepository.UseRecoveryCode(ctx, userID, codeHash)
+}
+
+func TestChallengeParallelUse(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.MFAChallengeTTL = time.Minute })
+	user := a.createUser(t, "alice", "Secret123", models.RoleUser)
+	secret, err := totp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovery := make([]string, 8)
+	hashes := make([]string, len(recovery))
+	for i := range recovery {
+		recovery[i] = fmt.Sprintf("recovery%02d", i)
+		sum := sha256.Sum256([]byte(recovery[i]))
+		hashes[i] = hex.EncodeToString(sum[:])
+	}
+	if err := a.repos.TOTP.Begin(context.Background(), user.ID, secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.repos.TOTP.Confirm(context.Background(), user.ID, totp.Step(time.Now())-10, hashes); err != nil {
+		t.Fatal(err)
+	}
+
+	// Kode TOTP dan kode-kode pemulihan yang berbeda dikirim bersamaan dengan satu challenge
+	challenge := a.challenge(t, "alice", "Secret123")
+	requests := []models.MFAVerifyRequest{{ChallengeToken: challenge, Code: totpCode(t, secret)}}
+	for _, code := range recovery {
+		requests = append(requests, models.MFAVerifyRequest{ChallengeToken: challenge, RecoveryCode: code})
+	}
+
+	repos := *a.repos
+	barrier := &barrierTOTP{TOTPRepository: repos.TOTP}
+	barrier.arrived.Add(len(requests))
+	repos.TOTP = barrier
+	b := &testApp{repos: &repos, cfg: a.cfg, tasks: background.New()}
+	b.app, _ = newApp(b.cfg, nil, b.repos, b.tasks)
+
+	// Goroutine biasa, bukan subtest paralel, agar jumlah request bersamaan tidak dibatasi -parallel
+	statuses := make([]int, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/auth/2fa/verify", bytes.NewReader(body))
+			httpReq.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := b.app.Test(httpReq, -1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for i, status := range statuses {
+		switch status {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusUnauthorized:
+		default:
+			t.Errorf("request %d = %d, want 200 or 401", i, status)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("statuses = %v, want exactly one 200", statuses)
+	}
+	sessions, err := a.repos.Sessions.ListActive(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Login yang mendapat challenge belum membuat session; hanya verifikasi yang lolos yang membuatnya
+	if len(sessions) != 1 {
+		t.Errorf("sessions = %d, want one", len(sessions))
+	}
+}
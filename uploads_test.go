@@ -0,0 +1,75 @@
+package main
+
+import (
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestUploadTitleTraversal(t *testing.T) {
+	// Root upload di dalam direktori sementara, agar file yang lolos keluar root terlihat di induknya
+	parent := t.TempDir()
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Upload.Root = filepath.Join(parent, "uploads") })
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	token := a.login(t, "admin", "Secret123")
+
+	title := "/../../../escape me"
+	product := a.multipart(t, http.MethodPost, "/api/v1/products", map[string]string{
+		"title": title, "type_product": "digital", "price": "1000", "status": "true",
+	}, "cover.png", token)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		fields map[string]string
+		status int
+		dir    string
+	}{
+		{name: "create carousel", method: http.MethodPost, path: "/api/v1/carousel", fields: map[string]string{"title": title}, status: http.StatusCreated, dir: "carousel"},
+		{name: "create portfolio review", method: http.MethodPost, path: "/api/v1/portfolio/reviews", fields: map[string]string{
+			"title": title, "description": "Redesign", "date": "2024-05-01",
+		}, status: http.StatusCreated, dir: "portfolio/reviews"},
+		{name: "update product", method: http.MethodPut, path: fmt.Sprintf("/api/v1/products/%d", product.id()), fields: map[string]string{"title": title}, status: http.StatusOK, dir: "products"},
+	}
+	responses := []response{product}
+	if product.status != http.StatusCreated {
+		t.Fatalf("create product = %d %v", product.status, product.body)
+	}
+	for _, tt := range tests {
+		r := a.multipart(t, tt.method, tt.path, tt.fields, "cover.png", token)
+		if r.status != tt.status {
+			t.Fatalf("%s = %d %v, want %d", tt.name, r.status, r.body, tt.status)
+		}
+		responses = append(responses, r)
+	}
+
+	// Judul dijadikan slug: hanya huruf, angka dan "-" yang tersisa di nama file
+	image := regexp.MustCompile(`^uploads/(products|carousel|portfolio/reviews)/\d+-escape-me\.png$`)
+	for _, r := range responses {
+		path := r.string("image")
+		if !image.MatchString(path) {
+			t.Errorf("image = %q, want a slugged name under its upload directory", path)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(a.cfg.Upload.Root, filepath.FromSlash(strings.TrimPrefix(path, "uploads/")))); err != nil {
+			t.Errorf("stat %s: %v", path, err)
+		}
+	}
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "uploads" {
+			t.Errorf("%s written outside the upload root", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"backend-go/internal/authz"
+	"backend-go/internal/config"
+	"backend-go/internal/models"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// withStaffGrants memberi staff permission tambahan di atas matriks bawaan
+func withStaffGrants(t *testing.T, names ...string) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		grants := authz.Grants{}
+		for role, names := range authz.DefaultGrants {
+			grants[role] = names
+		}
+		grants[models.RoleStaff] = append(names, authz.DefaultGrants[models.RoleStaff]...)
+		policy, err := authz.New(grants)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Authz.Policy = policy
+	}
+}
+
+func TestUserRoleEscalation(t *testing.T) {
+	// Staff diberi hak mengelola user; role lebih berkuasa tetap tidak boleh diberikan
+	a := newTestApp(t, withStaffGrants(t, "users:read", "users:create", "users:update"))
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	user := a.createUser(t, "user", "Secret123", models.RoleUser)
+	token := a.login(t, "staff", "Secret123")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		want   int
+	}{
+		{name: "create admin", method: http.MethodPost, path: "/api/v1/users", want: http.StatusForbidden,
+			body: models.CreateRequest{Name: "Eve", Phone: "+6281200000001", Username: "eve", Password: "Secret123", Role: models.RoleAdmin}},
+		{name: "create staff", method: http.MethodPost, path: "/api/v1/users", want: http.StatusCreated,
+			body: models.CreateRequest{Name: "Dave", Phone: "+6281200000002", Username: "dave", Password: "Secret123", Role: models.RoleStaff}},
+		{name: "promote self", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", staff.ID), want: http.StatusForbidden,
+			body: models.UpdateRequest{Role: models.RoleAdmin}},
+		{name: "promote other to admin", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", user.ID), want: http.StatusForbidden,
+			body: models.UpdateRequest{Role: models.RoleAdmin}},
+		{name: "promote other to staff", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", user.ID), want: http.StatusOK,
+			body: models.UpdateRequest{Role: models.RoleStaff}},
+		{name: "set admin password", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", admin.ID), want: http.StatusForbidden,
+			body: models.UpdateRequest{Password: "Takeover123"}},
+		{name: "demote admin", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", admin.ID), want: http.StatusForbidden,
+			body: models.UpdateRequest{Role: models.RoleUser}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := a.json(t, tt.method, tt.path, tt.body, token); r.status != tt.want {
+				t.Fatalf("status = %d %v, want %d", r.status, r.body, tt.want)
+			}
+		})
+	}
+
+	t.Run("admin promotes own role", func(t *testing.T) {
+		r := a.json(t, http.MethodPut, fmt.Sprintf("/api/v1/users/%d", admin.ID), models.UpdateRequest{Role: models.RoleAdmin}, a.login(t, "admin", "Secret123"))
+		if r.status != http.StatusForbidden {
+			t.Fatalf("status = %d %v, want 403", r.status, r.body)
+		}
+	})
+}
+
+func TestManageMorePrivilegedUser(t *testing.T) {
+	// Staff yang diberi hak mengelola user hanya dapat memakainya pada role yang tidak lebih berkuasa
+	a := newTestApp(t, withStaffGrants(t, "users:read", "users:update", "users:delete"))
+	a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	user := a.createUser(t, "user", "Secret123", models.RoleUser)
+	token := a.login(t, "staff", "Secret123")
+	adminToken := a.login(t, "admin", "Secret123")
+
+	actions := []struct {
+		method, path string
+	}{
+		{http.MethodGet, "/api/v1/users/%d/sessions"},
+		{http.MethodPost, "/api/v1/users/%d/unlock"},
+		{http.MethodPost, "/api/v1/users/%d/logout"},
+		{http.MethodDelete, "/api/v1/users/%d"},
+	}
+	for _, action := range actions {
+		if r := a.json(t, action.method, fmt.Sprintf(action.path, admin.ID), nil, token); r.status != http.StatusForbidden {
+			t.Errorf("%s %s on admin = %d %v, want 403", action.method, action.path, r.status, r.body)
+		}
+		if r := a.json(t, action.method, fmt.Sprintf(action.path, user.ID), nil, token); r.status != http.StatusOK {
+			t.Errorf("%s %s on user = %d %v, want 200", action.method, action.path, r.status, r.body)
+		}
+	}
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, adminToken); r.status != http.StatusOK {
+		t.Errorf("admin session after refused logout = %d %v, want 200", r.status, r.body)
+	}
+
+	// Reset 2FA memakai aturan yang sama, bukan pengecualian khusus admin
+	a.enrollTOTP(t, admin.ID)
+	staff := a.createUser(t, "staff2", "Secret123", models.RoleStaff)
+	a.enrollTOTP(t, staff.ID)
+	if r := a.json(t, http.MethodDelete, fmt.Sprintf("/api/v1/users/%d/2fa", admin.ID), nil, token); r.status != http.StatusForbidden {
+		t.Errorf("reset admin 2FA = %d %v, want 403", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodDelete, fmt.Sprintf("/api/v1/users/%d/2fa", staff.ID), nil, token); r.status != http.StatusNoContent {
+		t.Errorf("reset staff 2FA = %d %v, want 204", r.status, r.body)
+	}
+}
+
+func TestUpdateUserPasswordRevokesSessions(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	adminToken := a.login(t, "admin", "Secret123")
+
+	login := a.json(t, http.MethodPost, "/api/v1/login", models.LoginRequest{Username: "staff", Password: "Secret123"}, "")
+	staffToken, refresh := login.string("token"), login.string("refresh_token")
+
+	r := a.json(t, http.MethodPut, fmt.Sprintf("/api/v1/users/%d", staff.ID), models.UpdateRequest{Password: "Changed123"}, adminToken)
+	if r.status != http.StatusOK {
+		t.Fatalf("update = %d %v", r.status, r.body)
+	}
+
+	if r := a.json(t, http.MethodGet, "/api/v1/me", nil, staffToken); r.status != http.StatusUnauthorized {
+		t.Errorf("old access token = %d %v, want 401", r.status, r.body)
+	}
+	if r := a.json(t, http.MethodPost, "/api/v1/auth/refresh", models.RefreshRequest{RefreshToken: refresh}, ""); r.status != http.StatusUnauthorized {
+		t.Errorf("old refresh token = %d %v, want 401", r.status, r.body)
+	}
+	a.login(t, "staff", "Changed123")
+}
+
+func TestRegisterAndProfileRulesAgree(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) { cfg.Auth.PublicRegistration = true })
+	a.createUser(t, "alice", "Secret123", models.RoleUser)
+	token := a.login(t, "alice", "Secret123")
+
+	// Nilai yang ditolak saat register juga ditolak saat mengubah profil, dan sebaliknya
+	tests := []struct {
+		name     string
+		field    string
+		username string
+		phone    string
+	}{
+		{name: "username with underscore", field: "username", username: "john_doe", phone: "+6281200000001"},
+		{name: "local phone number", field: "phone", username: "johndoe", phone: "081200000001"},
+		{name: "blank name", field: "name", username: "johndoe", phone: "+6281200000001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := "John Doe"
+			if tt.field == "name" {
+				name = "     "
+			}
+			register := models.RegisterRequest{Name: name, Phone: tt.phone, Username: tt.username, Password: "Secret123"}
+			profile := models.UpdateProfileRequest{Name: &name, Phone: &tt.phone, Username: &tt.username}
+			for path, r := range map[string]response{
+				"/register": a.json(t, http.MethodPost, "/api/v1/register", register, ""),
+				"/me":       a.json(t, http.MethodPatch, "/api/v1/me", profile, token),
+			} {
+				details, _ := r.body["details"].([]interface{})
+				if r.status != http.StatusBadRequest || len(details) != 1 || details[0].(map[string]interface{})["field"] != tt.field {
+					t.Errorf("%s = %d %v, want one error on %s", path, r.status, r.body, tt.field)
+				}
+			}
+		})
+	}
+
+	register := models.RegisterRequest{Name: "John Doe", Phone: "+6281200000002", Username: "john2", Password: "Secret123"}
+	if r := a.json(t, http.MethodPost, "/api/v1/register", register, ""); r.status != http.StatusCreated {
+		t.Fatalf("register = %d %v, want 201", r.status, r.body)
+	}
+}
+
+func TestUserResponsesAgree(t *testing.T) {
+	a := newTestApp(t, withImpersonation)
+	admin := a.createUser(t, "admin", "Secret123", models.RoleAdmin)
+	staff := a.createUser(t, "staff", "Secret123", models.RoleStaff)
+	adminToken := a.login(t, "admin", "Secret123")
+	token := a.impersonate(t, adminToken, staff.ID)
+
+	name := "Staff Baru"
+	if r := a.json(t, http.MethodPatch, "/api/v1/me", models.UpdateProfileRequest{Name: &name}, token); r.status != http.StatusOK {
+		t.Fatalf("update profile = %d %v", r.status, r.body)
+	}
+
+	// /me, /users/:id dan /users memetakan user yang sama ke field yang sama
+	me := a.json(t, http.MethodGet, "/api/v1/me", nil, token)
+	byID := a.json(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%d", staff.ID), nil, adminToken)
+	list := a.json(t, http.MethodGet, "/api/v1/users?role=staff", nil, adminToken)
+	data, _ := list.body["data"].([]interface{})
+	if me.status != http.StatusOK || byID.status != http.StatusOK || len(data) != 1 {
+		t.Fatalf("me = %d, by id = %d, list = %d %v", me.status, byID.status, list.status, list.body)
+	}
+	listed, _ := data[0].(map[string]interface{})
+	for _, got := range []map[string]interface{}{me.body, byID.body, listed} {
+		if got["impersonated_by"] != float64(admin.ID) || got["name"] != name {
+			t.Errorf("response = %v, want name %q and impersonated_by %d", got, name, admin.ID)
+		}
+		if _, ok := got["password"]; ok {
+			t.Errorf("response = %v, want no password", got)
+		}
+	}
+	if !reflect.DeepEqual(me.body, byID.body) || !reflect.DeepEqual(me.body, listed) {
+		t.Errorf("responses differ:\n/me       %v\n/users/id %v\n/users    %v", me.body, byID.body, listed)
+	}
+}